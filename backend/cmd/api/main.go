@@ -2,94 +2,535 @@ package main
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/alexcolls/findme/internal/api/handlers"
+	"github.com/alexcolls/findme/internal/api/middleware"
+	"github.com/alexcolls/findme/internal/config"
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/metrics"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	qdrantrepo "github.com/alexcolls/findme/internal/repository/qdrant"
+	redisrepo "github.com/alexcolls/findme/internal/repository/redis"
+	"github.com/alexcolls/findme/internal/service/admin"
+	"github.com/alexcolls/findme/internal/service/auth"
+	"github.com/alexcolls/findme/internal/service/calls"
+	"github.com/alexcolls/findme/internal/service/chat"
+	"github.com/alexcolls/findme/internal/service/contentsafety"
+	"github.com/alexcolls/findme/internal/service/embedding"
+	"github.com/alexcolls/findme/internal/service/events"
+	"github.com/alexcolls/findme/internal/service/ice"
+	"github.com/alexcolls/findme/internal/service/match"
+	"github.com/alexcolls/findme/internal/service/matching"
+	"github.com/alexcolls/findme/internal/service/moderation"
+	"github.com/alexcolls/findme/internal/service/notifications"
+	"github.com/alexcolls/findme/internal/service/push"
+	"github.com/alexcolls/findme/internal/service/realtime"
+	"github.com/alexcolls/findme/internal/service/safety"
+	"github.com/alexcolls/findme/migrations"
+	"github.com/alexcolls/findme/pkg/apperror"
+	"github.com/alexcolls/findme/pkg/cache"
+	"github.com/alexcolls/findme/pkg/database"
+	"github.com/alexcolls/findme/pkg/health"
+	"github.com/alexcolls/findme/pkg/jwt"
+	"github.com/alexcolls/findme/pkg/logger"
+	"github.com/alexcolls/findme/pkg/ratelimit"
+	"github.com/alexcolls/findme/pkg/tracing"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+const (
+	serviceName    = "findme-api"
+	serviceVersion = "0.1.0"
+)
+
+// routerDeps groups the handlers and middleware mounted by setupRouter.
+type routerDeps struct {
+	authHandler         *handlers.AuthHandler
+	adminHandler        *handlers.AdminHandler
+	matchHandler        *handlers.MatchHandler
+	matchingHandler     *handlers.MatchingHandler
+	moderationHandler   *handlers.ModerationHandler
+	safetyHandler       *handlers.SafetyHandler
+	callHandler         *handlers.CallHandler
+	notificationHandler *handlers.NotificationHandler
+	pushHandler         *handlers.PushHandler
+	chatHandler         *handlers.ChatHandler
+	wsHandler           *handlers.WebSocketHandler
+	healthHandler       *handlers.HealthHandler
+	authMiddleware      *middleware.AuthMiddleware
+	authRateLimit       gin.HandlerFunc
+	apiRateLimit        gin.HandlerFunc
+	trustedProxies      []string
+	logger              *slog.Logger
+	logRequests         bool
+	// metrics instruments requests; metricsHandler is mounted at /metrics
+	// when it is served on the API port
+	metrics        bool
+	metricsHandler http.Handler
+}
+
 func main() {
-	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found, using environment variables")
+	// Load configuration (also reads .env if present)
+	cfg, err := config.Load()
+	if err != nil {
+		fatal("Failed to load configuration", err)
 	}
 
-	// Get configuration from environment
-	port := getEnv("API_PORT", "8080")
-	env := getEnv("APP_ENV", "development")
+	// Structured logging; the standard log package writes through it too
+	appLogger := logger.New(os.Stdout, logger.Config{Level: cfg.LogLevel, Format: cfg.LogFormat})
+	slog.SetDefault(appLogger)
+
+	// Tracing; spans still buffered are flushed on shutdown
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:       cfg.TracingExporter,
+		Endpoint:       cfg.TracingEndpoint,
+		Insecure:       cfg.TracingInsecure,
+		SampleRatio:    cfg.TracingSampleRatio,
+		ServiceName:    serviceName,
+		ServiceVersion: serviceVersion,
+		Environment:    cfg.Environment,
+	})
+	if err != nil {
+		fatal("Failed to set up tracing", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("Failed to flush traces", "error", err)
+		}
+	}()
 
 	// Set Gin mode
-	if env == "production" {
+	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	// Connect to PostgreSQL
+	db, err := database.NewPostgresDB(database.PostgresConfig{
+		DSN:             cfg.GetDatabaseDSN(),
+		MaxOpenConns:    25,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: 5 * time.Minute,
+	})
+	if err != nil {
+		fatal("Failed to connect to database", err)
+	}
+	defer database.CloseDB(db)
+
+	// Connect to Redis
+	redisCache, err := cache.NewRedisCache(cfg.GetRedisAddr(), cfg.RedisPassword, cfg.RedisDB, "findme:")
+	if err != nil {
+		fatal("Failed to connect to Redis", err)
+	}
+	defer redisCache.Close()
+
+	// Connect to Qdrant
+	qdrantClient, err := database.NewQdrantClient(database.QdrantConfig{
+		Host:   cfg.QdrantHost,
+		Port:   cfg.QdrantGRPCPort,
+		APIKey: cfg.QdrantAPIKey,
+	})
+	if err != nil {
+		fatal("Failed to create Qdrant client", err)
+	}
+	defer qdrantClient.Close()
+
+	// Repositories
+	userRepo := postgres.TraceUsers(postgres.NewUserRepository(db))
+	adminRepo := postgres.NewAdminRepository(db)
+	moderationRepo := postgres.NewModerationRepository(db)
+	matchRepo := postgres.NewMatchRepository(db)
+	safetyRepo := postgres.NewSafetyRepository(db)
+	callRepo := postgres.NewCallRepository(db)
+	notificationRepo := postgres.NewNotificationRepository(db)
+	pushRepo := postgres.NewPushRepository(db)
+	chatRepo := postgres.NewChatRepository(db)
+	embeddingRepo := postgres.NewEmbeddingRepository(db)
+	profileVectorRepo := qdrantrepo.NewProfileRepository(qdrantClient, cfg.QdrantCollection)
+	sessionRepo := redisrepo.NewSessionRepository(redisCache, userRepo)
+	notificationCountRepo := redisrepo.NewNotificationCountRepository(redisCache, notificationRepo)
+
+	// Bring the schema up to date before anything reads it
+	if cfg.MigrateOnStartup {
+		migrator, err := migrations.New(db, profileVectorRepo, cfg.QdrantVectorSize)
+		if err != nil {
+			fatal("Failed to load migrations", err)
+		}
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			fatal("Failed to apply migrations", err)
+		}
+		slog.Info("Migrations up to date", "applied", applied)
+	}
+
+	// Services
+	eventBus := events.NewBus()
+	moderationService := moderation.NewModerationService(
+		moderationRepo,
+		eventBus,
+		time.Duration(cfg.ModerationClaimMinutes)*time.Minute,
+	)
+	contentFilter, err := contentsafety.NewFilter(contentsafety.Config{
+		Enabled:        cfg.ContentFilterEnabled,
+		RulesFile:      cfg.ContentRulesFile,
+		ReloadInterval: time.Duration(cfg.ContentRulesReloadSeconds) * time.Second,
+	})
+	if err != nil {
+		fatal("Failed to load content safety rules", err)
+	}
+	// Every bio update goes through the content filter
+	userRepo = contentsafety.ScreenBios(userRepo, contentFilter, moderationService)
+
+	jwtManager := jwt.NewJWTManager(cfg.JWTSecret, cfg.JWTAccessTokenMinutes, cfg.JWTRefreshTokenDays)
+	authService := auth.NewAuthService(userRepo, jwtManager)
+	adminService := admin.NewAdminService(adminRepo, userRepo, sessionRepo, eventBus)
+
+	safetyService := safety.NewSafetyService(
+		safetyRepo, userRepo, adminRepo, sessionRepo, moderationService, eventBus,
+		safety.Config{
+			AutoSuspendThreshold:       cfg.ReportAutoSuspendThreshold,
+			AutoSuspendSevereThreshold: cfg.ReportAutoSuspendSevereThreshold,
+			AutoSuspendWindow:          time.Duration(cfg.ReportAutoSuspendWindowHours) * time.Hour,
+		},
+	)
+
+	scoreWeights := matching.ScoreWeights{
+		SharedInterests:     cfg.MatchingWeightSharedInterests,
+		Distance:            cfg.MatchingWeightDistance,
+		AgeFit:              cfg.MatchingWeightAgeFit,
+		EmbeddingSimilarity: cfg.MatchingWeightEmbeddingSimilarity,
+		Recency:             cfg.MatchingWeightRecency,
+	}
+	matchingService := matching.NewMatchingService(matchRepo, profileVectorRepo, eventBus, matching.Config{
+		WeeklyCount:         cfg.MatchingWeeklyCount,
+		MinScore:            cfg.MatchingMinScore,
+		ExcludePreviousDays: cfg.MatchingExcludePreviousDays,
+		Weights:             scoreWeights,
+		FairnessWeight:      cfg.MatchingFairnessWeight,
+	})
+
+	matchService := match.NewMatchService(matchRepo, eventBus)
+	candidateFinder := matching.NewCandidateFinder(matchRepo, profileVectorRepo, matching.Config{
+		ExcludePreviousDays: cfg.MatchingExcludePreviousDays,
+		Weights:             scoreWeights,
+	})
+
+	embedder, err := embedding.NewEmbedder(
+		cfg.EmbeddingProvider, cfg.OpenAIAPIKey, cfg.OpenAIEmbeddingModel, cfg.QdrantVectorSize,
+	)
+	if err != nil {
+		fatal("Failed to create embedder", err)
+	}
+	embeddingSync := embedding.NewSyncService(embeddingRepo, profileVectorRepo, embedder, embedding.Config{
+		BatchSize: cfg.EmbeddingBatchSize,
+	})
+
+	if err := adminService.BootstrapAdmins(context.Background(), cfg.AdminEmails); err != nil {
+		fatal("Failed to bootstrap admin accounts", err)
+	}
+
+	realtimeHub := realtime.NewRedisHub(redisCache.Client(), "findme:")
+	signaling := realtime.NewSignaling(realtimeHub, matchRepo, safetyRepo)
+	realtime.Subscribe(eventBus, realtimeHub)
+	ringTimeout := time.Duration(cfg.CallRingTimeoutSeconds) * time.Second
+	iceProvider, err := ice.NewProvider(ice.Config{
+		Provider:         cfg.ICEProvider,
+		STUNServers:      cfg.WebRTCSTUNServers,
+		TURNServers:      cfg.WebRTCTURNServers,
+		TURNUsername:     cfg.WebRTCTURNUsername,
+		TURNCredential:   cfg.WebRTCTURNCredential,
+		TURNSharedSecret: cfg.TURNSharedSecret,
+		CredentialTTL:    time.Duration(cfg.ICECredentialTTLSeconds) * time.Second,
+		TwilioAccountSID: cfg.TwilioAccountSID,
+		TwilioAuthToken:  cfg.TwilioAuthToken,
+		TwilioAPIKey:     cfg.TwilioAPIKey,
+		TwilioAPISecret:  cfg.TwilioAPISecret,
+	})
+	if err != nil {
+		fatal("Failed to create ICE provider", err)
+	}
+	pushProviders, err := push.NewProviders(push.Config{
+		Enabled: cfg.PushNotificationsEnabled,
+		APNs: push.APNsConfig{
+			KeyPath:    cfg.APNSKeyPath,
+			KeyID:      cfg.APNSKeyID,
+			TeamID:     cfg.APNSTeamID,
+			Topic:      cfg.APNSBundleID,
+			Production: cfg.APNSProduction,
+		},
+		FCMCredentialsFile: cfg.FCMCredentialsFile,
+	})
+	if err != nil {
+		fatal("Failed to create push providers", err)
+	}
+	pushService := push.NewPushService(pushRepo, pushProviders)
+	callService := calls.NewCallService(callRepo, iceProvider, realtimeHub, pushService, ringTimeout)
+	notificationService := notifications.NewNotificationService(notificationRepo, notificationCountRepo, realtimeHub, pushService)
+	notificationDispatcher := notifications.NewDispatcher(notificationService, 4, 1000)
+	notificationDispatcher.Subscribe(eventBus)
+	chatService := chat.NewChatService(
+		chatRepo, realtimeHub, pushService, contentFilter, moderationService, cfg.ChatMaxMessageLength,
+	)
+	chat.Subscribe(eventBus, chatService)
+
+	// Background jobs stop when the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	realtimeHub.Start(jobsCtx)
+	contentFilter.Start(jobsCtx)
+	notificationDispatcher.Start(jobsCtx)
+	if cfg.MatchingEnabled {
+		matching.NewScheduler(matchingService, time.Duration(cfg.MatchingIntervalMinutes)*time.Minute).Start(jobsCtx)
+	}
+	if cfg.MatchSweeperEnabled {
+		match.NewSweeper(matchRepo, redisCache, eventBus, match.SweeperConfig{
+			Interval:       time.Duration(cfg.MatchSweeperIntervalSeconds) * time.Second,
+			BatchSize:      cfg.MatchSweeperBatchSize,
+			ReminderBefore: time.Duration(cfg.MatchReminderHours) * time.Hour,
+		}).Start(jobsCtx)
+	}
+	calls.NewSweeper(callRepo, realtimeHub, eventBus, calls.SweeperConfig{
+		Interval:    time.Duration(cfg.CallSweeperIntervalSeconds) * time.Second,
+		RingTimeout: ringTimeout,
+		MaxDuration: time.Duration(cfg.CallMaxDurationMinutes) * time.Minute,
+	}).Start(jobsCtx)
+	if cfg.EmbeddingSyncEnabled {
+		embedding.NewWorker(
+			embeddingSync,
+			time.Duration(cfg.EmbeddingSyncIntervalSeconds)*time.Second,
+			cfg.EmbeddingBatchSize,
+		).Start(jobsCtx)
+	}
+
+	// Health checks. Postgres and Redis back every request; Qdrant has a
+	// Postgres fallback and storage and email are not on the request path,
+	// so they are only reported.
+	healthRegistry := health.NewRegistry(time.Duration(cfg.ReadinessCacheSeconds) * time.Second)
+	healthRegistry.Register(health.Checker{Name: "postgres", Check: db.PingContext, Critical: true})
+	healthRegistry.Register(health.Checker{
+		Name:     "redis",
+		Check:    func(ctx context.Context) error { return redisCache.Client().Ping(ctx).Err() },
+		Critical: true,
+	})
+	healthRegistry.Register(health.Checker{
+		Name: "qdrant",
+		Check: func(ctx context.Context) error {
+			_, err := qdrantClient.HealthCheck(ctx)
+			return err
+		},
+	})
+	if cfg.S3BucketName != "" {
+		healthRegistry.Register(health.Checker{Name: "storage", Check: health.Dial(storageAddr(cfg))})
+	}
+	if cfg.SMTPHost != "" {
+		healthRegistry.Register(health.Checker{
+			Name:    "smtp",
+			Check:   health.SMTP(net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort)),
+			Timeout: 5 * time.Second,
+		})
+	}
+
+	// Metrics are served on their own port unless it is the API port
+	var metricsHandler http.Handler
+	var metricsSrv *http.Server
+	if cfg.PrometheusEnabled {
+		metrics.Registry.MustRegister(
+			collectors.NewDBStatsCollector(db, "postgres"),
+			metrics.NewRedisPoolCollector(redisCache.Client(), "cache"),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Namespace: "findme",
+				Name:      "realtime_connections",
+				Help:      "WebSocket connections open on this instance.",
+			}, func() float64 { return float64(realtimeHub.Stats().Connections) }),
+		)
+		handler := promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{Registry: metrics.Registry})
+		if cfg.PrometheusPort == cfg.ServerPort {
+			metricsHandler = handler
+		} else {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", handler)
+			metricsSrv = &http.Server{
+				Addr:              cfg.ServerHost + ":" + cfg.PrometheusPort,
+				Handler:           mux,
+				ReadHeaderTimeout: 5 * time.Second,
+			}
+		}
+	}
+
 	// Initialize router
-	router := setupRouter()
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, sessionRepo)
+	rateLimiter := middleware.NewRateLimiter(
+		ratelimit.NewFallbackLimiter(
+			ratelimit.NewRedisLimiter(redisCache.Client(), "findme:"),
+			ratelimit.NewMemoryLimiter(),
+		),
+		cfg.RateLimitEnabled,
+	)
+	router := setupRouter(routerDeps{
+		authHandler:         handlers.NewAuthHandler(authService),
+		adminHandler:        handlers.NewAdminHandler(adminService),
+		matchHandler:        handlers.NewMatchHandler(matchService),
+		matchingHandler:     handlers.NewMatchingHandler(matchingService, candidateFinder),
+		moderationHandler:   handlers.NewModerationHandler(moderationService),
+		safetyHandler:       handlers.NewSafetyHandler(safetyService),
+		callHandler:         handlers.NewCallHandler(callService),
+		notificationHandler: handlers.NewNotificationHandler(notificationService),
+		pushHandler:         handlers.NewPushHandler(pushService),
+		chatHandler:         handlers.NewChatHandler(chatService),
+		wsHandler: handlers.NewWebSocketHandler(authMiddleware, realtimeHub, signaling.HandleMessage, realtime.Config{
+			MaxMessageSize: int64(cfg.WSMaxMessageBytes),
+			SendBuffer:     cfg.WSSendBufferSize,
+			PingInterval:   time.Duration(cfg.WSPingIntervalSeconds) * time.Second,
+		}),
+		healthHandler:  handlers.NewHealthHandler(healthRegistry, serviceName, serviceVersion),
+		authMiddleware: authMiddleware,
+		authRateLimit: rateLimiter.Limit(middleware.RateLimitPolicy{
+			Name:  "auth",
+			Limit: ratelimit.Limit{Requests: cfg.RateLimitAuthPerHour, Period: time.Hour},
+		}),
+		apiRateLimit: rateLimiter.Limit(middleware.RateLimitPolicy{
+			Name:  "api",
+			Limit: ratelimit.Limit{Requests: cfg.RateLimitPerMin, Period: time.Minute},
+		}),
+		trustedProxies: cfg.TrustedProxies,
+		logger:         appLogger,
+		logRequests:    cfg.LogRequests,
+		metrics:        cfg.PrometheusEnabled,
+		metricsHandler: metricsHandler,
+	})
 
 	// Create HTTP server
 	srv := &http.Server{
-		Addr:    ":" + port,
+		Addr:    cfg.ServerHost + ":" + cfg.ServerPort,
 		Handler: router,
 	}
 
 	// Start server in goroutine
 	go func() {
-		log.Printf("🚀 FindMe API Server starting on http://localhost:%s", port)
-		log.Printf("📝 Environment: %s", env)
-		log.Printf("❤️  FindMe - Find your love with verified people")
-		
+		slog.Info("FindMe API server starting", "addr", srv.Addr, "environment", cfg.Environment)
+
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			fatal("Failed to start server", err)
 		}
 	}()
+	if metricsSrv != nil {
+		go func() {
+			slog.Info("Metrics server starting", "addr", metricsSrv.Addr)
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fatal("Failed to start metrics server", err)
+			}
+		}()
+	}
 
 	// Wait for interrupt signal to gracefully shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	log.Println("🛑 Shutting down server...")
+	// Fail readiness first so load balancers stop routing here while the
+	// server still accepts requests
+	healthRegistry.Shutdown()
+	drain := time.Duration(cfg.ShutdownDrainSeconds) * time.Second
+	slog.Info("Shutting down server", "drain", drain)
+	time.Sleep(drain)
+	stopJobs()
+	realtimeHub.Close()
 
 	// Graceful shutdown with 5 second timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
+		fatal("Server forced to shutdown", err)
+	}
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			slog.Error("Failed to shut down metrics server", "error", err)
+		}
 	}
 
-	log.Println("✅ Server exited successfully")
+	slog.Info("Server exited")
 }
 
-func setupRouter(authHandler *handlers.AuthHandler, authMiddleware *middleware.AuthMiddleware) *gin.Engine {
+// storageAddr is the host:port of the S3 endpoint, a custom one such as
+// MinIO if configured.
+func storageAddr(cfg *config.Config) string {
+	endpoint := cfg.S3Endpoint
+	if endpoint == "" {
+		return "s3." + cfg.AWSRegion + ".amazonaws.com:443"
+	}
+	port := "443"
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		endpoint = u.Host
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	if _, _, err := net.SplitHostPort(endpoint); err == nil {
+		return endpoint
+	}
+	return net.JoinHostPort(endpoint, port)
+}
+
+// fatal logs err and exits. Like log.Fatal, it skips deferred cleanup.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+func setupRouter(deps routerDeps) *gin.Engine {
 	router := gin.New()
+	// Only listed proxies may set the client IP that rate limits key on
+	if err := router.SetTrustedProxies(deps.trustedProxies); err != nil {
+		fatal("Invalid TRUSTED_PROXIES", err)
+	}
 
 	// Apply middleware
-	router.Use(middleware.Logger())
-	router.Use(gin.Recovery())
+	router.Use(middleware.Tracing(serviceName))
+	router.Use(middleware.RequestID())
+	if deps.logRequests {
+		router.Use(middleware.Logger(deps.logger))
+	}
+	router.Use(middleware.Recovery())
+	router.Use(middleware.Errors())
+	if deps.metrics {
+		router.Use(middleware.Metrics())
+	}
 	router.Use(middleware.CORS())
-
-	// Health check endpoint
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "ok",
-			"service": "findme-api",
-			"version": "0.1.0",
-		})
+	router.NoRoute(func(c *gin.Context) {
+		middleware.AbortWithError(c, apperror.NotFound("route not found"))
 	})
 
-	// Ready check endpoint
-	router.GET("/ready", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status": "ready",
-		})
-	})
+	// Liveness, readiness and, for admins, the state of every dependency
+	router.GET("/health", deps.healthHandler.Live)
+	router.GET("/ready", deps.healthHandler.Ready)
+	router.GET("/health/details",
+		deps.authMiddleware.RequireAuth(),
+		deps.authMiddleware.RequireRole(models.RoleAdmin),
+		deps.apiRateLimit,
+		deps.healthHandler.Details,
+	)
+
+	if deps.metricsHandler != nil {
+		router.GET("/metrics", gin.WrapH(deps.metricsHandler))
+	}
+
+	// WebSocket for realtime events and call signaling; authenticates itself
+	// since browsers cannot send an Authorization header
+	router.GET("/ws", deps.apiRateLimit, deps.wsHandler.Connect)
 
 	// API v1 group
 	v1 := router.Group("/api/v1")
@@ -101,24 +542,105 @@ func setupRouter(authHandler *handlers.AuthHandler, authMiddleware *middleware.A
 			})
 		})
 
-		// Auth routes (public)
+		// Auth routes (public), limited per IP. Credential checks get the
+		// stricter limit against guessing; refreshes happen routinely.
 		auth := v1.Group("/auth")
 		{
-			auth.POST("/register", authHandler.Register)
-			auth.POST("/login", authHandler.Login)
-			auth.POST("/refresh", authHandler.RefreshToken)
-			auth.GET("/verify-email", authHandler.VerifyEmail)
-			auth.POST("/password-reset/request", authHandler.RequestPasswordReset)
-			auth.POST("/password-reset/reset", authHandler.ResetPassword)
+			credentials := auth.Group("", deps.authRateLimit)
+			credentials.POST("/register", deps.authHandler.Register)
+			credentials.POST("/login", deps.authHandler.Login)
+			credentials.POST("/password-reset/request", deps.authHandler.RequestPasswordReset)
+			credentials.POST("/password-reset/reset", deps.authHandler.ResetPassword)
+
+			auth.POST("/refresh", deps.apiRateLimit, deps.authHandler.RefreshToken)
+			auth.GET("/verify-email", deps.apiRateLimit, deps.authHandler.VerifyEmail)
 		}
 
-		// Protected routes
+		// Protected routes, limited per user
 		protected := v1.Group("/")
-		protected.Use(authMiddleware.RequireAuth())
+		protected.Use(deps.authMiddleware.RequireAuth(), deps.apiRateLimit)
 		{
-			protected.GET("/profile", authHandler.GetProfile)
+			protected.GET("/profile", deps.authHandler.GetProfile)
+
+			matches := protected.Group("/matches")
+			matches.GET("/weekly", deps.matchHandler.Weekly)
+			matches.GET("/active", deps.matchHandler.Active)
+			matches.POST("/:id/accept", deps.matchHandler.Accept)
+			matches.POST("/:id/reject", deps.matchHandler.Reject)
+			matches.POST("/:id/end", deps.matchHandler.End)
+
+			protected.GET("/blocks", deps.safetyHandler.ListBlocks)
+			protected.POST("/users/:id/block", deps.safetyHandler.Block)
+			protected.DELETE("/users/:id/block", deps.safetyHandler.Unblock)
+			protected.POST("/users/:id/report", deps.safetyHandler.Report)
+
+			notificationRoutes := protected.Group("/notifications")
+			notificationRoutes.GET("", deps.notificationHandler.List)
+			notificationRoutes.GET("/unread-count", deps.notificationHandler.UnreadCount)
+			notificationRoutes.POST("/read-all", deps.notificationHandler.MarkAllRead)
+			notificationRoutes.POST("/:id/read", deps.notificationHandler.MarkRead)
+			notificationRoutes.GET("/preferences", deps.pushHandler.GetPreferences)
+			notificationRoutes.PUT("/preferences", deps.pushHandler.UpdatePreferences)
+
+			protected.POST("/devices", deps.pushHandler.RegisterDevice)
+			protected.DELETE("/devices/:token", deps.pushHandler.UnregisterDevice)
+
+			conversations := protected.Group("/conversations")
+			conversations.GET("", deps.chatHandler.ListConversations)
+			conversations.GET("/:id/messages", deps.chatHandler.Messages)
+			conversations.POST("/:id/messages", deps.chatHandler.Send)
+			conversations.POST("/:id/read", deps.chatHandler.MarkRead)
+			conversations.DELETE("/:id/messages/:messageId", deps.chatHandler.DeleteMessage)
+
+			callRoutes := protected.Group("/calls")
+			callRoutes.POST("/initiate", deps.callHandler.Initiate)
+			callRoutes.GET("/history", deps.callHandler.History)
+			callRoutes.POST("/:id/accept", deps.callHandler.Accept)
+			callRoutes.POST("/:id/reject", deps.callHandler.Reject)
+			callRoutes.POST("/:id/end", deps.callHandler.End)
+			callRoutes.POST("/:id/feedback", deps.callHandler.Feedback)
 			// TODO: Add more protected routes
 		}
+
+		// Admin routes (staff only)
+		admin := v1.Group("/admin")
+		admin.Use(
+			deps.authMiddleware.RequireAuth(),
+			deps.authMiddleware.RequireRole(models.RoleAdmin, models.RoleModerator, models.RoleSupport),
+			deps.apiRateLimit,
+		)
+		{
+			users := admin.Group("/users")
+			users.GET("", deps.adminHandler.SearchUsers)
+			users.GET("/:id", deps.adminHandler.GetUser)
+			users.GET("/:id/audit", deps.adminHandler.AuditTrail)
+			users.GET("/:id/candidates", deps.matchingHandler.Candidates)
+
+			support := users.Group("")
+			support.Use(deps.authMiddleware.RequireRole(models.RoleAdmin, models.RoleSupport))
+			support.POST("/:id/suspend", deps.adminHandler.Suspend)
+			support.POST("/:id/unsuspend", deps.adminHandler.Unsuspend)
+			support.POST("/:id/logout", deps.adminHandler.ForceLogout)
+			support.PUT("/:id/verification", deps.adminHandler.OverrideVerification)
+
+			users.PUT("/:id/role", deps.authMiddleware.RequireRole(models.RoleAdmin), deps.adminHandler.ChangeRole)
+
+			admin.POST("/matching/runs", deps.authMiddleware.RequireRole(models.RoleAdmin), deps.matchingHandler.RunMatching)
+			admin.GET("/realtime/stats", deps.authMiddleware.RequireRole(models.RoleAdmin), deps.wsHandler.Stats)
+
+			mod := admin.Group("/moderation")
+			mod.Use(deps.authMiddleware.RequireRole(models.RoleAdmin, models.RoleModerator))
+			mod.GET("/queue", deps.moderationHandler.ListQueue)
+			mod.GET("/stats", deps.moderationHandler.Stats)
+			mod.POST("/items", deps.moderationHandler.Enqueue)
+			mod.POST("/claim", deps.moderationHandler.ClaimNext)
+			mod.GET("/items/:id", deps.moderationHandler.GetItem)
+			mod.GET("/items/:id/audit", deps.moderationHandler.AuditTrail)
+			mod.POST("/items/:id/claim", deps.moderationHandler.Claim)
+			mod.POST("/items/:id/release", deps.moderationHandler.Release)
+			mod.POST("/items/:id/approve", deps.moderationHandler.Approve)
+			mod.POST("/items/:id/reject", deps.moderationHandler.Reject)
+		}
 	}
 
 	return router
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alexcolls/findme/internal/config"
+	qdrantrepo "github.com/alexcolls/findme/internal/repository/qdrant"
+	"github.com/alexcolls/findme/migrations"
+	"github.com/alexcolls/findme/pkg/database"
+)
+
+const usage = `Usage: migrate [command]
+
+Commands:
+  up         apply every pending migration (default)
+  down [n]   revert the last n applied migrations (default 1)
+  status     list migrations and when they were applied
+`
+
+// migrate applies the embedded Postgres migrations and Qdrant steps. It is
+// safe to run alongside API replicas started with MIGRATE_ON_STARTUP; an
+// advisory lock makes one wait for the other.
+func main() {
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	command := flag.Arg(0)
+	if command == "" {
+		command = "up"
+	}
+	steps := 1
+	if command == "down" && flag.NArg() > 1 {
+		n, err := strconv.Atoi(flag.Arg(1))
+		if err != nil || n < 1 {
+			log.Fatalf("Invalid number of migrations to revert: %q", flag.Arg(1))
+		}
+		steps = n
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := database.NewPostgresDB(database.PostgresConfig{
+		DSN:             cfg.GetDatabaseDSN(),
+		MaxOpenConns:    2,
+		MaxIdleConns:    1,
+		ConnMaxLifetime: 5 * time.Minute,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.CloseDB(db)
+
+	qdrantClient, err := database.NewQdrantClient(database.QdrantConfig{
+		Host:   cfg.QdrantHost,
+		Port:   cfg.QdrantGRPCPort,
+		APIKey: cfg.QdrantAPIKey,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create Qdrant client: %v", err)
+	}
+	defer qdrantClient.Close()
+
+	migrator, err := migrations.New(
+		db,
+		qdrantrepo.NewProfileRepository(qdrantClient, cfg.QdrantCollection),
+		cfg.QdrantVectorSize,
+	)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("Migration failed after %d applied: %v", applied, err)
+		}
+		log.Printf("✅ %d migrations applied", applied)
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			log.Fatalf("Rollback failed after %d reverted: %v", reverted, err)
+		}
+		log.Printf("✅ %d migrations reverted", reverted)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("Failed to load migration status: %v", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TARGET\tVERSION\tNAME\tAPPLIED")
+		for _, s := range statuses {
+			applied := "pending"
+			if s.Applied {
+				applied = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%s\t%06d\t%s\t%s\n", s.Target, s.Version, s.Name, applied)
+		}
+		w.Flush()
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
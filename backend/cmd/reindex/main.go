@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/alexcolls/findme/internal/config"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	qdrantrepo "github.com/alexcolls/findme/internal/repository/qdrant"
+	"github.com/alexcolls/findme/internal/service/embedding"
+	"github.com/alexcolls/findme/pkg/database"
+)
+
+// reindex rebuilds the Qdrant profile collection from Postgres. It is safe
+// to run while the API is serving traffic; outbox entries written meanwhile
+// are applied on top by the sync worker.
+func main() {
+	prune := flag.Bool("prune", true, "delete points for users that no longer exist")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	db, err := database.NewPostgresDB(database.PostgresConfig{
+		DSN:             cfg.GetDatabaseDSN(),
+		MaxOpenConns:    5,
+		MaxIdleConns:    2,
+		ConnMaxLifetime: 5 * time.Minute,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.CloseDB(db)
+
+	qdrantClient, err := database.NewQdrantClient(database.QdrantConfig{
+		Host:   cfg.QdrantHost,
+		Port:   cfg.QdrantGRPCPort,
+		APIKey: cfg.QdrantAPIKey,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create Qdrant client: %v", err)
+	}
+	defer qdrantClient.Close()
+
+	embedder, err := embedding.NewEmbedder(
+		cfg.EmbeddingProvider, cfg.OpenAIAPIKey, cfg.OpenAIEmbeddingModel, cfg.QdrantVectorSize,
+	)
+	if err != nil {
+		log.Fatalf("Failed to create embedder: %v", err)
+	}
+
+	syncService := embedding.NewSyncService(
+		postgres.NewEmbeddingRepository(db),
+		qdrantrepo.NewProfileRepository(qdrantClient, cfg.QdrantCollection),
+		embedder,
+		embedding.Config{BatchSize: cfg.EmbeddingBatchSize},
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("🔮 Reindexing profile embeddings into %s...", cfg.QdrantCollection)
+
+	result, err := syncService.Reindex(ctx, *prune)
+	if err != nil {
+		log.Fatalf("Reindex failed: %v", err)
+	}
+
+	log.Printf("✅ Reindex complete: %d profiles indexed, %d stale points pruned", result.Indexed, result.Pruned)
+}
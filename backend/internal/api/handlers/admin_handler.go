@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/alexcolls/findme/internal/api/middleware"
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/admin"
+	"github.com/alexcolls/findme/pkg/apperror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AdminHandler struct {
+	adminService admin.AdminService
+}
+
+func NewAdminHandler(adminService admin.AdminService) *AdminHandler {
+	return &AdminHandler{adminService: adminService}
+}
+
+func (h *AdminHandler) SearchUsers(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	filter := models.UserSearchFilter{
+		Query:  c.Query("q"),
+		Role:   c.Query("role"),
+		Limit:  limit,
+		Offset: offset,
+	}
+	if active := c.Query("active"); active != "" {
+		value, err := strconv.ParseBool(active)
+		if err != nil {
+			middleware.AbortWithError(c, apperror.Validation("invalid active filter", nil))
+			return
+		}
+		filter.Active = &value
+	}
+
+	users, err := h.adminService.SearchUsers(c.Request.Context(), filter)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Internal("failed to search users", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	userID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.GetUser(c.Request.Context(), userID)
+	if err != nil {
+		renderAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+func (h *AdminHandler) ChangeRole(c *gin.Context) {
+	actorID, userID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChangeRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.AbortWithError(c, bindError(err))
+		return
+	}
+
+	if err := h.adminService.ChangeRole(c.Request.Context(), actorID, userID, &req); err != nil {
+		renderAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "role updated", "role": req.Role})
+}
+
+func (h *AdminHandler) Suspend(c *gin.Context) {
+	h.reasonAction(c, h.adminService.Suspend, "user suspended")
+}
+
+func (h *AdminHandler) Unsuspend(c *gin.Context) {
+	h.reasonAction(c, h.adminService.Unsuspend, "user reinstated")
+}
+
+func (h *AdminHandler) ForceLogout(c *gin.Context) {
+	h.reasonAction(c, h.adminService.ForceLogout, "all sessions revoked")
+}
+
+func (h *AdminHandler) OverrideVerification(c *gin.Context) {
+	actorID, userID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.VerificationOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.AbortWithError(c, bindError(err))
+		return
+	}
+
+	if err := h.adminService.OverrideVerification(c.Request.Context(), actorID, userID, &req); err != nil {
+		renderAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "verification updated", "verified": *req.Verified})
+}
+
+func (h *AdminHandler) AuditTrail(c *gin.Context) {
+	userID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	entries, err := h.adminService.AuditTrail(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		renderAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
+
+type reasonActionFunc func(ctx context.Context, actorID, userID uuid.UUID, reason string) error
+
+func (h *AdminHandler) reasonAction(c *gin.Context, action reasonActionFunc, message string) {
+	actorID, userID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.AdminActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.AbortWithError(c, bindError(err))
+		return
+	}
+
+	if err := action(c.Request.Context(), actorID, userID, req.Reason); err != nil {
+		renderAdminError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
+
+// callerAndIDParam returns the authenticated user and the resource ID from
+// the :id path parameter.
+func callerAndIDParam(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	actorID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return uuid.Nil, uuid.Nil, false
+	}
+	userID, ok := parseIDParam(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	return actorID, userID, true
+}
+
+func renderAdminError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, postgres.ErrAdminTargetNotFound):
+		middleware.AbortWithError(c, apperror.NotFound(err.Error()))
+	case errors.Is(err, admin.ErrSelfModification), errors.Is(err, admin.ErrOutranked):
+		middleware.AbortWithError(c, apperror.Forbidden(err.Error()))
+	default:
+		middleware.AbortWithError(c, apperror.Internal("admin request failed", err))
+	}
+}
@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/alexcolls/findme/internal/api/middleware"
 	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
 	"github.com/alexcolls/findme/internal/service/auth"
+	"github.com/alexcolls/findme/pkg/apperror"
 	"github.com/gin-gonic/gin"
 )
 
@@ -20,13 +23,13 @@ func NewAuthHandler(authService auth.AuthService) *AuthHandler {
 func (h *AuthHandler) Register(c *gin.Context) {
 	var req models.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		middleware.AbortWithError(c, bindError(err))
 		return
 	}
 
 	response, err := h.authService.Register(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		renderAuthError(c, err)
 		return
 	}
 
@@ -36,13 +39,13 @@ func (h *AuthHandler) Register(c *gin.Context) {
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req models.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		middleware.AbortWithError(c, bindError(err))
 		return
 	}
 
 	response, err := h.authService.Login(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		renderAuthError(c, err)
 		return
 	}
 
@@ -54,13 +57,13 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		RefreshToken string `json:"refresh_token" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		middleware.AbortWithError(c, bindError(err))
 		return
 	}
 
 	response, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		renderAuthError(c, err)
 		return
 	}
 
@@ -70,12 +73,12 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 func (h *AuthHandler) VerifyEmail(c *gin.Context) {
 	token := c.Query("token")
 	if token == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "token required"})
+		middleware.AbortWithError(c, apperror.Validation("invalid request", map[string]string{"token": "is required"}))
 		return
 	}
 
 	if err := h.authService.VerifyEmail(c.Request.Context(), token); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		renderAuthError(c, err)
 		return
 	}
 
@@ -87,13 +90,13 @@ func (h *AuthHandler) RequestPasswordReset(c *gin.Context) {
 		Email string `json:"email" binding:"required,email"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		middleware.AbortWithError(c, bindError(err))
 		return
 	}
 
 	token, err := h.authService.RequestPasswordReset(c.Request.Context(), req.Email)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process request"})
+		middleware.AbortWithError(c, apperror.Internal("failed to process request", err))
 		return
 	}
 
@@ -110,12 +113,12 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 		NewPassword string `json:"new_password" binding:"required,min=8"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		middleware.AbortWithError(c, bindError(err))
 		return
 	}
 
 	if err := h.authService.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		renderAuthError(c, err)
 		return
 	}
 
@@ -125,7 +128,7 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
 		return
 	}
 
@@ -134,3 +137,21 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 		"message": "profile endpoint - to be implemented",
 	})
 }
+
+func renderAuthError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, postgres.ErrEmailTaken):
+		middleware.AbortWithError(c, apperror.Conflict(err.Error()))
+	case errors.Is(err, auth.ErrInvalidDateOfBirth):
+		middleware.AbortWithError(c, apperror.Validation("invalid request", map[string]string{"date_of_birth": "must be formatted as YYYY-MM-DD"}))
+	case errors.Is(err, postgres.ErrUserTokenInvalid):
+		middleware.AbortWithError(c, apperror.Validation("invalid request", map[string]string{"token": "is invalid or expired"}))
+	case errors.Is(err, auth.ErrInvalidCredentials), errors.Is(err, auth.ErrInvalidRefreshToken),
+		errors.Is(err, auth.ErrRefreshTokenRevoked):
+		middleware.AbortWithError(c, apperror.Unauthorized(err.Error()))
+	case errors.Is(err, auth.ErrAccountInactive):
+		middleware.AbortWithError(c, apperror.Forbidden(err.Error()))
+	default:
+		middleware.AbortWithError(c, apperror.Internal("authentication request failed", err))
+	}
+}
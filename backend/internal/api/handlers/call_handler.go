@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/alexcolls/findme/internal/api/middleware"
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/calls"
+	"github.com/alexcolls/findme/pkg/apperror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CallHandler struct {
+	callService calls.CallService
+}
+
+func NewCallHandler(callService calls.CallService) *CallHandler {
+	return &CallHandler{callService: callService}
+}
+
+func (h *CallHandler) Initiate(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	var req models.InitiateCallRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.AbortWithError(c, bindError(err))
+		return
+	}
+
+	call, err := h.callService.Initiate(c.Request.Context(), userID, &req)
+	if err != nil {
+		renderCallError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, call)
+}
+
+func (h *CallHandler) Accept(c *gin.Context) {
+	userID, callID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	call, err := h.callService.Accept(c.Request.Context(), userID, callID)
+	if err != nil {
+		renderCallError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, call)
+}
+
+func (h *CallHandler) Reject(c *gin.Context) {
+	userID, callID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	call, err := h.callService.Reject(c.Request.Context(), userID, callID)
+	if err != nil {
+		renderCallError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, call)
+}
+
+func (h *CallHandler) End(c *gin.Context) {
+	userID, callID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	// The body is optional
+	var req models.EndCallRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			middleware.AbortWithError(c, bindError(err))
+			return
+		}
+	}
+
+	call, err := h.callService.End(c.Request.Context(), userID, callID, &req)
+	if err != nil {
+		renderCallError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, call)
+}
+
+func (h *CallHandler) Feedback(c *gin.Context) {
+	userID, callID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.CallFeedbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.AbortWithError(c, bindError(err))
+		return
+	}
+
+	call, err := h.callService.Review(c.Request.Context(), userID, callID, &req)
+	if err != nil {
+		renderCallError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, call)
+}
+
+func (h *CallHandler) History(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	filter := models.CallHistoryFilter{Limit: limit, Offset: offset}
+	if matchID := c.Query("match_id"); matchID != "" {
+		id, err := uuid.Parse(matchID)
+		if err != nil {
+			middleware.AbortWithError(c, apperror.Validation("invalid match_id", nil))
+			return
+		}
+		filter.MatchID = &id
+	}
+
+	history, err := h.callService.History(c.Request.Context(), userID, filter)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Internal("failed to load call history", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func renderCallError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, postgres.ErrCallNotFound), errors.Is(err, postgres.ErrCallMatchNotFound):
+		middleware.AbortWithError(c, apperror.NotFound(err.Error()))
+	case errors.Is(err, postgres.ErrCallBlocked), errors.Is(err, calls.ErrNotCallee):
+		middleware.AbortWithError(c, apperror.Forbidden(err.Error()))
+	case errors.Is(err, postgres.ErrCallParticipantBusy), errors.Is(err, postgres.ErrCallStateChanged),
+		errors.Is(err, postgres.ErrCallNotReviewable):
+		middleware.AbortWithError(c, apperror.Conflict(err.Error()))
+	default:
+		middleware.AbortWithError(c, apperror.Internal("call request failed", err))
+	}
+}
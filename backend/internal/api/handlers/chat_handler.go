@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/alexcolls/findme/internal/api/middleware"
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/chat"
+	"github.com/alexcolls/findme/internal/service/contentsafety"
+	"github.com/alexcolls/findme/pkg/apperror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ChatHandler struct {
+	chatService chat.ChatService
+}
+
+func NewChatHandler(chatService chat.ChatService) *ChatHandler {
+	return &ChatHandler{chatService: chatService}
+}
+
+func (h *ChatHandler) ListConversations(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	conversations, err := h.chatService.ListConversations(c.Request.Context(), userID)
+	if err != nil {
+		renderChatError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"conversations": conversations})
+}
+
+func (h *ChatHandler) Messages(c *gin.Context) {
+	userID, conversationID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	filter := models.MessageFilter{Cursor: c.Query("cursor"), Limit: limit}
+
+	page, err := h.chatService.Messages(c.Request.Context(), userID, conversationID, filter)
+	if err != nil {
+		renderChatError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *ChatHandler) Send(c *gin.Context) {
+	userID, conversationID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.SendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.AbortWithError(c, bindError(err))
+		return
+	}
+	req.Locale = requestLocale(c)
+
+	msg, err := h.chatService.Send(c.Request.Context(), userID, conversationID, &req)
+	if err != nil {
+		renderChatError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, msg)
+}
+
+func (h *ChatHandler) MarkRead(c *gin.Context) {
+	userID, conversationID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.MarkConversationReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.AbortWithError(c, bindError(err))
+		return
+	}
+
+	if err := h.chatService.MarkRead(c.Request.Context(), userID, conversationID, &req); err != nil {
+		renderChatError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "messages marked read"})
+}
+
+func (h *ChatHandler) DeleteMessage(c *gin.Context) {
+	userID, conversationID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+	messageID, err := uuid.Parse(c.Param("messageId"))
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Validation("invalid message id", nil))
+		return
+	}
+
+	if err := h.chatService.DeleteMessage(c.Request.Context(), userID, conversationID, messageID); err != nil {
+		renderChatError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "message deleted"})
+}
+
+// requestLocale returns the caller's preferred language tag, the first one
+// in Accept-Language.
+func requestLocale(c *gin.Context) string {
+	first, _, _ := strings.Cut(c.GetHeader("Accept-Language"), ",")
+	tag, _, _ := strings.Cut(first, ";")
+	if tag = strings.TrimSpace(tag); tag == "*" {
+		return ""
+	}
+	return tag
+}
+
+func renderChatError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, postgres.ErrConversationNotFound), errors.Is(err, postgres.ErrMessageNotFound):
+		middleware.AbortWithError(c, apperror.NotFound(err.Error()))
+	case errors.Is(err, postgres.ErrConversationClosed):
+		middleware.AbortWithError(c, apperror.Conflict(err.Error()))
+	case errors.Is(err, contentsafety.ErrContentBlocked):
+		middleware.AbortWithError(c, apperror.Validation(err.Error(), nil))
+	case errors.Is(err, chat.ErrEmptyMessage), errors.Is(err, chat.ErrMessageTooLong),
+		errors.Is(err, postgres.ErrInvalidCursor):
+		middleware.AbortWithError(c, apperror.Validation(err.Error(), nil))
+	default:
+		middleware.AbortWithError(c, apperror.Internal("chat request failed", err))
+	}
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/alexcolls/findme/pkg/health"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	registry *health.Registry
+	service  string
+	version  string
+}
+
+func NewHealthHandler(registry *health.Registry, service, version string) *HealthHandler {
+	return &HealthHandler{registry: registry, service: service, version: version}
+}
+
+// Live answers as long as the process serves requests. It checks no
+// dependencies, so an outage does not get every instance restarted.
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "ok",
+		"service": h.service,
+		"version": h.version,
+	})
+}
+
+// Ready reports whether the critical dependencies are up, from a result
+// cached for a few seconds. It fails with 503 once shutdown begins.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.registry.Ready(c.Request.Context())
+
+	checks := make(gin.H, len(report.Components))
+	for _, result := range report.Components {
+		checks[result.Name] = result.Status
+	}
+	if !report.Up() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not_ready", "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": checks})
+}
+
+// Details runs every check now and reports each with its latency and error.
+// Errors can reveal internal addresses, so the route is for admins only.
+func (h *HealthHandler) Details(c *gin.Context) {
+	report := h.registry.Check(c.Request.Context())
+
+	status := http.StatusOK
+	if !report.Up() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
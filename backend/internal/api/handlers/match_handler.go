@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alexcolls/findme/internal/api/middleware"
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/match"
+	"github.com/alexcolls/findme/pkg/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+type MatchHandler struct {
+	matchService match.MatchService
+}
+
+func NewMatchHandler(matchService match.MatchService) *MatchHandler {
+	return &MatchHandler{matchService: matchService}
+}
+
+func (h *MatchHandler) Weekly(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	week, year, matches, err := h.matchService.WeeklyMatches(c.Request.Context(), userID)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Internal("failed to load matches", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"week_number": week,
+		"year":        year,
+		"matches":     matches,
+	})
+}
+
+func (h *MatchHandler) Accept(c *gin.Context) {
+	userID, matchID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.matchService.Accept(c.Request.Context(), userID, matchID)
+	if err != nil {
+		renderMatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *MatchHandler) Reject(c *gin.Context) {
+	userID, matchID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.matchService.Reject(c.Request.Context(), userID, matchID)
+	if err != nil {
+		renderMatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *MatchHandler) Active(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	matches, err := h.matchService.ActiveMatches(c.Request.Context(), userID)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Internal("failed to load matches", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"matches": matches})
+}
+
+func (h *MatchHandler) End(c *gin.Context) {
+	userID, matchID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.EndMatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.AbortWithError(c, bindError(err))
+		return
+	}
+
+	if err := h.matchService.End(c.Request.Context(), userID, matchID, &req); err != nil {
+		renderMatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "match ended"})
+}
+
+func renderMatchError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, postgres.ErrMatchNotFound):
+		middleware.AbortWithError(c, apperror.NotFound(err.Error()))
+	case errors.Is(err, postgres.ErrMatchClosed):
+		middleware.AbortWithError(c, apperror.Conflict(err.Error()))
+	default:
+		middleware.AbortWithError(c, apperror.Internal("match request failed", err))
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/alexcolls/findme/internal/api/middleware"
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/matching"
+	"github.com/alexcolls/findme/pkg/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+type MatchingHandler struct {
+	matchingService matching.MatchingService
+	candidateFinder matching.CandidateFinder
+}
+
+func NewMatchingHandler(matchingService matching.MatchingService, candidateFinder matching.CandidateFinder) *MatchingHandler {
+	return &MatchingHandler{
+		matchingService: matchingService,
+		candidateFinder: candidateFinder,
+	}
+}
+
+// RunMatching forces a matching run for a week, defaulting to the current
+// ISO week.
+func (h *MatchingHandler) RunMatching(c *gin.Context) {
+	var req models.RunMatchingRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		middleware.AbortWithError(c, bindError(err))
+		return
+	}
+
+	week, year := matching.CurrentWeek(time.Now())
+	if req.WeekNumber != 0 {
+		week = req.WeekNumber
+	}
+	if req.Year != 0 {
+		year = req.Year
+	}
+
+	run, err := h.matchingService.RunWeek(c.Request.Context(), week, year, true)
+	if errors.Is(err, postgres.ErrMatchingRunNotStarted) {
+		middleware.AbortWithError(c, apperror.Conflict(err.Error()))
+		return
+	}
+	if err != nil {
+		middleware.AbortWithError(c, &apperror.Error{
+			Code:    apperror.CodeInternal,
+			Message: "matching run failed",
+			Details: gin.H{"run": run},
+			Err:     err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// Candidates lists the candidates the finder returns for a user, to help
+// staff debug matching quality.
+func (h *MatchingHandler) Candidates(c *gin.Context) {
+	userID, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	minScore, _ := strconv.ParseFloat(c.DefaultQuery("min_score", "0"), 64)
+
+	candidates, err := h.candidateFinder.FindCandidates(c.Request.Context(), userID, models.CandidateQuery{
+		Limit:    limit,
+		Offset:   offset,
+		MinScore: minScore,
+	})
+	if errors.Is(err, postgres.ErrMatchProfileNotFound) {
+		middleware.AbortWithError(c, apperror.NotFound(err.Error()))
+		return
+	}
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Internal("failed to find candidates", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"candidates": candidates})
+}
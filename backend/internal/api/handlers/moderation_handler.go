@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/alexcolls/findme/internal/api/middleware"
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/moderation"
+	"github.com/alexcolls/findme/pkg/apperror"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ModerationHandler struct {
+	moderationService moderation.ModerationService
+}
+
+func NewModerationHandler(moderationService moderation.ModerationService) *ModerationHandler {
+	return &ModerationHandler{moderationService: moderationService}
+}
+
+func (h *ModerationHandler) ListQueue(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	items, err := h.moderationService.ListQueue(c.Request.Context(), models.ModerationQueueFilter{
+		Status:   c.Query("status"),
+		ItemType: c.Query("item_type"),
+		Limit:    limit,
+		Offset:   offset,
+	})
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Internal("failed to load moderation queue", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *ModerationHandler) Stats(c *gin.Context) {
+	stats, err := h.moderationService.Stats(c.Request.Context())
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Internal("failed to load moderation stats", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func (h *ModerationHandler) Enqueue(c *gin.Context) {
+	var req models.EnqueueModerationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.AbortWithError(c, bindError(err))
+		return
+	}
+	if req.Source == "" {
+		req.Source = "manual"
+	}
+
+	item, err := h.moderationService.Enqueue(c.Request.Context(), &req)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Internal("failed to enqueue item", err))
+		return
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *ModerationHandler) GetItem(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.moderationService.GetItem(c.Request.Context(), id)
+	if err != nil {
+		renderModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *ModerationHandler) ClaimNext(c *gin.Context) {
+	moderatorID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	item, err := h.moderationService.ClaimNext(c.Request.Context(), moderatorID, c.Query("item_type"))
+	if err != nil {
+		renderModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *ModerationHandler) Claim(c *gin.Context) {
+	moderatorID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.moderationService.Claim(c.Request.Context(), id, moderatorID)
+	if err != nil {
+		renderModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *ModerationHandler) Release(c *gin.Context) {
+	moderatorID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.moderationService.Release(c.Request.Context(), id, moderatorID); err != nil {
+		renderModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "item released"})
+}
+
+func (h *ModerationHandler) Approve(c *gin.Context) {
+	h.decide(c, h.moderationService.Approve)
+}
+
+func (h *ModerationHandler) Reject(c *gin.Context) {
+	h.decide(c, h.moderationService.Reject)
+}
+
+func (h *ModerationHandler) AuditTrail(c *gin.Context) {
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	entries, err := h.moderationService.AuditTrail(c.Request.Context(), id)
+	if err != nil {
+		renderModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": entries})
+}
+
+func (h *ModerationHandler) decide(c *gin.Context, decide func(context.Context, uuid.UUID, uuid.UUID, *models.ModerationDecisionRequest) (*models.ModerationItem, error)) {
+	moderatorID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+	id, ok := parseIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.ModerationDecisionRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		middleware.AbortWithError(c, bindError(err))
+		return
+	}
+
+	item, err := decide(c.Request.Context(), id, moderatorID, &req)
+	if err != nil {
+		renderModerationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, item)
+}
+
+func renderModerationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, postgres.ErrModerationItemNotFound):
+		middleware.AbortWithError(c, apperror.NotFound(err.Error()))
+	case errors.Is(err, postgres.ErrModerationQueueEmpty):
+		middleware.AbortWithError(c, apperror.NotFound(err.Error()))
+	case errors.Is(err, postgres.ErrModerationItemUnavailable),
+		errors.Is(err, postgres.ErrModerationItemNotClaimed):
+		middleware.AbortWithError(c, apperror.Conflict(err.Error()))
+	case errors.Is(err, moderation.ErrInvalidReasonCode):
+		middleware.AbortWithError(c, apperror.Validation(err.Error(), nil))
+	default:
+		middleware.AbortWithError(c, apperror.Internal("moderation request failed", err))
+	}
+}
+
+// parseIDParam parses the :id path parameter, rejecting the request when it
+// is not a valid UUID.
+func parseIDParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Validation("invalid id", nil))
+		return uuid.Nil, false
+	}
+	return id, true
+}
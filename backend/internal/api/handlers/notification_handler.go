@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/alexcolls/findme/internal/api/middleware"
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/notifications"
+	"github.com/alexcolls/findme/pkg/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationService notifications.NotificationService
+}
+
+func NewNotificationHandler(notificationService notifications.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+func (h *NotificationHandler) List(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	unreadOnly, _ := strconv.ParseBool(c.Query("unread"))
+	filter := models.NotificationFilter{
+		Cursor:     c.Query("cursor"),
+		Limit:      limit,
+		UnreadOnly: unreadOnly,
+	}
+
+	page, err := h.notificationService.List(c.Request.Context(), userID, filter)
+	if err != nil {
+		renderNotificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	count, err := h.notificationService.UnreadCount(c.Request.Context(), userID)
+	if err != nil {
+		renderNotificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &models.UnreadCount{UnreadCount: count})
+}
+
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, notificationID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	n, err := h.notificationService.MarkRead(c.Request.Context(), userID, notificationID)
+	if err != nil {
+		renderNotificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, n)
+}
+
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	marked, err := h.notificationService.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		renderNotificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked_read": marked})
+}
+
+func renderNotificationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, postgres.ErrNotificationNotFound):
+		middleware.AbortWithError(c, apperror.NotFound(err.Error()))
+	case errors.Is(err, postgres.ErrInvalidCursor):
+		middleware.AbortWithError(c, apperror.Validation(err.Error(), nil))
+	default:
+		middleware.AbortWithError(c, apperror.Internal("notification request failed", err))
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alexcolls/findme/internal/api/middleware"
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/push"
+	"github.com/alexcolls/findme/pkg/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+type PushHandler struct {
+	pushService push.PushService
+}
+
+func NewPushHandler(pushService push.PushService) *PushHandler {
+	return &PushHandler{pushService: pushService}
+}
+
+func (h *PushHandler) RegisterDevice(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	var req models.RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.AbortWithError(c, bindError(err))
+		return
+	}
+
+	device, err := h.pushService.RegisterDevice(c.Request.Context(), userID, &req)
+	if err != nil {
+		renderPushError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, device)
+}
+
+func (h *PushHandler) UnregisterDevice(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	if err := h.pushService.UnregisterDevice(c.Request.Context(), userID, c.Param("token")); err != nil {
+		renderPushError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "device unregistered"})
+}
+
+func (h *PushHandler) GetPreferences(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	prefs, err := h.pushService.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		renderPushError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+func (h *PushHandler) UpdatePreferences(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	var req models.UpdateNotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.AbortWithError(c, bindError(err))
+		return
+	}
+
+	prefs, err := h.pushService.UpdatePreferences(c.Request.Context(), userID, &req)
+	if err != nil {
+		renderPushError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+func renderPushError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, postgres.ErrDeviceNotFound):
+		middleware.AbortWithError(c, apperror.NotFound(err.Error()))
+	case errors.Is(err, push.ErrInvalidQuietHours), errors.Is(err, push.ErrInvalidTimezone):
+		middleware.AbortWithError(c, apperror.Validation(err.Error(), nil))
+	default:
+		middleware.AbortWithError(c, apperror.Internal("push request failed", err))
+	}
+}
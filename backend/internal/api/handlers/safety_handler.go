@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/alexcolls/findme/internal/api/middleware"
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/safety"
+	"github.com/alexcolls/findme/pkg/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+type SafetyHandler struct {
+	safetyService safety.SafetyService
+}
+
+func NewSafetyHandler(safetyService safety.SafetyService) *SafetyHandler {
+	return &SafetyHandler{safetyService: safetyService}
+}
+
+func (h *SafetyHandler) Block(c *gin.Context) {
+	userID, targetID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.safetyService.Block(c.Request.Context(), userID, targetID); err != nil {
+		renderSafetyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user blocked"})
+}
+
+func (h *SafetyHandler) Unblock(c *gin.Context) {
+	userID, targetID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.safetyService.Unblock(c.Request.Context(), userID, targetID); err != nil {
+		renderSafetyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "user unblocked"})
+}
+
+func (h *SafetyHandler) ListBlocks(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Unauthorized("unauthorized"))
+		return
+	}
+
+	blocks, err := h.safetyService.ListBlocks(c.Request.Context(), userID)
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Internal("failed to load blocked users", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"blocked_users": blocks})
+}
+
+func (h *SafetyHandler) Report(c *gin.Context) {
+	userID, targetID, ok := callerAndIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.ReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.AbortWithError(c, bindError(err))
+		return
+	}
+
+	resp, err := h.safetyService.Report(c.Request.Context(), userID, targetID, &req)
+	if err != nil {
+		renderSafetyError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, resp)
+}
+
+func renderSafetyError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, postgres.ErrSafetyTargetNotFound), errors.Is(err, postgres.ErrBlockNotFound):
+		middleware.AbortWithError(c, apperror.NotFound(err.Error()))
+	case errors.Is(err, postgres.ErrAlreadyReported):
+		middleware.AbortWithError(c, apperror.Conflict(err.Error()))
+	case errors.Is(err, safety.ErrSelfTarget):
+		middleware.AbortWithError(c, apperror.Validation(err.Error(), nil))
+	default:
+		middleware.AbortWithError(c, apperror.Internal("safety request failed", err))
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/alexcolls/findme/internal/api/middleware"
+	"github.com/alexcolls/findme/internal/service/realtime"
+	"github.com/alexcolls/findme/pkg/apperror"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// bearerSubprotocol lets browsers, which cannot set headers on WebSocket
+// requests, pass the token as a subprotocol: ["bearer", "<access_token>"].
+const bearerSubprotocol = "bearer"
+
+type WebSocketHandler struct {
+	auth     *middleware.AuthMiddleware
+	hub      *realtime.Hub
+	handle   realtime.MessageHandler
+	cfg      realtime.Config
+	upgrader websocket.Upgrader
+}
+
+func NewWebSocketHandler(auth *middleware.AuthMiddleware, hub *realtime.Hub, handle realtime.MessageHandler, cfg realtime.Config) *WebSocketHandler {
+	return &WebSocketHandler{
+		auth:   auth,
+		hub:    hub,
+		handle: handle,
+		cfg:    cfg,
+		upgrader: websocket.Upgrader{
+			Subprotocols: []string{bearerSubprotocol},
+			// Connections authenticate with a token rather than cookies, so
+			// another site cannot ride on a user's session.
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// Connect upgrades an authenticated request to a WebSocket and serves it
+// until the client disconnects, the access token expires or the user's
+// sessions are revoked.
+func (h *WebSocketHandler) Connect(c *gin.Context) {
+	token := websocketToken(c.Request)
+	if token == "" {
+		middleware.AbortWithError(c, apperror.Unauthorized("access token required"))
+		return
+	}
+
+	claims, err := h.auth.Authenticate(c.Request.Context(), token)
+	if err != nil {
+		middleware.AbortWithError(c, err)
+		return
+	}
+
+	// On failure the upgrader has already replied with an HTTP error
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+
+	realtime.NewClient(h.hub, conn, claims.UserID, claims.ExpiresAt.Time, h.cfg).Run(c.Request.Context(), h.handle)
+}
+
+// Stats reports how many users and connections are online, across all
+// instances and on this one.
+func (h *WebSocketHandler) Stats(c *gin.Context) {
+	stats, err := h.hub.ClusterStats(c.Request.Context())
+	if err != nil {
+		middleware.AbortWithError(c, apperror.Internal("failed to load connection counts", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// websocketToken reads the access token from the token query parameter or
+// from the subprotocol following "bearer".
+func websocketToken(r *http.Request) string {
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token
+	}
+
+	protocols := websocket.Subprotocols(r)
+	for i, p := range protocols {
+		if strings.EqualFold(p, bearerSubprotocol) && i+1 < len(protocols) {
+			return protocols[i+1]
+		}
+	}
+	return ""
+}
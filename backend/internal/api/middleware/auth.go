@@ -1,54 +1,122 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/repository/redis"
+	"github.com/alexcolls/findme/pkg/apperror"
 	"github.com/alexcolls/findme/pkg/jwt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+// Token problems are UNAUTHORIZED; Authenticate reports a failed session
+// lookup as an internal error instead, so clients do not sign users out.
+var (
+	ErrInvalidToken     = apperror.Unauthorized("invalid or expired token")
+	ErrInvalidTokenType = apperror.Unauthorized("invalid token type")
+	ErrSessionRevoked   = apperror.Unauthorized("session has been revoked")
+)
+
 type AuthMiddleware struct {
 	jwtManager *jwt.JWTManager
+	sessions   redis.SessionRepository
 }
 
-func NewAuthMiddleware(jwtManager *jwt.JWTManager) *AuthMiddleware {
-	return &AuthMiddleware{jwtManager: jwtManager}
+// NewAuthMiddleware creates the auth middleware. When sessions is non-nil,
+// access tokens whose version predates a forced logout are rejected.
+func NewAuthMiddleware(jwtManager *jwt.JWTManager, sessions redis.SessionRepository) *AuthMiddleware {
+	return &AuthMiddleware{
+		jwtManager: jwtManager,
+		sessions:   sessions,
+	}
 }
 
 func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "authorization header required"})
-			c.Abort()
+			AbortWithError(c, apperror.Unauthorized("authorization header required"))
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid authorization header format"})
-			c.Abort()
+			AbortWithError(c, apperror.Unauthorized("invalid authorization header format"))
 			return
 		}
 
-		token := parts[1]
-		claims, err := m.jwtManager.ValidateToken(token)
+		claims, err := m.Authenticate(c.Request.Context(), parts[1])
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired token"})
-			c.Abort()
+			AbortWithError(c, err)
 			return
 		}
 
-		if claims.Type != "access" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token type"})
-			c.Abort()
-			return
+		role := claims.Role
+		if role == "" {
+			role = models.RoleUser
 		}
 
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
+		c.Set("user_role", role)
+		c.Next()
+	}
+}
+
+// Authenticate validates an access token and checks it has not been revoked.
+// It backs RequireAuth and connections that cannot send headers, such as
+// WebSockets from browsers.
+func (m *AuthMiddleware) Authenticate(ctx context.Context, token string) (*jwt.Claims, error) {
+	claims, err := m.jwtManager.ValidateToken(token)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.Type != "access" {
+		return nil, ErrInvalidTokenType
+	}
+
+	if m.sessions != nil {
+		version, err := m.sessions.GetTokenVersion(ctx, claims.UserID)
+		if errors.Is(err, postgres.ErrUserNotFound) {
+			return nil, ErrSessionRevoked
+		}
+		if err != nil {
+			return nil, apperror.Internal("failed to check session", err)
+		}
+		if claims.TokenVersion != version {
+			return nil, ErrSessionRevoked
+		}
+	}
+	return claims, nil
+}
+
+// RequireRole allows the request through only if the authenticated user has
+// one of the given roles. It must run after RequireAuth.
+func (m *AuthMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
+	return func(c *gin.Context) {
+		role, err := GetUserRoleFromContext(c)
+		if err != nil {
+			AbortWithError(c, apperror.Unauthorized("unauthorized"))
+			return
+		}
+
+		if !allowed[role] {
+			AbortWithError(c, apperror.Forbidden("insufficient permissions"))
+			return
+		}
+
 		c.Next()
 	}
 }
@@ -68,3 +136,11 @@ func GetUserEmailFromContext(c *gin.Context) (string, error) {
 	}
 	return email.(string), nil
 }
+
+func GetUserRoleFromContext(c *gin.Context) (string, error) {
+	role, exists := c.Get("user_role")
+	if !exists {
+		return "", http.ErrNoCookie
+	}
+	return role.(string), nil
+}
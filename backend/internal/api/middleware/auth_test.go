@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/repository/redis"
+	"github.com/alexcolls/findme/pkg/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtManager := jwt.NewJWTManager("test-secret", 15, 7)
+	m := NewAuthMiddleware(jwtManager, nil)
+
+	router := gin.New()
+	router.GET("/admin", m.RequireAuth(), m.RequireRole(models.RoleAdmin, models.RoleSupport), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name      string
+		role      string
+		tokenType string
+		want      int
+	}{
+		{"admin allowed", models.RoleAdmin, "access", http.StatusOK},
+		{"support allowed", models.RoleSupport, "access", http.StatusOK},
+		{"moderator forbidden", models.RoleModerator, "access", http.StatusForbidden},
+		{"user forbidden", models.RoleUser, "access", http.StatusForbidden},
+		{"missing role treated as user", "", "access", http.StatusForbidden},
+		{"refresh token rejected", models.RoleAdmin, "refresh", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generate := jwtManager.GenerateAccessToken
+			if tt.tokenType == "refresh" {
+				generate = jwtManager.GenerateRefreshToken
+			}
+			token, err := generate(uuid.New(), "staff@findme.app", tt.role, 0)
+			if err != nil {
+				t.Fatalf("generate token: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+type stubSessions struct {
+	redis.SessionRepository
+	version int
+	err     error
+}
+
+func (s stubSessions) GetTokenVersion(ctx context.Context, userID uuid.UUID) (int, error) {
+	return s.version, s.err
+}
+
+func TestRequireAuthSessionLookup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtManager := jwt.NewJWTManager("test-secret", 15, 7)
+	token, err := jwtManager.GenerateAccessToken(uuid.New(), "user@findme.app", models.RoleUser, 1)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		sessions stubSessions
+		want     int
+	}{
+		{"current version", stubSessions{version: 1}, http.StatusOK},
+		{"forced logout", stubSessions{version: 2}, http.StatusUnauthorized},
+		{"deleted user", stubSessions{err: postgres.ErrUserNotFound}, http.StatusUnauthorized},
+		{"lookup failure", stubSessions{err: errors.New("redis: connection refused")}, http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/me", NewAuthMiddleware(jwtManager, tt.sessions).RequireAuth(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"github.com/alexcolls/findme/pkg/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+// Errors renders the last error a handler or middleware recorded with
+// c.Error as {"success": false, "error": {"code", "message", "details"}}.
+// Errors that are not an *apperror.Error become INTERNAL_ERROR, with the
+// cause logged rather than shown.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := apperror.From(c.Errors.Last().Err)
+		if err.Code == apperror.CodeInternal {
+			slog.ErrorContext(c.Request.Context(), "Request failed", "path", c.Request.URL.Path, "error", err)
+		}
+		c.JSON(err.Code.Status(), err.Response())
+	}
+}
+
+// AbortWithError records err for Errors to render, sets the status for its
+// code and skips the remaining handlers.
+func AbortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Status(apperror.From(err).Code.Status())
+	c.Abort()
+}
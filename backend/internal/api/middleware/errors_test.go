@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alexcolls/findme/pkg/apperror"
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Errors())
+	router.GET("/typed", func(c *gin.Context) {
+		AbortWithError(c, apperror.Validation("invalid request", map[string]string{"email": "is required"}))
+	})
+	router.GET("/untyped", func(c *gin.Context) {
+		AbortWithError(c, errors.New("pq: connection refused"))
+	})
+	router.GET("/written", func(c *gin.Context) {
+		_ = c.Error(errors.New("ignored"))
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+	router.NoRoute(func(c *gin.Context) {
+		AbortWithError(c, apperror.NotFound("route not found"))
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		status  int
+		code    apperror.Code
+		message string
+	}{
+		{"typed error", "/typed", http.StatusUnprocessableEntity, apperror.CodeValidation, "invalid request"},
+		{"untyped error is hidden", "/untyped", http.StatusInternalServerError, apperror.CodeInternal, "internal server error"},
+		{"unknown route", "/nope", http.StatusNotFound, apperror.CodeNotFound, "route not found"},
+		{"response already written", "/written", http.StatusOK, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if strings.Contains(rec.Body.String(), "pq:") {
+				t.Errorf("body leaks the cause: %s", rec.Body.String())
+			}
+			if tt.code == "" {
+				return
+			}
+			var body apperror.Body
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decode body %q: %v", rec.Body.String(), err)
+			}
+			if body.Success || body.Error.Code != tt.code || body.Error.Message != tt.message {
+				t.Errorf("body = %+v, want code %s and message %q", body, tt.code, tt.message)
+			}
+		})
+	}
+}
@@ -1,34 +1,101 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
 	"time"
 
+	"github.com/alexcolls/findme/pkg/apperror"
+	"github.com/alexcolls/findme/pkg/logger"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func Logger() gin.HandlerFunc {
+// RequestIDHeader carries the ID that ties together the logs of one request.
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID limits IDs taken from clients to what is safe to log.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID reuses the caller's X-Request-ID, such as one set by a load
+// balancer, or generates one. The ID is echoed in the response, set on the
+// request's span and stored in the request context, so logs written further
+// down carry it.
+func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		start := time.Now()
-		path := c.Request.URL.Path
-		raw := c.Request.URL.RawQuery
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = uuid.NewString()
+		}
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("http.request.header.x-request-id", id))
+		c.Request = c.Request.WithContext(logger.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
 
+// Logger logs each request once it completes: server errors at error level,
+// client errors at warn and the rest at info. Sensitive query parameters are
+// redacted, and headers are only logged at debug level.
+func Logger(log *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
 		c.Next()
 
-		end := time.Now()
-		latency := end.Sub(start)
+		ctx := c.Request.Context()
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		if !log.Enabled(ctx, level) {
+			return
+		}
 
-		if raw != "" {
-			path = path + "?" + raw
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		}
+		if query := logger.RedactQuery(c.Request.URL.RawQuery); query != "" {
+			attrs = append(attrs, slog.String("query", query))
+		}
+		if userID, err := GetUserIDFromContext(c); err == nil {
+			attrs = append(attrs, slog.String("user_id", userID.String()))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
 		}
+		if log.Enabled(ctx, slog.LevelDebug) {
+			attrs = append(attrs, slog.Any("headers", logger.RedactHeaders(c.Request.Header)))
+		}
+		log.LogAttrs(ctx, level, "HTTP request", attrs...)
+	}
+}
 
-		log.Printf("[GIN] %s | %3d | %13v | %15s | %-7s %s",
-			end.Format("2006/01/02 - 15:04:05"),
-			c.Writer.Status(),
-			latency,
-			c.ClientIP(),
-			c.Request.Method,
-			path,
+// Recovery turns a panic in a handler into a 500 and logs it with the stack
+// and request ID.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(nil, func(c *gin.Context, recovered any) {
+		slog.ErrorContext(c.Request.Context(), "Handler panicked",
+			"panic", recovered,
+			"path", c.Request.URL.Path,
+			"stack", string(debug.Stack()),
 		)
-	}
+		err := apperror.Internal("internal server error", nil)
+		c.AbortWithStatusJSON(err.Code.Status(), err.Response())
+	})
 }
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alexcolls/findme/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestLogging(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.Config{Level: "info", Format: "json"})
+
+	router := gin.New()
+	router.Use(RequestID(), Logger(log), Recovery())
+	router.GET("/auth/verify-email", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		requestID  string
+		wantStatus int
+		wantLevel  string
+		wantID     string
+	}{
+		{"client request id", "/auth/verify-email?token=secret", "lb-123", http.StatusOK, "INFO", "lb-123"},
+		{"invalid request id", "/auth/verify-email?token=secret", "bad id\n", http.StatusOK, "INFO", ""},
+		{"panic", "/panic", "", http.StatusInternalServerError, "ERROR", ""},
+		{"unknown route", "/missing", "", http.StatusNotFound, "WARN", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			id := rec.Header().Get(RequestIDHeader)
+			if tt.wantID != "" && id != tt.wantID {
+				t.Errorf("%s = %q, want %q", RequestIDHeader, id, tt.wantID)
+			}
+			if tt.wantID == "" && (id == "" || id == tt.requestID) {
+				t.Errorf("%s = %q, want a generated ID", RequestIDHeader, id)
+			}
+			if strings.Contains(buf.String(), "secret") {
+				t.Errorf("log leaks the token: %s", buf.String())
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			var record map[string]any
+			if err := json.Unmarshal([]byte(lines[len(lines)-1]), &record); err != nil {
+				t.Fatalf("invalid log record %q: %v", buf.String(), err)
+			}
+			if record["msg"] != "HTTP request" || record["level"] != tt.wantLevel || record["request_id"] != id {
+				t.Errorf("record = %v, want HTTP request at %s with request_id %s", record, tt.wantLevel, id)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/alexcolls/findme/pkg/apperror"
+	"github.com/alexcolls/findme/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitPolicy limits one group of routes. Each policy counts requests
+// separately under its name.
+type RateLimitPolicy struct {
+	Name  string
+	Limit ratelimit.Limit
+}
+
+type RateLimiter struct {
+	limiter ratelimit.Limiter
+	enabled bool
+}
+
+func NewRateLimiter(limiter ratelimit.Limiter, enabled bool) *RateLimiter {
+	return &RateLimiter{limiter: limiter, enabled: enabled}
+}
+
+// Limit enforces a policy per user on routes behind RequireAuth and per
+// client IP elsewhere. Responses carry RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset and RateLimit-Policy; a request over the limit gets 429
+// with Retry-After. If the limiter fails the request is let through.
+func (m *RateLimiter) Limit(policy RateLimitPolicy) gin.HandlerFunc {
+	policyHeader := fmt.Sprintf("%d;w=%d", policy.Limit.Requests, ratelimit.Seconds(policy.Limit.Period))
+
+	return func(c *gin.Context) {
+		if !m.enabled || policy.Limit.Requests <= 0 {
+			c.Next()
+			return
+		}
+
+		key := "ratelimit:" + policy.Name + ":ip:" + c.ClientIP()
+		if userID, err := GetUserIDFromContext(c); err == nil {
+			key = "ratelimit:" + policy.Name + ":user:" + userID.String()
+		}
+
+		result, err := m.limiter.Allow(c.Request.Context(), key, policy.Limit)
+		if err != nil {
+			slog.WarnContext(c.Request.Context(), "Rate limit check failed", "policy", policy.Name, "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(ratelimit.Seconds(result.ResetAfter)))
+		c.Header("RateLimit-Policy", policyHeader)
+
+		if !result.Allowed {
+			retryAfter := ratelimit.Seconds(result.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			AbortWithError(c, apperror.RateLimited("rate limit exceeded", retryAfter))
+			return
+		}
+		c.Next()
+	}
+}
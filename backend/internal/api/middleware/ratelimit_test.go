@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alexcolls/findme/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := NewRateLimiter(ratelimit.NewMemoryLimiter(), true)
+	policy := RateLimitPolicy{Name: "test", Limit: ratelimit.Limit{Requests: 2, Period: time.Minute}}
+
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		if id := c.GetHeader("X-Test-User"); id != "" {
+			c.Set("user_id", uuid.MustParse(id))
+		}
+	}, m.Limit(policy), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	alice, bob := uuid.NewString(), uuid.NewString()
+	tests := []struct {
+		name          string
+		ip            string
+		user          string
+		want          int
+		wantRemaining string
+	}{
+		{"first from ip", "10.0.0.1", "", http.StatusOK, "1"},
+		{"second from ip", "10.0.0.1", "", http.StatusOK, "0"},
+		{"third from ip", "10.0.0.1", "", http.StatusTooManyRequests, "0"},
+		{"other ip", "10.0.0.2", "", http.StatusOK, "1"},
+		{"user behind the limited ip", "10.0.0.1", alice, http.StatusOK, "1"},
+		{"user again", "10.0.0.1", alice, http.StatusOK, "0"},
+		{"user over the limit", "10.0.0.3", alice, http.StatusTooManyRequests, "0"},
+		{"other user", "10.0.0.1", bob, http.StatusOK, "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.ip + ":1234"
+			if tt.user != "" {
+				req.Header.Set("X-Test-User", tt.user)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if got := rec.Header().Get("RateLimit-Remaining"); got != tt.wantRemaining {
+				t.Errorf("RateLimit-Remaining = %q, want %q", got, tt.wantRemaining)
+			}
+			if got := rec.Header().Get("RateLimit-Policy"); got != "2;w=60" {
+				t.Errorf("RateLimit-Policy = %q, want 2;w=60", got)
+			}
+			if retry := rec.Header().Get("Retry-After"); (retry != "") != (tt.want == http.StatusTooManyRequests) {
+				t.Errorf("Retry-After = %q with status %d", retry, rec.Code)
+			}
+		})
+	}
+}
+
+func TestRateLimitForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	policy := RateLimitPolicy{Name: "auth", Limit: ratelimit.Limit{Requests: 1, Period: time.Hour}}
+
+	tests := []struct {
+		name    string
+		proxies []string
+		want    int
+	}{
+		{"forged header from a client", nil, http.StatusTooManyRequests},
+		{"header from a trusted proxy", []string{"10.0.0.1"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			if err := router.SetTrustedProxies(tt.proxies); err != nil {
+				t.Fatal(err)
+			}
+			router.POST("/login", NewRateLimiter(ratelimit.NewMemoryLimiter(), true).Limit(policy), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			var rec *httptest.ResponseRecorder
+			for _, forwarded := range []string{"203.0.113.1", "203.0.113.2"} {
+				req := httptest.NewRequest(http.MethodPost, "/login", nil)
+				req.RemoteAddr = "10.0.0.1:1234"
+				req.Header.Set("X-Forwarded-For", forwarded)
+				rec = httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+			}
+			if rec.Code != tt.want {
+				t.Errorf("second request status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
@@ -4,14 +4,15 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
 	// Server
-	ServerHost string
-	ServerPort string
+	ServerHost  string
+	ServerPort  string
 	Environment string
 
 	// Database
@@ -22,6 +23,9 @@ type Config struct {
 	DBName     string
 	DBSSL      string
 
+	// Apply pending migrations before serving
+	MigrateOnStartup bool
+
 	// Redis
 	RedisHost     string
 	RedisPort     string
@@ -29,20 +33,24 @@ type Config struct {
 	RedisDB       int
 
 	// Qdrant
-	QdrantHost string
-	QdrantPort string
-	QdrantAPIKey string
+	QdrantHost       string
+	QdrantPort       string
+	QdrantGRPCPort   int
+	QdrantAPIKey     string
+	QdrantCollection string
+	QdrantVectorSize int
 
 	// JWT
-	JWTSecret              string
-	JWTAccessTokenMinutes  int
-	JWTRefreshTokenDays    int
+	JWTSecret             string
+	JWTAccessTokenMinutes int
+	JWTRefreshTokenDays   int
 
 	// AWS/Storage
 	AWSRegion          string
 	AWSAccessKeyID     string
 	AWSSecretAccessKey string
 	S3BucketName       string
+	S3Endpoint         string
 
 	// Email
 	SMTPHost     string
@@ -52,20 +60,114 @@ type Config struct {
 	SMTPFrom     string
 
 	// WebRTC
-	TwilioAccountSID string
-	TwilioAuthToken  string
-	TwilioAPIKey     string
-	TwilioAPISecret  string
+	ICEProvider             string
+	WebRTCSTUNServers       []string
+	WebRTCTURNServers       []string
+	WebRTCTURNUsername      string
+	WebRTCTURNCredential    string
+	TURNSharedSecret        string
+	ICECredentialTTLSeconds int
+	TwilioAccountSID        string
+	TwilioAuthToken         string
+	TwilioAPIKey            string
+	TwilioAPISecret         string
+
+	// Push notifications
+	PushNotificationsEnabled bool
+	APNSKeyID                string
+	APNSTeamID               string
+	APNSBundleID             string
+	APNSKeyPath              string
+	APNSProduction           bool
+	FCMCredentialsFile       string
 
 	// OpenAI
-	OpenAIAPIKey string
-	OpenAIModel  string
+	OpenAIAPIKey         string
+	OpenAIModel          string
+	OpenAIEmbeddingModel string
+
+	// Embeddings
+	EmbeddingProvider            string
+	EmbeddingBatchSize           int
+	EmbeddingSyncEnabled         bool
+	EmbeddingSyncIntervalSeconds int
 
 	// App Settings
-	MaxUploadSize    int64
-	AllowedOrigins   []string
-	RateLimitPerMin  int
+	MaxUploadSize           int64
+	AllowedOrigins          []string
+	RateLimitEnabled        bool
+	RateLimitPerMin         int
+	RateLimitAuthPerHour    int
 	ProfileVideoMaxDuration int
+	// Proxies whose X-Forwarded-For is believed when finding the client IP
+	TrustedProxies []string
+
+	// Admin
+	AdminEmails            []string
+	ModerationClaimMinutes int
+
+	// Reports
+	ReportAutoSuspendThreshold       int
+	ReportAutoSuspendSevereThreshold int
+	ReportAutoSuspendWindowHours     int
+
+	// Matching
+	MatchingEnabled             bool
+	MatchingWeeklyCount         int
+	MatchingMinScore            float64
+	MatchingExcludePreviousDays int
+	MatchingIntervalMinutes     int
+
+	// Match score weights, relative to each other
+	MatchingWeightSharedInterests     float64
+	MatchingWeightDistance            float64
+	MatchingWeightAgeFit              float64
+	MatchingWeightEmbeddingSimilarity float64
+	MatchingWeightRecency             float64
+	MatchingFairnessWeight            float64
+
+	// Match expiry
+	MatchSweeperEnabled         bool
+	MatchSweeperIntervalSeconds int
+	MatchSweeperBatchSize       int
+	MatchReminderHours          int
+
+	// Video calls
+	CallRingTimeoutSeconds     int
+	CallMaxDurationMinutes     int
+	CallSweeperIntervalSeconds int
+
+	// Chat
+	ChatMaxMessageLength int
+
+	// Content safety
+	ContentFilterEnabled      bool
+	ContentRulesFile          string
+	ContentRulesReloadSeconds int
+
+	// WebSocket
+	WSMaxMessageBytes     int
+	WSSendBufferSize      int
+	WSPingIntervalSeconds int
+
+	// Logging
+	LogLevel    string
+	LogFormat   string
+	LogRequests bool
+
+	// Metrics
+	PrometheusEnabled bool
+	PrometheusPort    string
+
+	// Tracing
+	TracingExporter    string
+	TracingEndpoint    string
+	TracingInsecure    bool
+	TracingSampleRatio float64
+
+	// Health
+	ReadinessCacheSeconds int
+	ShutdownDrainSeconds  int
 }
 
 func Load() (*Config, error) {
@@ -86,6 +188,8 @@ func Load() (*Config, error) {
 		DBName:     getEnv("DB_NAME", "findme_db"),
 		DBSSL:      getEnv("DB_SSL", "disable"),
 
+		MigrateOnStartup: getEnvBool("MIGRATE_ON_STARTUP", false),
+
 		// Redis
 		RedisHost:     getEnv("REDIS_HOST", "localhost"),
 		RedisPort:     getEnv("REDIS_PORT", "6379"),
@@ -93,9 +197,12 @@ func Load() (*Config, error) {
 		RedisDB:       getEnvInt("REDIS_DB", 0),
 
 		// Qdrant
-		QdrantHost:   getEnv("QDRANT_HOST", "localhost"),
-		QdrantPort:   getEnv("QDRANT_PORT", "6333"),
-		QdrantAPIKey: getEnv("QDRANT_API_KEY", ""),
+		QdrantHost:       getEnv("QDRANT_HOST", "localhost"),
+		QdrantPort:       getEnv("QDRANT_PORT", "6333"),
+		QdrantGRPCPort:   getEnvInt("QDRANT_GRPC_PORT", 6334),
+		QdrantAPIKey:     getEnv("QDRANT_API_KEY", ""),
+		QdrantCollection: getEnv("QDRANT_COLLECTION_PROFILES", "profile_embeddings"),
+		QdrantVectorSize: getEnvInt("QDRANT_VECTOR_SIZE", 512),
 
 		// JWT
 		JWTSecret:             getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
@@ -107,6 +214,7 @@ func Load() (*Config, error) {
 		AWSAccessKeyID:     getEnv("AWS_ACCESS_KEY_ID", ""),
 		AWSSecretAccessKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
 		S3BucketName:       getEnv("S3_BUCKET_NAME", ""),
+		S3Endpoint:         getEnv("S3_ENDPOINT", ""),
 
 		// Email
 		SMTPHost:     getEnv("SMTP_HOST", ""),
@@ -116,19 +224,112 @@ func Load() (*Config, error) {
 		SMTPFrom:     getEnv("SMTP_FROM", "noreply@findme.app"),
 
 		// WebRTC
-		TwilioAccountSID: getEnv("TWILIO_ACCOUNT_SID", ""),
-		TwilioAuthToken:  getEnv("TWILIO_AUTH_TOKEN", ""),
-		TwilioAPIKey:     getEnv("TWILIO_API_KEY", ""),
-		TwilioAPISecret:  getEnv("TWILIO_API_SECRET", ""),
+		ICEProvider:             getEnv("ICE_PROVIDER", "static"),
+		WebRTCSTUNServers:       getEnvSlice("WEBRTC_STUN_SERVERS", []string{"stun:stun.l.google.com:19302"}),
+		WebRTCTURNServers:       getEnvSlice("WEBRTC_TURN_SERVERS", []string{}),
+		WebRTCTURNUsername:      getEnv("WEBRTC_TURN_USERNAME", ""),
+		WebRTCTURNCredential:    getEnv("WEBRTC_TURN_CREDENTIAL", ""),
+		TURNSharedSecret:        getEnv("TURN_SHARED_SECRET", ""),
+		ICECredentialTTLSeconds: getEnvInt("ICE_CREDENTIAL_TTL_SECONDS", 10800),
+		TwilioAccountSID:        getEnv("TWILIO_ACCOUNT_SID", ""),
+		TwilioAuthToken:         getEnv("TWILIO_AUTH_TOKEN", ""),
+		TwilioAPIKey:            getEnv("TWILIO_API_KEY", ""),
+		TwilioAPISecret:         getEnv("TWILIO_API_SECRET", ""),
+
+		// Push notifications
+		PushNotificationsEnabled: getEnvBool("PUSH_NOTIFICATIONS_ENABLED", false),
+		APNSKeyID:                getEnv("APNS_KEY_ID", ""),
+		APNSTeamID:               getEnv("APNS_TEAM_ID", ""),
+		APNSBundleID:             getEnv("APNS_BUNDLE_ID", ""),
+		APNSKeyPath:              getEnv("APNS_KEY_PATH", ""),
+		APNSProduction:           getEnvBool("APNS_PRODUCTION", false),
+		FCMCredentialsFile:       getEnv("FCM_CREDENTIALS_FILE", ""),
 
 		// OpenAI
-		OpenAIAPIKey: getEnv("OPENAI_API_KEY", ""),
-		OpenAIModel:  getEnv("OPENAI_MODEL", "gpt-4"),
+		OpenAIAPIKey:         getEnv("OPENAI_API_KEY", ""),
+		OpenAIModel:          getEnv("OPENAI_MODEL", "gpt-4"),
+		OpenAIEmbeddingModel: getEnv("OPENAI_EMBEDDING_MODEL", "text-embedding-3-small"),
+
+		// Embeddings
+		EmbeddingProvider:            getEnv("EMBEDDING_PROVIDER", "local"),
+		EmbeddingBatchSize:           getEnvInt("EMBEDDING_BATCH_SIZE", 100),
+		EmbeddingSyncEnabled:         getEnvBool("EMBEDDING_SYNC_ENABLED", true),
+		EmbeddingSyncIntervalSeconds: getEnvInt("EMBEDDING_SYNC_INTERVAL_SECONDS", 5),
 
 		// App Settings
 		MaxUploadSize:           getEnvInt64("MAX_UPLOAD_SIZE", 100*1024*1024), // 100MB
+		RateLimitEnabled:        getEnvBool("RATE_LIMIT_ENABLED", true),
 		RateLimitPerMin:         getEnvInt("RATE_LIMIT_PER_MIN", 60),
+		RateLimitAuthPerHour:    getEnvInt("RATE_LIMIT_AUTH", 10),
 		ProfileVideoMaxDuration: getEnvInt("PROFILE_VIDEO_MAX_DURATION", 30),
+		TrustedProxies:          getEnvSlice("TRUSTED_PROXIES", nil),
+
+		// Admin
+		AdminEmails:            getEnvSlice("ADMIN_EMAILS", nil),
+		ModerationClaimMinutes: getEnvInt("MODERATION_CLAIM_MINUTES", 15),
+
+		// Reports
+		ReportAutoSuspendThreshold:       getEnvInt("REPORT_AUTO_SUSPEND_THRESHOLD", 3),
+		ReportAutoSuspendSevereThreshold: getEnvInt("REPORT_AUTO_SUSPEND_SEVERE_THRESHOLD", 2),
+		ReportAutoSuspendWindowHours:     getEnvInt("REPORT_AUTO_SUSPEND_WINDOW_HOURS", 72),
+
+		// Matching
+		MatchingEnabled:             getEnvBool("FEATURE_AI_MATCHING_ENABLED", true),
+		MatchingWeeklyCount:         getEnvInt("MATCHING_WEEKLY_COUNT", 4),
+		MatchingMinScore:            getEnvFloat("MATCHING_MIN_SCORE", 0.6),
+		MatchingExcludePreviousDays: getEnvInt("MATCHING_EXCLUDE_PREVIOUS_DAYS", 90),
+		MatchingIntervalMinutes:     getEnvInt("MATCHING_INTERVAL_MINUTES", 60),
+
+		// Match score weights
+		MatchingWeightSharedInterests:     getEnvFloat("MATCHING_WEIGHT_SHARED_INTERESTS", 0.2),
+		MatchingWeightDistance:            getEnvFloat("MATCHING_WEIGHT_DISTANCE", 0.15),
+		MatchingWeightAgeFit:              getEnvFloat("MATCHING_WEIGHT_AGE_FIT", 0.2),
+		MatchingWeightEmbeddingSimilarity: getEnvFloat("MATCHING_WEIGHT_EMBEDDING_SIMILARITY", 0.3),
+		MatchingWeightRecency:             getEnvFloat("MATCHING_WEIGHT_RECENCY", 0.15),
+		MatchingFairnessWeight:            getEnvFloat("MATCHING_FAIRNESS_WEIGHT", 0.3),
+
+		// Match expiry
+		MatchSweeperEnabled:         getEnvBool("MATCH_SWEEPER_ENABLED", true),
+		MatchSweeperIntervalSeconds: getEnvInt("MATCH_SWEEPER_INTERVAL_SECONDS", 60),
+		MatchSweeperBatchSize:       getEnvInt("MATCH_SWEEPER_BATCH_SIZE", 500),
+		MatchReminderHours:          getEnvInt("MATCH_REMINDER_HOURS", 24),
+
+		// Video calls
+		CallRingTimeoutSeconds:     getEnvInt("CALL_RING_TIMEOUT_SECONDS", 30),
+		CallMaxDurationMinutes:     getEnvInt("CALL_MAX_DURATION_MINUTES", 120),
+		CallSweeperIntervalSeconds: getEnvInt("CALL_SWEEPER_INTERVAL_SECONDS", 5),
+
+		// Chat
+		ChatMaxMessageLength: getEnvInt("CHAT_MAX_MESSAGE_LENGTH", 2000),
+
+		// Content safety
+		ContentFilterEnabled:      getEnvBool("CONTENT_FILTER_ENABLED", true),
+		ContentRulesFile:          getEnv("CONTENT_RULES_FILE", ""),
+		ContentRulesReloadSeconds: getEnvInt("CONTENT_RULES_RELOAD_SECONDS", 30),
+
+		// WebSocket
+		WSMaxMessageBytes:     getEnvInt("WS_MAX_MESSAGE_BYTES", 64*1024),
+		WSSendBufferSize:      getEnvInt("WS_SEND_BUFFER_SIZE", 64),
+		WSPingIntervalSeconds: getEnvInt("WS_PING_INTERVAL_SECONDS", 25),
+
+		// Logging
+		LogLevel:    getEnv("LOG_LEVEL", "info"),
+		LogFormat:   getEnv("LOG_FORMAT", "json"),
+		LogRequests: getEnvBool("LOG_REQUESTS", true),
+
+		// Metrics
+		PrometheusEnabled: getEnvBool("PROMETHEUS_ENABLED", true),
+		PrometheusPort:    getEnv("PROMETHEUS_PORT", "9090"),
+
+		// Tracing
+		TracingExporter:    getEnv("TRACING_EXPORTER", "none"),
+		TracingEndpoint:    getEnv("TRACING_OTLP_ENDPOINT", ""),
+		TracingInsecure:    getEnvBool("TRACING_OTLP_INSECURE", false),
+		TracingSampleRatio: getEnvFloat("TRACING_SAMPLE_RATIO", 1.0),
+
+		// Health
+		ReadinessCacheSeconds: getEnvInt("READINESS_CACHE_SECONDS", 2),
+		ShutdownDrainSeconds:  getEnvInt("SHUTDOWN_DRAIN_SECONDS", 5),
 	}
 
 	// Validate required fields
@@ -143,6 +344,10 @@ func (c *Config) Validate() error {
 	if c.JWTSecret == "your-secret-key-change-in-production" && c.Environment == "production" {
 		return fmt.Errorf("JWT_SECRET must be set in production")
 	}
+	// On the API port /metrics is as public as the API itself
+	if c.PrometheusEnabled && c.PrometheusPort == c.ServerPort && c.Environment != "development" {
+		return fmt.Errorf("PROMETHEUS_PORT can only equal SERVER_PORT in development, where /metrics is served publicly")
+	}
 	return nil
 }
 
@@ -185,3 +390,35 @@ func getEnvInt64(key string, defaultValue int64) int64 {
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolVal, err := strconv.ParseBool(value); err == nil {
+			return boolVal
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatVal, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatVal
+		}
+	}
+	return defaultValue
+}
+
+func getEnvSlice(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
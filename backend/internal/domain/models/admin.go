@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Admin audit actions
+const (
+	AdminActionRoleChanged         = "role_changed"
+	AdminActionSuspended           = "suspended"
+	AdminActionUnsuspended         = "unsuspended"
+	AdminActionForcedLogout        = "forced_logout"
+	AdminActionVerificationChanged = "verification_changed"
+)
+
+type AdminAuditEntry struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	ActorID      *uuid.UUID `json:"actor_id,omitempty" db:"actor_id"`
+	TargetUserID uuid.UUID  `json:"target_user_id" db:"target_user_id"`
+	Action       string     `json:"action" db:"action"`
+	OldValue     *string    `json:"old_value,omitempty" db:"old_value"`
+	NewValue     *string    `json:"new_value,omitempty" db:"new_value"`
+	Reason       *string    `json:"reason,omitempty" db:"reason"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+type UserSearchFilter struct {
+	Query  string
+	Role   string
+	Active *bool
+	Limit  int
+	Offset int
+}
+
+type ChangeRoleRequest struct {
+	Role   string `json:"role" binding:"required,oneof=user moderator admin support"`
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type AdminActionRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+type VerificationOverrideRequest struct {
+	Verified *bool  `json:"verified" binding:"required"`
+	Reason   string `json:"reason" binding:"required,max=500"`
+}
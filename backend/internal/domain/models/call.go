@@ -0,0 +1,165 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Video call statuses
+const (
+	CallStatusInitiated = "initiated"
+	CallStatusRinging   = "ringing"
+	CallStatusActive    = "active"
+	CallStatusEnded     = "ended"
+	CallStatusMissed    = "missed"
+	CallStatusRejected  = "rejected"
+	CallStatusFailed    = "failed"
+)
+
+// Reasons a call ended
+const (
+	CallEndHangup    = "hangup"
+	CallEndCancelled = "cancelled"
+	CallEndTimeout   = "timeout"
+	CallEndFailed    = "failed"
+)
+
+// LiveCallStatuses are the statuses in which a call occupies both
+// participants.
+var LiveCallStatuses = []string{CallStatusInitiated, CallStatusRinging, CallStatusActive}
+
+// callTransitions lists the statuses each status may move to. Ended, missed,
+// rejected and failed are final.
+var callTransitions = map[string][]string{
+	CallStatusInitiated: {CallStatusRinging, CallStatusActive, CallStatusEnded, CallStatusMissed, CallStatusRejected, CallStatusFailed},
+	CallStatusRinging:   {CallStatusActive, CallStatusEnded, CallStatusMissed, CallStatusRejected, CallStatusFailed},
+	CallStatusActive:    {CallStatusEnded, CallStatusFailed},
+}
+
+// CanTransitionCall reports whether a call may move from one status to
+// another.
+func CanTransitionCall(from, to string) bool {
+	for _, next := range callTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+type VideoCall struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	MatchID   uuid.UUID  `json:"match_id" db:"match_id"`
+	CallerID  uuid.UUID  `json:"caller_id" db:"caller_id"`
+	CalleeID  uuid.UUID  `json:"callee_id" db:"callee_id"`
+	SessionID string     `json:"session_id" db:"session_id"`
+	Status    string     `json:"status" db:"status"`
+	StartedAt *time.Time `json:"started_at,omitempty" db:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty" db:"ended_at"`
+	// Duration is in seconds, set when an answered call ends
+	Duration  *int       `json:"duration,omitempty" db:"duration"`
+	EndedBy   *uuid.UUID `json:"ended_by,omitempty" db:"ended_by"`
+	EndReason *string    `json:"end_reason,omitempty" db:"end_reason"`
+	// ICEServers are issued when the call starts and shared by both
+	// participants
+	ICEServers []ICEServer `json:"ice_servers,omitempty" db:"ice_servers"`
+	CreatedAt  time.Time   `json:"created_at" db:"created_at"`
+
+	// Each participant only sees their own review, through Review
+	CallerRating   *int        `json:"-" db:"quality_rating"`
+	CallerFeedback *string     `json:"-" db:"feedback"`
+	CalleeRating   *int        `json:"-" db:"callee_quality_rating"`
+	CalleeFeedback *string     `json:"-" db:"callee_feedback"`
+	Review         *CallReview `json:"review,omitempty" db:"-"`
+}
+
+// IsParticipant reports whether the user is the caller or the callee.
+func (c *VideoCall) IsParticipant(userID uuid.UUID) bool {
+	return c.CallerID == userID || c.CalleeID == userID
+}
+
+// OtherUserID returns the participant of the call that is not userID.
+func (c *VideoCall) OtherUserID(userID uuid.UUID) uuid.UUID {
+	if c.CallerID == userID {
+		return c.CalleeID
+	}
+	return c.CallerID
+}
+
+// ForViewer fills Review with the viewer's own rating and feedback.
+func (c *VideoCall) ForViewer(userID uuid.UUID) *VideoCall {
+	rating, feedback := c.CallerRating, c.CallerFeedback
+	if c.CalleeID == userID {
+		rating, feedback = c.CalleeRating, c.CalleeFeedback
+	}
+	c.Review = nil
+	if rating != nil {
+		c.Review = &CallReview{QualityRating: *rating, Feedback: feedback}
+	}
+	return c
+}
+
+// ICEServer is a STUN or TURN server in the shape of WebRTC's RTCIceServer.
+type ICEServer struct {
+	URLs       []string `json:"urls"`
+	Username   string   `json:"username,omitempty"`
+	Credential string   `json:"credential,omitempty"`
+}
+
+type CallReview struct {
+	QualityRating int     `json:"quality_rating"`
+	Feedback      *string `json:"feedback,omitempty"`
+}
+
+type InitiateCallRequest struct {
+	MatchID uuid.UUID `json:"match_id" binding:"required"`
+}
+
+// EndCallRequest optionally reports that the call failed to connect rather
+// than being hung up.
+type EndCallRequest struct {
+	Failed bool `json:"failed"`
+}
+
+type CallFeedbackRequest struct {
+	QualityRating int    `json:"quality_rating" binding:"required,min=1,max=5"`
+	Feedback      string `json:"feedback" binding:"max=2000"`
+}
+
+// CallHistoryFilter pages through a user's calls, optionally for one match.
+type CallHistoryFilter struct {
+	MatchID *uuid.UUID
+	Limit   int
+	Offset  int
+}
+
+type CallHistory struct {
+	Calls []*VideoCall `json:"calls"`
+	Total int          `json:"total"`
+	// TotalDuration is the summed duration in seconds of all matching calls
+	TotalDuration int `json:"total_duration"`
+	Limit         int `json:"limit"`
+	Offset        int `json:"offset"`
+}
+
+// CallStatusUpdate is pushed to both participants when a call changes state.
+type CallStatusUpdate struct {
+	CallID    uuid.UUID  `json:"call_id"`
+	MatchID   uuid.UUID  `json:"match_id"`
+	Status    string     `json:"status"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Duration  *int       `json:"duration,omitempty"`
+	EndReason *string    `json:"end_reason,omitempty"`
+}
+
+// IncomingCall is pushed to the callee when a call is initiated.
+type IncomingCall struct {
+	CallID   uuid.UUID `json:"call_id"`
+	MatchID  uuid.UUID `json:"match_id"`
+	CallerID uuid.UUID `json:"caller_id"`
+	// ExpiresAt is when the call will be marked missed if not answered
+	ExpiresAt  time.Time   `json:"expires_at"`
+	ICEServers []ICEServer `json:"ice_servers"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// Candidate sources
+const (
+	CandidateSourceVector   = "vector"
+	CandidateSourceFallback = "fallback"
+)
+
+// CandidateQuery pages through the candidates for a user. Candidates scoring
+// below MinScore are dropped.
+type CandidateQuery struct {
+	Limit    int
+	Offset   int
+	MinScore float64
+}
+
+// Candidate is a potential match for a user. Score is the embedding
+// similarity for vector results and the heuristic match score for results
+// from the Postgres fallback.
+type Candidate struct {
+	UserID     uuid.UUID `json:"user_id"`
+	Score      float64   `json:"score"`
+	Gender     string    `json:"gender"`
+	Age        int       `json:"age"`
+	DistanceKm *float64  `json:"distance_km,omitempty"`
+	Source     string    `json:"source"`
+}
+
+// CandidateFilter is the Postgres equivalent of a vector search filter,
+// used when the vector index is unavailable. Zero values disable a filter.
+type CandidateFilter struct {
+	Genders    []string
+	MinAge     int
+	MaxAge     int
+	Latitude   *float64
+	Longitude  *float64
+	RadiusKm   int
+	ExcludeIDs []uuid.UUID
+	Limit      int
+	Offset     int
+}
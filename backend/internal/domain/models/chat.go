@@ -0,0 +1,129 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reasons a conversation was closed
+const (
+	ConversationClosedMatchEnded = "match_ended"
+	ConversationClosedBlocked    = "blocked"
+)
+
+// Message receipt statuses
+const (
+	MessageSent      = "sent"
+	MessageDelivered = "delivered"
+	MessageRead      = "read"
+)
+
+// PushChatMessage is the push category of new chat messages.
+const PushChatMessage = "chat_message"
+
+// Conversation is the chat of a mutual match. It closes when the match ends
+// or either user blocks the other, after which the history stays readable
+// but no messages can be sent.
+type Conversation struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	MatchID       uuid.UUID  `json:"match_id" db:"match_id"`
+	UserID        uuid.UUID  `json:"-" db:"user_id"`
+	MatchedUserID uuid.UUID  `json:"-" db:"matched_user_id"`
+	ClosedAt      *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+	CloseReason   *string    `json:"close_reason,omitempty" db:"close_reason"`
+	LastMessageAt *time.Time `json:"last_message_at,omitempty" db:"last_message_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsParticipant reports whether userID is one of the two users of the
+// conversation.
+func (c *Conversation) IsParticipant(userID uuid.UUID) bool {
+	return c.UserID == userID || c.MatchedUserID == userID
+}
+
+// OtherUserID returns the participant that is not userID.
+func (c *Conversation) OtherUserID(userID uuid.UUID) uuid.UUID {
+	if c.UserID == userID {
+		return c.MatchedUserID
+	}
+	return c.UserID
+}
+
+// ConversationSummary is a conversation in the caller's inbox.
+type ConversationSummary struct {
+	Conversation
+	User        MatchUser `json:"user"`
+	LastMessage *Message  `json:"last_message,omitempty"`
+	UnreadCount int64     `json:"unread_count"`
+}
+
+// Message is a chat message. The body of a deleted message is cleared. A held
+// message waits for moderation and only its sender sees it.
+type Message struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	ConversationID uuid.UUID  `json:"conversation_id" db:"conversation_id"`
+	SenderID       uuid.UUID  `json:"sender_id" db:"sender_id"`
+	Body           string     `json:"body" db:"body"`
+	Status         string     `json:"status" db:"-"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty" db:"delivered_at"`
+	ReadAt         *time.Time `json:"read_at,omitempty" db:"read_at"`
+	Deleted        bool       `json:"deleted,omitempty" db:"-"`
+	Held           bool       `json:"held,omitempty" db:"-"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	// Flags are the content safety categories found in a message the
+	// sender just sent, so the app can warn them
+	Flags []string `json:"flags,omitempty" db:"-"`
+}
+
+type SendMessageRequest struct {
+	Body string `json:"body" binding:"required"`
+	// Locale picks the content filter's word lists; the handler fills it
+	// from Accept-Language
+	Locale string `json:"-"`
+}
+
+type MarkConversationReadRequest struct {
+	// MessageID is the newest message the user has seen; it and every
+	// earlier message from the other user are marked read
+	MessageID uuid.UUID `json:"message_id" binding:"required"`
+}
+
+type MessageFilter struct {
+	Cursor string
+	Limit  int
+}
+
+type MessagePage struct {
+	Messages   []*Message `json:"messages"`
+	NextCursor *string    `json:"next_cursor"`
+}
+
+// MessageReceipt tells a sender that their messages in a conversation up to
+// and including MessageID were delivered or read.
+type MessageReceipt struct {
+	ConversationID uuid.UUID `json:"conversation_id"`
+	MessageID      uuid.UUID `json:"message_id"`
+	Status         string    `json:"status"`
+	At             time.Time `json:"at"`
+}
+
+// MessageDeleted is the data of a message_deleted realtime message.
+type MessageDeleted struct {
+	ConversationID uuid.UUID `json:"conversation_id"`
+	MessageID      uuid.UUID `json:"message_id"`
+}
+
+// ConversationClosed is the data of a conversation_closed realtime message.
+type ConversationClosed struct {
+	ConversationID uuid.UUID `json:"conversation_id"`
+	MatchID        uuid.UUID `json:"match_id"`
+	Reason         string    `json:"reason"`
+}
+
+// TypingIndicator is relayed to the other user of a match while one types.
+type TypingIndicator struct {
+	MatchID uuid.UUID `json:"match_id"`
+	From    uuid.UUID `json:"from"`
+	Typing  bool      `json:"typing"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Embedding outbox operations
+const (
+	EmbeddingOpUpsert = "upsert"
+	EmbeddingOpDelete = "delete"
+)
+
+// EmbeddingOutboxEntry is a pending change to a user's profile embedding.
+type EmbeddingOutboxEntry struct {
+	ID            int64      `json:"id" db:"id"`
+	UserID        uuid.UUID  `json:"user_id" db:"user_id"`
+	Operation     string     `json:"operation" db:"operation"`
+	Attempts      int        `json:"attempts" db:"attempts"`
+	LastError     *string    `json:"last_error,omitempty" db:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" db:"next_attempt_at"`
+	ProcessedAt   *time.Time `json:"processed_at,omitempty" db:"processed_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// EmbeddingProfile is the subset of a user's profile that is embedded and
+// stored as payload in the vector index.
+type EmbeddingProfile struct {
+	UserID      uuid.UUID
+	Gender      string
+	DateOfBirth time.Time
+	Bio         *string
+	Verified    bool
+	// VideoVerified is set when the user has an approved video profile
+	VideoVerified bool
+	Active        bool
+	Latitude      *float64
+	Longitude     *float64
+}
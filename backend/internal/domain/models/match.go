@@ -0,0 +1,217 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Match statuses
+const (
+	MatchStatusPending   = "pending"
+	MatchStatusAccepted  = "accepted"
+	MatchStatusRejected  = "rejected"
+	MatchStatusCompleted = "completed"
+	MatchStatusExpired   = "expired"
+)
+
+// Match actions
+const (
+	MatchActionAccepted = "accepted"
+	MatchActionRejected = "rejected"
+)
+
+// Matching run statuses
+const (
+	MatchingRunRunning   = "running"
+	MatchingRunCompleted = "completed"
+	MatchingRunFailed    = "failed"
+)
+
+type Match struct {
+	ID                uuid.UUID       `json:"id" db:"id"`
+	UserID            uuid.UUID       `json:"user_id" db:"user_id"`
+	MatchedUserID     uuid.UUID       `json:"matched_user_id" db:"matched_user_id"`
+	WeekNumber        int             `json:"week_number" db:"week_number"`
+	Year              int             `json:"year" db:"year"`
+	Status            string          `json:"status" db:"status"`
+	MatchScore        float64         `json:"match_score" db:"match_score"`
+	ScoreBreakdown    *ScoreBreakdown `json:"score_breakdown,omitempty" db:"score_breakdown"`
+	UserAction        *string         `json:"user_action,omitempty" db:"user_action"`
+	MatchedUserAction *string         `json:"matched_user_action,omitempty" db:"matched_user_action"`
+	MutualMatch       bool            `json:"mutual_match" db:"mutual_match"`
+	ExpiresAt         time.Time       `json:"expires_at" db:"expires_at"`
+	MatchedAt         *time.Time      `json:"matched_at,omitempty" db:"matched_at"`
+	CompletedAt       *time.Time      `json:"completed_at,omitempty" db:"completed_at"`
+	EndedBy           *uuid.UUID      `json:"ended_by,omitempty" db:"ended_by"`
+	EndReason         *string         `json:"end_reason,omitempty" db:"end_reason"`
+	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at" db:"updated_at"`
+	DeletedAt         *time.Time      `json:"-" db:"deleted_at"`
+}
+
+// OtherUserID returns the participant of the match that is not userID.
+func (m *Match) OtherUserID(userID uuid.UUID) uuid.UUID {
+	if m.UserID == userID {
+		return m.MatchedUserID
+	}
+	return m.UserID
+}
+
+// ActionOf returns the action taken by the given participant, if any.
+func (m *Match) ActionOf(userID uuid.UUID) *string {
+	if m.UserID == userID {
+		return m.UserAction
+	}
+	return m.MatchedUserAction
+}
+
+// VideoProfileSummary is the public part of a user's verified video.
+type VideoProfileSummary struct {
+	ThumbnailURL *string `json:"thumbnail_url,omitempty"`
+	Duration     int     `json:"duration"`
+}
+
+// MatchUser is the profile of the other participant shown with a match.
+type MatchUser struct {
+	ID           uuid.UUID            `json:"id"`
+	FullName     string               `json:"full_name"`
+	Age          int                  `json:"age"`
+	Bio          *string              `json:"bio,omitempty"`
+	VideoProfile *VideoProfileSummary `json:"video_profile,omitempty"`
+}
+
+// WeeklyMatch is a match from the caller's point of view.
+type WeeklyMatch struct {
+	ID          uuid.UUID `json:"id"`
+	User        MatchUser `json:"user"`
+	MatchScore  float64   `json:"match_score"`
+	Reasons     []string  `json:"reasons"`
+	Status      string    `json:"status"`
+	MyAction    *string   `json:"my_action,omitempty"`
+	MutualMatch bool      `json:"mutual_match"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// ActiveMatch is a mutual match that has not ended yet.
+type ActiveMatch struct {
+	ID           uuid.UUID  `json:"id"`
+	User         MatchUser  `json:"user"`
+	Reasons      []string   `json:"reasons"`
+	MatchedAt    *time.Time `json:"matched_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	CanVideoCall bool       `json:"can_video_call"`
+	CallsCount   int        `json:"calls_count"`
+	LastCallAt   *time.Time `json:"last_call_at,omitempty"`
+}
+
+type MatchActionResponse struct {
+	MatchID     uuid.UUID `json:"match_id"`
+	Status      string    `json:"status"`
+	MutualMatch bool      `json:"mutual_match"`
+	Message     string    `json:"message"`
+}
+
+type EndMatchRequest struct {
+	Reason   string `json:"reason" binding:"required,oneof=not_compatible no_chemistry met_someone safety_concern other"`
+	Feedback string `json:"feedback" binding:"max=2000"`
+}
+
+// ScoreBreakdown explains a match score. Component scores range from 0 to 1.
+// Optional components are nil when the data behind them was missing, in
+// which case their weight was spread over the others.
+type ScoreBreakdown struct {
+	SharedInterests     *float64 `json:"shared_interests,omitempty"`
+	Distance            float64  `json:"distance"`
+	AgeFit              float64  `json:"age_fit"`
+	EmbeddingSimilarity *float64 `json:"embedding_similarity,omitempty"`
+	Recency             float64  `json:"recency"`
+	Total               float64  `json:"total"`
+
+	// Interests lists the interests both users share
+	Interests  []string `json:"interests,omitempty"`
+	DistanceKm *float64 `json:"distance_km,omitempty"`
+}
+
+// maxReasons caps how many reasons are shown for a match.
+const maxReasons = 3
+
+// Reasons turns the strongest components into short sentences for users,
+// most specific first.
+func (b *ScoreBreakdown) Reasons() []string {
+	reasons := []string{}
+	if b == nil {
+		return reasons
+	}
+
+	if len(b.Interests) > 0 {
+		shared := b.Interests
+		if len(shared) > 3 {
+			shared = shared[:3]
+		}
+		reasons = append(reasons, "You both love "+joinWords(shared))
+	}
+	if b.DistanceKm != nil && *b.DistanceKm <= 10 {
+		km := math.Max(1, math.Round(*b.DistanceKm))
+		reasons = append(reasons, fmt.Sprintf("You're only %.0f km apart", km))
+	}
+	if b.EmbeddingSimilarity != nil && *b.EmbeddingSimilarity >= 0.75 {
+		reasons = append(reasons, "Your profiles have a lot in common")
+	}
+	if b.AgeFit >= 0.8 {
+		reasons = append(reasons, "You're right in each other's preferred age range")
+	}
+	if b.Recency >= 1 {
+		reasons = append(reasons, "You've both been active this week")
+	}
+
+	if len(reasons) > maxReasons {
+		reasons = reasons[:maxReasons]
+	}
+	return reasons
+}
+
+// joinWords joins words as "a", "a and b" or "a, b and c".
+func joinWords(words []string) string {
+	if len(words) == 1 {
+		return words[0]
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
+
+// MatchProfile is the subset of a user's profile and preferences the
+// matching engine works with.
+type MatchProfile struct {
+	UserID        uuid.UUID
+	Gender        string
+	DateOfBirth   time.Time
+	Bio           *string
+	Latitude      *float64
+	Longitude     *float64
+	LastLoginAt   *time.Time
+	Interests     []string
+	InterestedIn  []string
+	MinAge        int
+	MaxAge        int
+	MaxDistanceKm int
+}
+
+type MatchingRun struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	WeekNumber     int        `json:"week_number" db:"week_number"`
+	Year           int        `json:"year" db:"year"`
+	Status         string     `json:"status" db:"status"`
+	EligibleUsers  int        `json:"eligible_users" db:"eligible_users"`
+	MatchesCreated int        `json:"matches_created" db:"matches_created"`
+	Error          *string    `json:"error,omitempty" db:"error"`
+	StartedAt      time.Time  `json:"started_at" db:"started_at"`
+	FinishedAt     *time.Time `json:"finished_at,omitempty" db:"finished_at"`
+}
+
+type RunMatchingRequest struct {
+	WeekNumber int `json:"week_number" binding:"omitempty,min=1,max=53"`
+	Year       int `json:"year" binding:"omitempty,min=2025"`
+}
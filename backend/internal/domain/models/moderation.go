@@ -0,0 +1,114 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Moderation item types
+const (
+	ModerationItemVideo   = "video"
+	ModerationItemPhoto   = "photo"
+	ModerationItemBio     = "bio"
+	ModerationItemReport  = "report"
+	ModerationItemMessage = "message"
+)
+
+// Moderation item statuses
+const (
+	ModerationStatusPending  = "pending"
+	ModerationStatusClaimed  = "claimed"
+	ModerationStatusApproved = "approved"
+	ModerationStatusRejected = "rejected"
+)
+
+// Moderation audit actions
+const (
+	ModerationActionEnqueued = "enqueued"
+	ModerationActionClaimed  = "claimed"
+	ModerationActionReleased = "released"
+	ModerationActionApproved = "approved"
+	ModerationActionRejected = "rejected"
+)
+
+// ModerationReasonCodes maps decision reason codes to the human-readable
+// text stored in videos.rejection_reason.
+var ModerationReasonCodes = map[string]string{
+	"approved":       "Content meets community guidelines",
+	"nudity":         "Contains nudity or sexual content",
+	"violence":       "Contains violent or graphic content",
+	"hate_speech":    "Contains hate speech or harassment",
+	"spam":           "Spam or commercial solicitation",
+	"impersonation":  "Impersonates another person",
+	"underage":       "User appears to be underage",
+	"fake_profile":   "Profile does not show a real person",
+	"low_quality":    "Face not clearly visible or poor quality",
+	"personal_info":  "Contains personal contact information",
+	"not_actionable": "Report reviewed, no violation found",
+	"other":          "Violates community guidelines",
+}
+
+type ModerationItem struct {
+	ID                 uuid.UUID              `json:"id" db:"id"`
+	ItemType           string                 `json:"item_type" db:"item_type"`
+	ItemID             uuid.UUID              `json:"item_id" db:"item_id"`
+	UserID             *uuid.UUID             `json:"user_id,omitempty" db:"user_id"`
+	Status             string                 `json:"status" db:"status"`
+	Priority           int                    `json:"priority" db:"priority"`
+	Source             string                 `json:"source" db:"source"`
+	AutoScore          *float64               `json:"auto_score,omitempty" db:"auto_score"`
+	Reason             *string                `json:"reason,omitempty" db:"reason"`
+	Metadata           map[string]interface{} `json:"metadata,omitempty" db:"metadata"`
+	ClaimedBy          *uuid.UUID             `json:"claimed_by,omitempty" db:"claimed_by"`
+	ClaimedAt          *time.Time             `json:"claimed_at,omitempty" db:"claimed_at"`
+	ClaimExpiresAt     *time.Time             `json:"claim_expires_at,omitempty" db:"claim_expires_at"`
+	DecisionReasonCode *string                `json:"decision_reason_code,omitempty" db:"decision_reason_code"`
+	DecisionNotes      *string                `json:"decision_notes,omitempty" db:"decision_notes"`
+	DecidedBy          *uuid.UUID             `json:"decided_by,omitempty" db:"decided_by"`
+	DecidedAt          *time.Time             `json:"decided_at,omitempty" db:"decided_at"`
+	SLADueAt           time.Time              `json:"sla_due_at" db:"sla_due_at"`
+	CreatedAt          time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+type ModerationAuditEntry struct {
+	ID               uuid.UUID  `json:"id" db:"id"`
+	ModerationItemID uuid.UUID  `json:"moderation_item_id" db:"moderation_item_id"`
+	ActorID          *uuid.UUID `json:"actor_id,omitempty" db:"actor_id"`
+	Action           string     `json:"action" db:"action"`
+	ReasonCode       *string    `json:"reason_code,omitempty" db:"reason_code"`
+	Notes            *string    `json:"notes,omitempty" db:"notes"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+}
+
+type ModerationQueueFilter struct {
+	Status   string
+	ItemType string
+	Limit    int
+	Offset   int
+}
+
+type ModerationQueueStats struct {
+	Pending  int `json:"pending"`
+	Claimed  int `json:"claimed"`
+	Overdue  int `json:"overdue"`
+	Approved int `json:"approved_24h"`
+	Rejected int `json:"rejected_24h"`
+}
+
+type EnqueueModerationRequest struct {
+	ItemType  string                 `json:"item_type" binding:"required,oneof=video photo bio report"`
+	ItemID    uuid.UUID              `json:"item_id" binding:"required"`
+	UserID    *uuid.UUID             `json:"user_id"`
+	Priority  int                    `json:"priority" binding:"min=0,max=100"`
+	Source    string                 `json:"source" binding:"omitempty,oneof=auto report manual"`
+	AutoScore *float64               `json:"auto_score" binding:"omitempty,min=0,max=1"`
+	Reason    string                 `json:"reason"`
+	Metadata  map[string]interface{} `json:"metadata"`
+}
+
+type ModerationDecisionRequest struct {
+	ReasonCode string `json:"reason_code"`
+	Notes      string `json:"notes" binding:"max=2000"`
+}
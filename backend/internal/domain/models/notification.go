@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification types
+const (
+	NotificationNewMatch           = "new_match"
+	NotificationMutualMatch        = "mutual_match"
+	NotificationMatchExpiring      = "match_expiring"
+	NotificationCallMissed         = "call_missed"
+	NotificationVerificationResult = "verification_result"
+)
+
+type Notification struct {
+	ID     uuid.UUID `json:"id" db:"id"`
+	UserID uuid.UUID `json:"-" db:"user_id"`
+	Type   string    `json:"type" db:"type"`
+	Title  string    `json:"title" db:"title"`
+	Body   string    `json:"body" db:"body"`
+	// Data references what the notification is about, e.g. match_id
+	Data      map[string]interface{} `json:"data" db:"data"`
+	ReadAt    *time.Time             `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt time.Time              `json:"created_at" db:"created_at"`
+}
+
+// NotificationFilter pages through a user's notifications, newest first.
+// Cursor is the next_cursor of the previous page.
+type NotificationFilter struct {
+	Cursor     string
+	Limit      int
+	UnreadOnly bool
+}
+
+type NotificationPage struct {
+	Notifications []*Notification `json:"notifications"`
+	NextCursor    *string         `json:"next_cursor"`
+	UnreadCount   int64           `json:"unread_count"`
+}
+
+// NotificationDelivery is the data of a notification message pushed to a
+// connected user.
+type NotificationDelivery struct {
+	Notification *Notification `json:"notification"`
+	UnreadCount  int64         `json:"unread_count"`
+}
+
+// UnreadCount is the data of an unread_count message, sent when
+// notifications are read on another device.
+type UnreadCount struct {
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// VerificationResult is the outcome of reviewing a user's verification
+// video, or of staff overriding their verified status.
+type VerificationResult struct {
+	UserID   uuid.UUID  `json:"user_id"`
+	Verified bool       `json:"verified"`
+	VideoID  *uuid.UUID `json:"video_id,omitempty"`
+	// ReasonCode explains a rejection
+	ReasonCode string `json:"reason_code,omitempty"`
+}
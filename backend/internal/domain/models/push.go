@@ -0,0 +1,151 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Device platforms
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+)
+
+// PushIncomingCall is the push category of incoming calls; the others are
+// the notification types.
+const PushIncomingCall = "incoming_call"
+
+// Push priorities
+const (
+	PushPriorityNormal = "normal"
+	// PushPriorityHigh wakes the device immediately
+	PushPriorityHigh = "high"
+)
+
+type DeviceToken struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	UserID     uuid.UUID `json:"-" db:"user_id"`
+	Platform   string    `json:"platform" db:"platform"`
+	Token      string    `json:"token" db:"token"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at" db:"last_seen_at"`
+}
+
+type RegisterDeviceRequest struct {
+	Platform string `json:"platform" binding:"required,oneof=ios android"`
+	Token    string `json:"token" binding:"required,max=512"`
+}
+
+// NotificationPreferences says which pushes a user wants and when not to
+// send any. Quiet hours are "HH:MM" in Timezone and may wrap past midnight.
+type NotificationPreferences struct {
+	NewMatch           bool      `json:"new_match" db:"new_match"`
+	MutualMatch        bool      `json:"mutual_match" db:"mutual_match"`
+	MatchExpiring      bool      `json:"match_expiring" db:"match_expiring"`
+	IncomingCall       bool      `json:"incoming_call" db:"incoming_call"`
+	CallMissed         bool      `json:"call_missed" db:"call_missed"`
+	VerificationResult bool      `json:"verification_result" db:"verification_result"`
+	QuietHoursStart    *string   `json:"quiet_hours_start" db:"quiet_hours_start"`
+	QuietHoursEnd      *string   `json:"quiet_hours_end" db:"quiet_hours_end"`
+	Timezone           string    `json:"timezone" db:"timezone"`
+	UpdatedAt          time.Time `json:"updated_at,omitempty" db:"updated_at"`
+}
+
+// DefaultNotificationPreferences applies to users who never changed them.
+func DefaultNotificationPreferences() *NotificationPreferences {
+	return &NotificationPreferences{
+		NewMatch:           true,
+		MutualMatch:        true,
+		MatchExpiring:      true,
+		IncomingCall:       true,
+		CallMissed:         true,
+		VerificationResult: true,
+		Timezone:           "UTC",
+	}
+}
+
+// Allows reports whether the user wants pushes of a category.
+func (p *NotificationPreferences) Allows(category string) bool {
+	switch category {
+	case NotificationNewMatch:
+		return p.NewMatch
+	case NotificationMutualMatch:
+		return p.MutualMatch
+	case NotificationMatchExpiring:
+		return p.MatchExpiring
+	case PushIncomingCall:
+		return p.IncomingCall
+	case NotificationCallMissed:
+		return p.CallMissed
+	case NotificationVerificationResult:
+		return p.VerificationResult
+	default:
+		return true
+	}
+}
+
+// InQuietHours reports whether t falls in the user's quiet hours.
+func (p *NotificationPreferences) InQuietHours(t time.Time) bool {
+	if p.QuietHoursStart == nil || p.QuietHoursEnd == nil {
+		return false
+	}
+	start, err := ParseClock(*p.QuietHoursStart)
+	if err != nil {
+		return false
+	}
+	end, err := ParseClock(*p.QuietHoursEnd)
+	if err != nil || start == end {
+		return false
+	}
+	if loc, err := time.LoadLocation(p.Timezone); err == nil {
+		t = t.In(loc)
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	// The window wraps past midnight, e.g. 22:00 to 07:00
+	return minute >= start || minute < end
+}
+
+// ParseClock returns the minutes after midnight of an "HH:MM" time.
+func ParseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// UpdateNotificationPreferencesRequest changes only the fields that are set.
+// Quiet hours are "HH:MM" and set together; empty strings turn them off.
+type UpdateNotificationPreferencesRequest struct {
+	NewMatch           *bool   `json:"new_match"`
+	MutualMatch        *bool   `json:"mutual_match"`
+	MatchExpiring      *bool   `json:"match_expiring"`
+	IncomingCall       *bool   `json:"incoming_call"`
+	CallMissed         *bool   `json:"call_missed"`
+	VerificationResult *bool   `json:"verification_result"`
+	QuietHoursStart    *string `json:"quiet_hours_start"`
+	QuietHoursEnd      *string `json:"quiet_hours_end"`
+	Timezone           *string `json:"timezone" binding:"omitempty,max=64"`
+}
+
+// PushMessage is one push to one device.
+type PushMessage struct {
+	Token    string
+	Platform string
+	Title    string
+	Body     string
+	// Data is passed to the app; values must be strings for FCM
+	Data     map[string]string
+	Priority string
+	// CollapseKey replaces an undelivered push with the same key, so a
+	// device that was offline only shows the latest one
+	CollapseKey string
+	// TTL is how long the provider keeps trying to deliver; zero leaves it
+	// to the provider
+	TTL time.Duration
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// Realtime message types
+const (
+	RealtimeWebRTCSignal = "webrtc_signal"
+	RealtimeIncomingCall = "incoming_call"
+	RealtimeCallStatus   = "call_status"
+	RealtimeNotification = "notification"
+	RealtimeUnreadCount  = "unread_count"
+	RealtimeError        = "error"
+
+	RealtimeChatMessage        = "chat_message"
+	RealtimeMessageReceipt     = "message_receipt"
+	RealtimeMessageDeleted     = "message_deleted"
+	RealtimeConversationClosed = "conversation_closed"
+	RealtimeTyping             = "typing"
+)
+
+// WebRTC signal types relayed between call participants
+const (
+	SignalOffer        = "offer"
+	SignalAnswer       = "answer"
+	SignalICECandidate = "ice-candidate"
+)
+
+// Realtime error codes
+const (
+	RealtimeErrInvalidMessage = "invalid_message"
+	RealtimeErrUnsupported    = "unsupported_type"
+	RealtimeErrForbidden      = "forbidden"
+	RealtimeErrPeerOffline    = "peer_offline"
+	RealtimeErrInternal       = "internal_error"
+)
+
+// ClientMessage is a message sent by a client over the WebSocket.
+type ClientMessage struct {
+	Type    string        `json:"type"`
+	MatchID *uuid.UUID    `json:"match_id,omitempty"`
+	Signal  *WebRTCSignal `json:"signal,omitempty"`
+	// Typing is set by typing messages
+	Typing bool `json:"typing,omitempty"`
+}
+
+// WebRTCSignal is an SDP offer or answer, or an ICE candidate. Data is
+// relayed to the other participant untouched.
+type WebRTCSignal struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// ServerMessage is a message pushed to a client over the WebSocket.
+type ServerMessage struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// SignalRelay is the data of a webrtc_signal message delivered to a peer.
+type SignalRelay struct {
+	MatchID uuid.UUID     `json:"match_id"`
+	From    uuid.UUID     `json:"from"`
+	Signal  *WebRTCSignal `json:"signal"`
+}
+
+type RealtimeErrorData struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
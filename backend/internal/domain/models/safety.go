@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Report categories
+const (
+	ReportCategoryHarassment           = "harassment"
+	ReportCategoryInappropriateContent = "inappropriate_content"
+	ReportCategoryFakeProfile          = "fake_profile"
+	ReportCategorySpam                 = "spam"
+	ReportCategoryScam                 = "scam"
+	ReportCategoryUnderage             = "underage"
+	ReportCategorySafetyConcern        = "safety_concern"
+	ReportCategoryOther                = "other"
+)
+
+// Report statuses
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusActioned  = "actioned"
+	ReportStatusDismissed = "dismissed"
+)
+
+// SevereReportCategories are categories where a user may be at risk, so
+// fewer reports are needed to suspend the reported account.
+var SevereReportCategories = []string{ReportCategoryUnderage, ReportCategorySafetyConcern}
+
+// ReportPriorities sets the moderation priority of each report category.
+var ReportPriorities = map[string]int{
+	ReportCategoryUnderage:             95,
+	ReportCategorySafetyConcern:        90,
+	ReportCategoryHarassment:           70,
+	ReportCategoryScam:                 70,
+	ReportCategoryInappropriateContent: 60,
+	ReportCategoryFakeProfile:          50,
+	ReportCategorySpam:                 40,
+	ReportCategoryOther:                30,
+}
+
+type Block struct {
+	BlockerID uuid.UUID `json:"blocker_id" db:"blocker_id"`
+	BlockedID uuid.UUID `json:"blocked_id" db:"blocked_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// BlockedUser is an entry in a user's own block list.
+type BlockedUser struct {
+	UserID    uuid.UUID `json:"user_id"`
+	FullName  string    `json:"full_name"`
+	BlockedAt time.Time `json:"blocked_at"`
+}
+
+// ReportEvidence points at something supporting a report, such as a
+// screenshot upload, a chat message or a call.
+type ReportEvidence struct {
+	Type      string `json:"type" binding:"required,oneof=screenshot message call link"`
+	Reference string `json:"reference" binding:"required,max=500"`
+}
+
+type Report struct {
+	ID          uuid.UUID        `json:"id" db:"id"`
+	ReporterID  uuid.UUID        `json:"reporter_id" db:"reporter_id"`
+	ReportedID  uuid.UUID        `json:"reported_id" db:"reported_id"`
+	Category    string           `json:"category" db:"category"`
+	Description *string          `json:"description,omitempty" db:"description"`
+	Evidence    []ReportEvidence `json:"evidence" db:"evidence"`
+	MatchID     *uuid.UUID       `json:"match_id,omitempty" db:"match_id"`
+	CallID      *uuid.UUID       `json:"call_id,omitempty" db:"call_id"`
+	Status      string           `json:"status" db:"status"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+type ReportRequest struct {
+	Category    string           `json:"category" binding:"required,oneof=harassment inappropriate_content fake_profile spam scam underage safety_concern other"`
+	Description string           `json:"description" binding:"max=2000"`
+	Evidence    []ReportEvidence `json:"evidence" binding:"max=10,dive"`
+	MatchID     *uuid.UUID       `json:"match_id"`
+	CallID      *uuid.UUID       `json:"call_id"`
+	// Block also blocks the reported user
+	Block bool `json:"block"`
+}
+
+type ReportResponse struct {
+	Report  *Report `json:"report"`
+	Blocked bool    `json:"blocked"`
+}
@@ -6,25 +6,50 @@ import (
 	"github.com/google/uuid"
 )
 
+// User roles
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+	RoleSupport   = "support"
+)
+
+// ValidRoles lists every role that can be assigned to a user.
+var ValidRoles = []string{RoleUser, RoleModerator, RoleAdmin, RoleSupport}
+
 type User struct {
-	ID                          uuid.UUID  `json:"id" db:"id"`
-	Email                       string     `json:"email" db:"email"`
-	PasswordHash                string     `json:"-" db:"password_hash"`
-	FullName                    string     `json:"full_name" db:"full_name"`
-	DateOfBirth                 time.Time  `json:"date_of_birth" db:"date_of_birth"`
-	Gender                      string     `json:"gender" db:"gender"`
-	Bio                         *string    `json:"bio,omitempty" db:"bio"`
-	VideoID                     *uuid.UUID `json:"video_id,omitempty" db:"video_id"`
-	Verified                    bool       `json:"verified" db:"verified"`
-	EmailVerificationToken      *string    `json:"-" db:"email_verification_token"`
-	EmailVerificationExpiresAt  *time.Time `json:"-" db:"email_verification_expires_at"`
-	PasswordResetToken          *string    `json:"-" db:"password_reset_token"`
-	PasswordResetExpiresAt      *time.Time `json:"-" db:"password_reset_expires_at"`
-	LastLoginAt                 *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
-	Active                      bool       `json:"active" db:"active"`
-	CreatedAt                   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt                   time.Time  `json:"updated_at" db:"updated_at"`
-	DeletedAt                   *time.Time `json:"-" db:"deleted_at"`
+	ID                         uuid.UUID  `json:"id" db:"id"`
+	Email                      string     `json:"email" db:"email"`
+	PasswordHash               string     `json:"-" db:"password_hash"`
+	FullName                   string     `json:"full_name" db:"full_name"`
+	DateOfBirth                time.Time  `json:"date_of_birth" db:"date_of_birth"`
+	Gender                     string     `json:"gender" db:"gender"`
+	Bio                        *string    `json:"bio,omitempty" db:"bio"`
+	PendingBio                 *string    `json:"pending_bio,omitempty" db:"pending_bio"`
+	Interests                  []string   `json:"interests" db:"interests"`
+	VideoID                    *uuid.UUID `json:"video_id,omitempty" db:"video_id"`
+	Verified                   bool       `json:"verified" db:"verified"`
+	EmailVerificationToken     *string    `json:"-" db:"email_verification_token"`
+	EmailVerificationExpiresAt *time.Time `json:"-" db:"email_verification_expires_at"`
+	PasswordResetToken         *string    `json:"-" db:"password_reset_token"`
+	PasswordResetExpiresAt     *time.Time `json:"-" db:"password_reset_expires_at"`
+	LastLoginAt                *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	Active                     bool       `json:"active" db:"active"`
+	Role                       string     `json:"role" db:"role"`
+	TokenVersion               int        `json:"-" db:"token_version"`
+	CreatedAt                  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt                  time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt                  *time.Time `json:"-" db:"deleted_at"`
+}
+
+// AgeAt returns the age in whole years of someone born on dob, on the given
+// date.
+func AgeAt(dob, at time.Time) int {
+	age := at.Year() - dob.Year()
+	if at.Month() < dob.Month() || (at.Month() == dob.Month() && at.Day() < dob.Day()) {
+		age--
+	}
+	return age
 }
 
 type RegisterRequest struct {
@@ -0,0 +1,126 @@
+// Package metrics holds the Prometheus collectors of the API. Collectors are
+// registered on Registry, which is what /metrics serves.
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "findme"
+
+// Registry holds every collector of the process, including the Go runtime
+// and process collectors.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// HTTP
+var (
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method and route template.",
+		Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"method", "route"})
+)
+
+// Business events
+var (
+	Registrations = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "registrations_total",
+		Help:      "Accounts registered.",
+	})
+
+	Logins = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Successful logins.",
+	})
+
+	LoginFailures = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_failures_total",
+		Help:      "Rejected logins by reason: invalid_credentials or inactive.",
+	}, []string{"reason"})
+
+	MatchesCreated = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "matches_created_total",
+		Help:      "Matches created by weekly matching runs.",
+	})
+
+	MatchesSwept = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "matches_swept_total",
+		Help:      "Matches handled by the match sweeper by outcome: expired, completed or reminded.",
+	}, []string{"outcome"})
+
+	CallsStarted = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "calls_started_total",
+		Help:      "Video calls initiated.",
+	})
+)
+
+// Background jobs
+var (
+	jobRuns = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "job",
+		Name:      "runs_total",
+		Help:      "Background job runs by job and result: success, failure or skipped.",
+	}, []string{"job", "result"})
+
+	jobDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "job",
+		Name:      "duration_seconds",
+		Help:      "Duration of background job runs that were not skipped.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 4, 8),
+	}, []string{"job"})
+
+	jobLastSuccess = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "job",
+		Name:      "last_success_timestamp_seconds",
+		Help:      "Unix time of the last successful run of each background job.",
+	}, []string{"job"})
+)
+
+// ObserveJob records a run of a background job that started at started and
+// failed if err is not nil.
+func ObserveJob(job string, started time.Time, err error) {
+	jobDuration.WithLabelValues(job).Observe(time.Since(started).Seconds())
+	if err != nil {
+		jobRuns.WithLabelValues(job, "failure").Inc()
+		return
+	}
+	jobRuns.WithLabelValues(job, "success").Inc()
+	jobLastSuccess.WithLabelValues(job).SetToCurrentTime()
+}
+
+// SkipJob records a run that did no work, such as one that lost a lock to
+// another replica.
+func SkipJob(job string) {
+	jobRuns.WithLabelValues(job, "skipped").Inc()
+}
@@ -0,0 +1,194 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var ErrAdminTargetNotFound = errors.New("user not found")
+
+// AdminRepository performs administrative changes on user accounts. Every
+// mutation is written to admin_audit_log in the same transaction.
+type AdminRepository interface {
+	SearchUsers(ctx context.Context, filter models.UserSearchFilter) ([]*models.User, error)
+	SetRole(ctx context.Context, actorID *uuid.UUID, userID uuid.UUID, role, reason string) error
+	SetActive(ctx context.Context, actorID *uuid.UUID, userID uuid.UUID, active bool, reason string) error
+	SetVerified(ctx context.Context, actorID *uuid.UUID, userID uuid.UUID, verified bool, reason string) error
+	RevokeTokens(ctx context.Context, actorID *uuid.UUID, userID uuid.UUID, reason string) error
+	ListAudit(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.AdminAuditEntry, error)
+}
+
+type adminRepository struct {
+	db *sql.DB
+}
+
+func NewAdminRepository(db *sql.DB) AdminRepository {
+	return &adminRepository{db: db}
+}
+
+func (r *adminRepository) SearchUsers(ctx context.Context, filter models.UserSearchFilter) ([]*models.User, error) {
+	query := `
+		SELECT id, email, password_hash, full_name, date_of_birth, gender, bio, pending_bio, interests,
+		       video_id, verified, last_login_at, active, role, token_version,
+		       created_at, updated_at
+		FROM users
+		WHERE deleted_at IS NULL
+		  AND ($1 = '' OR email ILIKE '%' || $1 || '%' OR full_name ILIKE '%' || $1 || '%' OR id::text = $1)
+		  AND ($2 = '' OR role = $2)
+		  AND ($3::boolean IS NULL OR active = $3)
+		ORDER BY created_at DESC
+		LIMIT $4 OFFSET $5
+	`
+	rows, err := r.db.QueryContext(ctx, query, filter.Query, filter.Role, filter.Active, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*models.User{}
+	for rows.Next() {
+		user := &models.User{}
+		if err := rows.Scan(
+			&user.ID, &user.Email, &user.PasswordHash, &user.FullName,
+			&user.DateOfBirth, &user.Gender, &user.Bio, &user.PendingBio, pq.Array(&user.Interests), &user.VideoID,
+			&user.Verified, &user.LastLoginAt, &user.Active,
+			&user.Role, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// SetRole changes a user's role and revokes their tokens so the new role is
+// embedded on next login.
+func (r *adminRepository) SetRole(ctx context.Context, actorID *uuid.UUID, userID uuid.UUID, role, reason string) error {
+	return r.updateUser(ctx, actorID, userID, models.AdminActionRoleChanged, "role", role, reason, true)
+}
+
+// SetActive suspends or reinstates a user. Suspension also revokes tokens.
+func (r *adminRepository) SetActive(ctx context.Context, actorID *uuid.UUID, userID uuid.UUID, active bool, reason string) error {
+	action := models.AdminActionUnsuspended
+	if !active {
+		action = models.AdminActionSuspended
+	}
+	return r.updateUser(ctx, actorID, userID, action, "active", active, reason, !active)
+}
+
+func (r *adminRepository) SetVerified(ctx context.Context, actorID *uuid.UUID, userID uuid.UUID, verified bool, reason string) error {
+	return r.updateUser(ctx, actorID, userID, models.AdminActionVerificationChanged, "verified", verified, reason, false)
+}
+
+func (r *adminRepository) RevokeTokens(ctx context.Context, actorID *uuid.UUID, userID uuid.UUID, reason string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var version int
+	query := `
+		UPDATE users SET token_version = token_version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING token_version
+	`
+	err = tx.QueryRowContext(ctx, query, userID).Scan(&version)
+	if err == sql.ErrNoRows {
+		return ErrAdminTargetNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	oldValue := strconv.Itoa(version - 1)
+	newValue := strconv.Itoa(version)
+	if err := insertAdminAudit(ctx, tx, actorID, userID, models.AdminActionForcedLogout, &oldValue, &newValue, reason); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *adminRepository) ListAudit(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.AdminAuditEntry, error) {
+	query := `
+		SELECT id, actor_id, target_user_id, action, old_value, new_value, reason, created_at
+		FROM admin_audit_log
+		WHERE target_user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*models.AdminAuditEntry{}
+	for rows.Next() {
+		entry := &models.AdminAuditEntry{}
+		if err := rows.Scan(
+			&entry.ID, &entry.ActorID, &entry.TargetUserID, &entry.Action,
+			&entry.OldValue, &entry.NewValue, &entry.Reason, &entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// updateUser sets a single column on users, optionally bumping token_version,
+// and records the old and new values in the audit log. column must be a
+// trusted identifier, never user input.
+func (r *adminRepository) updateUser(ctx context.Context, actorID *uuid.UUID, userID uuid.UUID, action, column string, value interface{}, reason string, revokeTokens bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldValue string
+	selectQuery := fmt.Sprintf(`SELECT %s::text FROM users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, column)
+	err = tx.QueryRowContext(ctx, selectQuery, userID).Scan(&oldValue)
+	if err == sql.ErrNoRows {
+		return ErrAdminTargetNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	bump := 0
+	if revokeTokens {
+		bump = 1
+	}
+	var newValue string
+	updateQuery := fmt.Sprintf(`
+		UPDATE users SET %[1]s = $1, token_version = token_version + $2
+		WHERE id = $3
+		RETURNING %[1]s::text
+	`, column)
+	if err := tx.QueryRowContext(ctx, updateQuery, value, bump, userID).Scan(&newValue); err != nil {
+		return err
+	}
+
+	if err := insertAdminAudit(ctx, tx, actorID, userID, action, &oldValue, &newValue, reason); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertAdminAudit(ctx context.Context, tx *sql.Tx, actorID *uuid.UUID, userID uuid.UUID, action string, oldValue, newValue *string, reason string) error {
+	query := `
+		INSERT INTO admin_audit_log (actor_id, target_user_id, action, old_value, new_value, reason)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''))
+	`
+	_, err := tx.ExecContext(ctx, query, actorID, userID, action, oldValue, newValue, reason)
+	return err
+}
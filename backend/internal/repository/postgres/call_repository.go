@@ -0,0 +1,284 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrCallNotFound        = errors.New("call not found")
+	ErrCallMatchNotFound   = errors.New("you can only call an active mutual match")
+	ErrCallBlocked         = errors.New("you cannot call this user")
+	ErrCallParticipantBusy = errors.New("you or your match are already in a call")
+	ErrCallStateChanged    = errors.New("call is no longer in a state that allows this")
+	ErrCallNotReviewable   = errors.New("only answered calls that have ended can be reviewed, once")
+)
+
+type CallRepository interface {
+	Create(ctx context.Context, matchID, callerID uuid.UUID, sessionID string, iceServers []models.ICEServer) (*models.VideoCall, error)
+	GetByID(ctx context.Context, callID uuid.UUID) (*models.VideoCall, error)
+	UpdateStatus(ctx context.Context, callID uuid.UUID, from, to string, endedBy *uuid.UUID, endReason *string) (*models.VideoCall, error)
+	ExpireUnanswered(ctx context.Context, ringTimeout time.Duration, limit int) ([]*models.VideoCall, error)
+	EndStale(ctx context.Context, maxDuration time.Duration, limit int) ([]*models.VideoCall, error)
+	Review(ctx context.Context, callID, userID uuid.UUID, rating int, feedback *string) (*models.VideoCall, error)
+	ListHistory(ctx context.Context, userID uuid.UUID, filter models.CallHistoryFilter) (*models.CallHistory, error)
+}
+
+type callRepository struct {
+	db *sql.DB
+}
+
+func NewCallRepository(db *sql.DB) CallRepository {
+	return &callRepository{db: db}
+}
+
+const callColumns = `
+	id, match_id, caller_id, callee_id, session_id, status, started_at, ended_at,
+	duration, ended_by, end_reason, ice_servers, quality_rating, feedback,
+	callee_quality_rating, callee_feedback, created_at
+`
+
+func scanCall(row rowScanner) (*models.VideoCall, error) {
+	call := &models.VideoCall{}
+	err := row.Scan(
+		&call.ID, &call.MatchID, &call.CallerID, &call.CalleeID, &call.SessionID,
+		&call.Status, &call.StartedAt, &call.EndedAt, &call.Duration, &call.EndedBy,
+		&call.EndReason, iceServers{&call.ICEServers}, &call.CallerRating, &call.CallerFeedback, &call.CalleeRating,
+		&call.CalleeFeedback, &call.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+// iceServers scans the nullable JSONB ice_servers column.
+type iceServers struct {
+	dest *[]models.ICEServer
+}
+
+func (s iceServers) Scan(src interface{}) error {
+	if src == nil {
+		*s.dest = nil
+		return nil
+	}
+	data, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("unexpected ice_servers type %T", src)
+	}
+	return json.Unmarshal(data, s.dest)
+}
+
+func (r *callRepository) queryCalls(ctx context.Context, query string, args ...interface{}) ([]*models.VideoCall, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	calls := []*models.VideoCall{}
+	for rows.Next() {
+		call, err := scanCall(rows)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+	}
+	return calls, rows.Err()
+}
+
+// Create starts a call from callerID to the other participant of a mutual
+// match. Both users are locked for the transaction so two concurrent calls
+// cannot each find the other user free.
+func (r *callRepository) Create(ctx context.Context, matchID, callerID uuid.UUID, sessionID string, servers []models.ICEServer) (*models.VideoCall, error) {
+	serversJSON, err := json.Marshal(servers)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var calleeID uuid.UUID
+	matchQuery := `
+		SELECT CASE WHEN user_id = $2 THEN matched_user_id ELSE user_id END
+		FROM matches
+		WHERE id = $1 AND deleted_at IS NULL AND mutual_match = true AND status = 'accepted'
+		  AND (user_id = $2 OR matched_user_id = $2)
+	`
+	err = tx.QueryRowContext(ctx, matchQuery, matchID, callerID).Scan(&calleeID)
+	if err == sql.ErrNoRows {
+		return nil, ErrCallMatchNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Lock in a fixed order so two users calling each other cannot deadlock
+	first, second := callerID, calleeID
+	if first.String() > second.String() {
+		first, second = second, first
+	}
+	for _, id := range []uuid.UUID{first, second} {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtextextended('video_call:' || $1::text, 0))`, id); err != nil {
+			return nil, err
+		}
+	}
+
+	var busy bool
+	busyQuery := `
+		SELECT EXISTS(
+			SELECT 1 FROM video_calls
+			WHERE status = ANY($3) AND (caller_id IN ($1, $2) OR callee_id IN ($1, $2))
+		)
+	`
+	if err := tx.QueryRowContext(ctx, busyQuery, callerID, calleeID, pq.Array(models.LiveCallStatuses)).Scan(&busy); err != nil {
+		return nil, err
+	}
+	if busy {
+		return nil, ErrCallParticipantBusy
+	}
+
+	insertQuery := `
+		INSERT INTO video_calls (match_id, caller_id, callee_id, session_id, ice_servers)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + callColumns
+	call, err := scanCall(tx.QueryRowContext(ctx, insertQuery, matchID, callerID, calleeID, sessionID, serversJSON))
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Constraint == "no_blocked_call" {
+		return nil, ErrCallBlocked
+	}
+	if err != nil {
+		return nil, err
+	}
+	return call, tx.Commit()
+}
+
+func (r *callRepository) GetByID(ctx context.Context, callID uuid.UUID) (*models.VideoCall, error) {
+	query := `SELECT ` + callColumns + ` FROM video_calls WHERE id = $1`
+	call, err := scanCall(r.db.QueryRowContext(ctx, query, callID))
+	if err == sql.ErrNoRows {
+		return nil, ErrCallNotFound
+	}
+	return call, err
+}
+
+// UpdateStatus moves a call from one status to another, failing with
+// ErrCallStateChanged if it is no longer in the expected status. Answering
+// sets started_at; final statuses set ended_at.
+func (r *callRepository) UpdateStatus(ctx context.Context, callID uuid.UUID, from, to string, endedBy *uuid.UUID, endReason *string) (*models.VideoCall, error) {
+	query := `
+		UPDATE video_calls
+		SET status = $3,
+		    started_at = CASE WHEN $3 = 'active' THEN NOW() ELSE started_at END,
+		    ended_at = CASE WHEN $3 IN ('ended', 'missed', 'rejected', 'failed') THEN NOW() ELSE ended_at END,
+		    ended_by = COALESCE($4, ended_by),
+		    end_reason = COALESCE($5, end_reason)
+		WHERE id = $1 AND status = $2
+		RETURNING ` + callColumns
+	call, err := scanCall(r.db.QueryRowContext(ctx, query, callID, from, to, endedBy, endReason))
+	if err == sql.ErrNoRows {
+		return nil, ErrCallStateChanged
+	}
+	return call, err
+}
+
+// ExpireUnanswered marks calls missed once they have rung for longer than
+// ringTimeout.
+func (r *callRepository) ExpireUnanswered(ctx context.Context, ringTimeout time.Duration, limit int) ([]*models.VideoCall, error) {
+	query := `
+		UPDATE video_calls
+		SET status = 'missed', ended_at = NOW(), end_reason = 'timeout'
+		WHERE id IN (
+			SELECT id FROM video_calls
+			WHERE status IN ('initiated', 'ringing')
+			  AND created_at < NOW() - $1::float8 * INTERVAL '1 second'
+			ORDER BY created_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		) AND status IN ('initiated', 'ringing')
+		RETURNING ` + callColumns
+	return r.queryCalls(ctx, query, ringTimeout.Seconds(), limit)
+}
+
+// EndStale ends active calls that have run longer than maxDuration, which
+// happens when both clients vanish without hanging up.
+func (r *callRepository) EndStale(ctx context.Context, maxDuration time.Duration, limit int) ([]*models.VideoCall, error) {
+	query := `
+		UPDATE video_calls
+		SET status = 'ended', ended_at = NOW(), end_reason = 'timeout'
+		WHERE id IN (
+			SELECT id FROM video_calls
+			WHERE status = 'active'
+			  AND started_at < NOW() - $1::float8 * INTERVAL '1 second'
+			ORDER BY started_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		) AND status = 'active'
+		RETURNING ` + callColumns
+	return r.queryCalls(ctx, query, maxDuration.Seconds(), limit)
+}
+
+// Review stores a participant's rating of an answered call that has ended.
+// Each participant can review a call once.
+func (r *callRepository) Review(ctx context.Context, callID, userID uuid.UUID, rating int, feedback *string) (*models.VideoCall, error) {
+	query := `
+		UPDATE video_calls
+		SET quality_rating = CASE WHEN caller_id = $2 THEN $3 ELSE quality_rating END,
+		    feedback = CASE WHEN caller_id = $2 THEN $4 ELSE feedback END,
+		    callee_quality_rating = CASE WHEN callee_id = $2 THEN $3 ELSE callee_quality_rating END,
+		    callee_feedback = CASE WHEN callee_id = $2 THEN $4 ELSE callee_feedback END
+		WHERE id = $1 AND status IN ('ended', 'failed') AND started_at IS NOT NULL
+		  AND ((caller_id = $2 AND quality_rating IS NULL) OR (callee_id = $2 AND callee_quality_rating IS NULL))
+		RETURNING ` + callColumns
+	call, err := scanCall(r.db.QueryRowContext(ctx, query, callID, userID, rating, feedback))
+	if err != sql.ErrNoRows {
+		return call, err
+	}
+
+	existing, err := r.GetByID(ctx, callID)
+	if err != nil {
+		return nil, err
+	}
+	if !existing.IsParticipant(userID) {
+		return nil, ErrCallNotFound
+	}
+	return nil, ErrCallNotReviewable
+}
+
+// ListHistory returns a page of the user's calls, newest first, with totals
+// over all of them.
+func (r *callRepository) ListHistory(ctx context.Context, userID uuid.UUID, filter models.CallHistoryFilter) (*models.CallHistory, error) {
+	where := `
+		WHERE (caller_id = $1 OR callee_id = $1)
+		  AND ($2::uuid IS NULL OR match_id = $2)
+	`
+	history := &models.CallHistory{Limit: filter.Limit, Offset: filter.Offset}
+
+	totalsQuery := `SELECT COUNT(*), COALESCE(SUM(duration), 0) FROM video_calls` + where
+	err := r.db.QueryRowContext(ctx, totalsQuery, userID, filter.MatchID).Scan(&history.Total, &history.TotalDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT ` + callColumns + ` FROM video_calls` + where + `
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`
+	history.Calls, err = r.queryCalls(ctx, query, userID, filter.MatchID, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
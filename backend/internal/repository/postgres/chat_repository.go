@@ -0,0 +1,404 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrConversationNotFound = errors.New("conversation not found")
+	ErrConversationClosed   = errors.New("conversation is closed")
+	ErrMessageNotFound      = errors.New("message not found")
+)
+
+// ChatRepository stores the conversations of mutual matches and their
+// messages.
+type ChatRepository interface {
+	// CreateConversation opens the conversation of a mutual match; it does
+	// nothing if the match already has one
+	CreateConversation(ctx context.Context, match *models.Match) error
+	// GetConversation returns a conversation the user takes part in, hiding
+	// conversations between users who blocked each other
+	GetConversation(ctx context.Context, userID, conversationID uuid.UUID) (*models.Conversation, error)
+	ListConversations(ctx context.Context, userID uuid.UUID) ([]*models.ConversationSummary, error)
+	// CloseMatchConversation closes the conversation of a match and returns
+	// it, or nil if it was not open
+	CloseMatchConversation(ctx context.Context, matchID uuid.UUID, reason string) (*models.Conversation, error)
+	// CloseConversationsBetween closes every open conversation of two users
+	CloseConversationsBetween(ctx context.Context, userID, otherUserID uuid.UUID, reason string) ([]*models.Conversation, error)
+
+	// CreateMessage stores a message; a held one stays hidden from the
+	// recipient until moderation approves it
+	CreateMessage(ctx context.Context, conversationID, senderID uuid.UUID, body string, held bool) (*models.Message, error)
+	GetMessage(ctx context.Context, messageID uuid.UUID) (*models.Message, error)
+	// CountMessages returns how many messages the conversation holds
+	CountMessages(ctx context.Context, conversationID uuid.UUID) (int, error)
+	// ListMessages returns a page of the messages viewerID can see, newest
+	// first, and the cursor of the next page if there is one
+	ListMessages(ctx context.Context, conversationID, viewerID uuid.UUID, filter models.MessageFilter) ([]*models.Message, *string, error)
+	// MarkDelivered and MarkRead update the messages the recipient received
+	// and return a receipt for the newest one changed, or nil if none were
+	MarkDelivered(ctx context.Context, conversationID, recipientID uuid.UUID) (*models.MessageReceipt, error)
+	MarkRead(ctx context.Context, conversationID, readerID, upToMessageID uuid.UUID) (*models.MessageReceipt, error)
+	DeleteMessage(ctx context.Context, conversationID, senderID, messageID uuid.UUID) (*models.Message, error)
+}
+
+type chatRepository struct {
+	db *sql.DB
+}
+
+func NewChatRepository(db *sql.DB) ChatRepository {
+	return &chatRepository{db: db}
+}
+
+const conversationColumns = `c.id, c.match_id, c.user_id, c.matched_user_id, c.closed_at, c.close_reason,
+	c.last_message_at, c.created_at`
+
+func scanConversation(row rowScanner, extra ...interface{}) (*models.Conversation, error) {
+	c := &models.Conversation{}
+	dest := append([]interface{}{
+		&c.ID, &c.MatchID, &c.UserID, &c.MatchedUserID, &c.ClosedAt, &c.CloseReason,
+		&c.LastMessageAt, &c.CreatedAt,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+const messageColumns = `id, conversation_id, sender_id, body, delivered_at, read_at, deleted_at, held_at, created_at`
+
+func scanMessage(row rowScanner) (*models.Message, error) {
+	m := &models.Message{}
+	var deletedAt, heldAt *time.Time
+	err := row.Scan(&m.ID, &m.ConversationID, &m.SenderID, &m.Body, &m.DeliveredAt, &m.ReadAt, &deletedAt, &heldAt, &m.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	finishMessage(m, deletedAt)
+	m.Held = heldAt != nil
+	return m, nil
+}
+
+// finishMessage derives the receipt status and hides deleted bodies.
+func finishMessage(m *models.Message, deletedAt *time.Time) {
+	switch {
+	case m.ReadAt != nil:
+		m.Status = models.MessageRead
+	case m.DeliveredAt != nil:
+		m.Status = models.MessageDelivered
+	default:
+		m.Status = models.MessageSent
+	}
+	if deletedAt != nil {
+		m.Deleted = true
+		m.Body = ""
+	}
+}
+
+// conversationNotBlocked is pairNotBlocked for a conversation aliased c.
+const conversationNotBlocked = `
+	NOT EXISTS (
+		SELECT 1 FROM blocks b
+		WHERE (b.blocker_id = c.user_id AND b.blocked_id = c.matched_user_id)
+		   OR (b.blocker_id = c.matched_user_id AND b.blocked_id = c.user_id)
+	)
+`
+
+func (r *chatRepository) CreateConversation(ctx context.Context, match *models.Match) error {
+	query := `
+		INSERT INTO conversations (match_id, user_id, matched_user_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (match_id) DO NOTHING
+	`
+	_, err := r.db.ExecContext(ctx, query, match.ID, match.UserID, match.MatchedUserID)
+	return err
+}
+
+func (r *chatRepository) GetConversation(ctx context.Context, userID, conversationID uuid.UUID) (*models.Conversation, error) {
+	query := `
+		SELECT ` + conversationColumns + ` FROM conversations c
+		WHERE c.id = $1 AND (c.user_id = $2 OR c.matched_user_id = $2)
+		  AND ` + conversationNotBlocked
+	c, err := scanConversation(r.db.QueryRowContext(ctx, query, conversationID, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrConversationNotFound
+	}
+	return c, err
+}
+
+// ListConversations returns the user's conversations with the newest
+// activity first, each with the other user, its last message and how many
+// messages the user has not read.
+func (r *chatRepository) ListConversations(ctx context.Context, userID uuid.UUID) ([]*models.ConversationSummary, error) {
+	query := `
+		SELECT ` + conversationColumns + `,
+		       o.id, o.full_name, o.date_of_birth, o.bio, v.thumbnail_url, v.duration,
+		       lm.id, lm.sender_id, lm.body, lm.delivered_at, lm.read_at, lm.deleted_at, lm.held_at, lm.created_at,
+		       (SELECT COUNT(*) FROM messages u
+		        WHERE u.conversation_id = c.id AND u.sender_id <> $1
+		          AND u.read_at IS NULL AND u.deleted_at IS NULL AND u.held_at IS NULL)
+		FROM conversations c
+		JOIN users o ON o.id = CASE WHEN c.user_id = $1 THEN c.matched_user_id ELSE c.user_id END
+		LEFT JOIN videos v ON v.id = o.video_id AND v.status = 'verified' AND v.deleted_at IS NULL
+		LEFT JOIN LATERAL (
+			SELECT * FROM messages m
+			WHERE m.conversation_id = c.id AND (m.held_at IS NULL OR m.sender_id = $1)
+			ORDER BY m.created_at DESC, m.id DESC
+			LIMIT 1
+		) lm ON TRUE
+		WHERE (c.user_id = $1 OR c.matched_user_id = $1)
+		  AND o.deleted_at IS NULL
+		  AND ` + conversationNotBlocked + `
+		ORDER BY COALESCE(c.last_message_at, c.created_at) DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := []*models.ConversationSummary{}
+	for rows.Next() {
+		var (
+			s         models.ConversationSummary
+			dob       time.Time
+			thumbnail sql.NullString
+			duration  sql.NullInt64
+			lastID    uuid.NullUUID
+			last      models.Message
+			lastBody  sql.NullString
+			lastAt    sql.NullTime
+			deletedAt *time.Time
+			heldAt    *time.Time
+			senderID  uuid.NullUUID
+		)
+		c, err := scanConversation(rows,
+			&s.User.ID, &s.User.FullName, &dob, &s.User.Bio, &thumbnail, &duration,
+			&lastID, &senderID, &lastBody, &last.DeliveredAt, &last.ReadAt, &deletedAt, &heldAt, &lastAt,
+			&s.UnreadCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+		s.Conversation = *c
+		scanMatchUser(&s.User, &dob, &thumbnail, &duration)
+		if lastID.Valid {
+			last.ID, last.ConversationID, last.SenderID = lastID.UUID, c.ID, senderID.UUID
+			last.Body, last.CreatedAt = lastBody.String, lastAt.Time
+			finishMessage(&last, deletedAt)
+			last.Held = heldAt != nil
+			s.LastMessage = &last
+		}
+		summaries = append(summaries, &s)
+	}
+	return summaries, rows.Err()
+}
+
+func (r *chatRepository) CloseMatchConversation(ctx context.Context, matchID uuid.UUID, reason string) (*models.Conversation, error) {
+	query := `
+		UPDATE conversations c SET closed_at = NOW(), close_reason = $2
+		WHERE c.match_id = $1 AND c.closed_at IS NULL
+		RETURNING ` + conversationColumns
+	c, err := scanConversation(r.db.QueryRowContext(ctx, query, matchID, reason))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return c, err
+}
+
+func (r *chatRepository) CloseConversationsBetween(ctx context.Context, userID, otherUserID uuid.UUID, reason string) ([]*models.Conversation, error) {
+	query := `
+		UPDATE conversations c SET closed_at = NOW(), close_reason = $3
+		WHERE ((c.user_id = $1 AND c.matched_user_id = $2) OR (c.user_id = $2 AND c.matched_user_id = $1))
+		  AND c.closed_at IS NULL
+		RETURNING ` + conversationColumns
+	rows, err := r.db.QueryContext(ctx, query, userID, otherUserID, reason)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conversations := []*models.Conversation{}
+	for rows.Next() {
+		c, err := scanConversation(rows)
+		if err != nil {
+			return nil, err
+		}
+		conversations = append(conversations, c)
+	}
+	return conversations, rows.Err()
+}
+
+// CreateMessage only stores the message while the conversation is open, its
+// match still accepted and neither user has blocked the other, so a message
+// racing a block or the end of the match is refused. A held message does not
+// move the conversation up the recipient's inbox.
+func (r *chatRepository) CreateMessage(ctx context.Context, conversationID, senderID uuid.UUID, body string, held bool) (*models.Message, error) {
+	query := `
+		WITH conv AS (
+			UPDATE conversations c
+			SET last_message_at = CASE WHEN $4 THEN c.last_message_at ELSE NOW() END
+			FROM matches m
+			WHERE c.id = $1 AND m.id = c.match_id
+			  AND (c.user_id = $2 OR c.matched_user_id = $2)
+			  AND c.closed_at IS NULL AND m.status = 'accepted' AND m.deleted_at IS NULL
+			  AND ` + conversationNotBlocked + `
+			RETURNING c.id
+		)
+		INSERT INTO messages (conversation_id, sender_id, body, held_at)
+		SELECT id, $2, $3, CASE WHEN $4 THEN NOW() END FROM conv
+		RETURNING ` + messageColumns
+	m, err := scanMessage(r.db.QueryRowContext(ctx, query, conversationID, senderID, body, held))
+	if err == sql.ErrNoRows {
+		if _, err := r.GetConversation(ctx, senderID, conversationID); err != nil {
+			return nil, err
+		}
+		return nil, ErrConversationClosed
+	}
+	return m, err
+}
+
+func (r *chatRepository) GetMessage(ctx context.Context, messageID uuid.UUID) (*models.Message, error) {
+	query := `SELECT ` + messageColumns + ` FROM messages WHERE id = $1`
+	m, err := scanMessage(r.db.QueryRowContext(ctx, query, messageID))
+	if err == sql.ErrNoRows {
+		return nil, ErrMessageNotFound
+	}
+	return m, err
+}
+
+func (r *chatRepository) CountMessages(ctx context.Context, conversationID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM messages WHERE conversation_id = $1`, conversationID).Scan(&count)
+	return count, err
+}
+
+func (r *chatRepository) ListMessages(ctx context.Context, conversationID, viewerID uuid.UUID, filter models.MessageFilter) ([]*models.Message, *string, error) {
+	conditions := []string{"conversation_id = $1", "(held_at IS NULL OR sender_id = $2)"}
+	args := []interface{}{conversationID, viewerID}
+
+	if filter.Cursor != "" {
+		createdAt, id, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		args = append(args, createdAt, id)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	// Fetch one extra row to learn whether there is a next page
+	args = append(args, filter.Limit+1)
+	query := fmt.Sprintf(`
+		SELECT %s FROM messages
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d
+	`, messageColumns, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	messages := []*models.Message{}
+	for rows.Next() {
+		m, err := scanMessage(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var next *string
+	if len(messages) > filter.Limit {
+		messages = messages[:filter.Limit]
+		last := messages[len(messages)-1]
+		cursor := encodeCursor(last.CreatedAt, last.ID)
+		next = &cursor
+	}
+	return messages, next, nil
+}
+
+// scanReceipt reads the newest message changed by a receipt update.
+func scanReceipt(row rowScanner, conversationID uuid.UUID, status string) (*models.MessageReceipt, error) {
+	receipt := &models.MessageReceipt{ConversationID: conversationID, Status: status}
+	err := row.Scan(&receipt.MessageID, &receipt.At)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+func (r *chatRepository) MarkDelivered(ctx context.Context, conversationID, recipientID uuid.UUID) (*models.MessageReceipt, error) {
+	query := `
+		WITH updated AS (
+			UPDATE messages SET delivered_at = NOW()
+			WHERE conversation_id = $1 AND sender_id <> $2 AND delivered_at IS NULL AND held_at IS NULL
+			RETURNING id, created_at, delivered_at
+		)
+		SELECT id, delivered_at FROM updated
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+	return scanReceipt(r.db.QueryRowContext(ctx, query, conversationID, recipientID), conversationID, models.MessageDelivered)
+}
+
+// MarkRead marks every message from the other user up to the given one read,
+// and delivered if they were not already.
+func (r *chatRepository) MarkRead(ctx context.Context, conversationID, readerID, upToMessageID uuid.UUID) (*models.MessageReceipt, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS(SELECT 1 FROM messages WHERE id = $1 AND conversation_id = $2 AND (held_at IS NULL OR sender_id = $3))`,
+		upToMessageID, conversationID, readerID,
+	).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrMessageNotFound
+	}
+
+	query := `
+		WITH target AS (
+			SELECT created_at, id FROM messages WHERE id = $3
+		), updated AS (
+			UPDATE messages m SET read_at = NOW(), delivered_at = COALESCE(m.delivered_at, NOW())
+			FROM target t
+			WHERE m.conversation_id = $1 AND m.sender_id <> $2 AND m.read_at IS NULL AND m.held_at IS NULL
+			  AND (m.created_at, m.id) <= (t.created_at, t.id)
+			RETURNING m.id, m.created_at, m.read_at
+		)
+		SELECT id, read_at FROM updated
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+	return scanReceipt(r.db.QueryRowContext(ctx, query, conversationID, readerID, upToMessageID), conversationID, models.MessageRead)
+}
+
+// DeleteMessage soft-deletes a message its sender wants gone.
+func (r *chatRepository) DeleteMessage(ctx context.Context, conversationID, senderID, messageID uuid.UUID) (*models.Message, error) {
+	query := `
+		UPDATE messages SET deleted_at = NOW()
+		WHERE id = $1 AND conversation_id = $2 AND sender_id = $3 AND deleted_at IS NULL
+		RETURNING ` + messageColumns
+	m, err := scanMessage(r.db.QueryRowContext(ctx, query, messageID, conversationID, senderID))
+	if err == sql.ErrNoRows {
+		return nil, ErrMessageNotFound
+	}
+	return m, err
+}
@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// EmbeddingRepository reads the profile data that is embedded into Qdrant and
+// manages the outbox of pending profile changes written by the users trigger.
+type EmbeddingRepository interface {
+	ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]*models.EmbeddingOutboxEntry, error)
+	MarkProcessed(ctx context.Context, ids []int64) error
+	MarkFailed(ctx context.Context, ids []int64, cause error, retryAfter time.Duration) error
+	PurgeProcessed(ctx context.Context, before time.Time) (int64, error)
+	GetProfiles(ctx context.Context, userIDs []uuid.UUID) ([]*models.EmbeddingProfile, error)
+	ListProfiles(ctx context.Context, afterID uuid.UUID, limit int) ([]*models.EmbeddingProfile, error)
+}
+
+type embeddingRepository struct {
+	db *sql.DB
+}
+
+func NewEmbeddingRepository(db *sql.DB) EmbeddingRepository {
+	return &embeddingRepository{db: db}
+}
+
+const embeddingProfileColumns = `
+	id, gender, date_of_birth, bio, COALESCE(verified, FALSE),
+	EXISTS (
+		SELECT 1 FROM videos v
+		WHERE v.user_id = users.id AND v.status = 'verified' AND v.deleted_at IS NULL
+	),
+	COALESCE(active, FALSE), latitude, longitude
+`
+
+func scanEmbeddingProfile(row rowScanner) (*models.EmbeddingProfile, error) {
+	p := &models.EmbeddingProfile{}
+	if err := row.Scan(
+		&p.UserID, &p.Gender, &p.DateOfBirth, &p.Bio, &p.Verified, &p.VideoVerified,
+		&p.Active, &p.Latitude, &p.Longitude,
+	); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ClaimOutbox leases up to limit due entries to the caller. Leased entries are
+// hidden from other workers until the lease runs out, so a crashed worker's
+// entries are picked up again instead of being lost.
+func (r *embeddingRepository) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]*models.EmbeddingOutboxEntry, error) {
+	query := `
+		UPDATE embedding_outbox
+		SET next_attempt_at = NOW() + $2::float8 * INTERVAL '1 second'
+		WHERE id IN (
+			SELECT id FROM embedding_outbox
+			WHERE processed_at IS NULL AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, user_id, operation, attempts, last_error, next_attempt_at, processed_at, created_at
+	`
+	rows, err := r.db.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*models.EmbeddingOutboxEntry{}
+	for rows.Next() {
+		e := &models.EmbeddingOutboxEntry{}
+		if err := rows.Scan(
+			&e.ID, &e.UserID, &e.Operation, &e.Attempts, &e.LastError,
+			&e.NextAttemptAt, &e.ProcessedAt, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *embeddingRepository) MarkProcessed(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query := `
+		UPDATE embedding_outbox
+		SET processed_at = NOW(), last_error = NULL
+		WHERE id = ANY($1)
+	`
+	_, err := r.db.ExecContext(ctx, query, pq.Array(ids))
+	return err
+}
+
+func (r *embeddingRepository) MarkFailed(ctx context.Context, ids []int64, cause error, retryAfter time.Duration) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	query := `
+		UPDATE embedding_outbox
+		SET attempts = attempts + 1, last_error = $2,
+		    next_attempt_at = NOW() + $3::float8 * INTERVAL '1 second'
+		WHERE id = ANY($1)
+	`
+	_, err := r.db.ExecContext(ctx, query, pq.Array(ids), cause.Error(), retryAfter.Seconds())
+	return err
+}
+
+// PurgeProcessed deletes entries processed before the given time.
+func (r *embeddingRepository) PurgeProcessed(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM embedding_outbox WHERE processed_at IS NOT NULL AND processed_at < $1`
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetProfiles returns the current profiles of the given users. Deleted users
+// are omitted, which callers treat as a request to drop their embedding.
+func (r *embeddingRepository) GetProfiles(ctx context.Context, userIDs []uuid.UUID) ([]*models.EmbeddingProfile, error) {
+	ids := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		ids[i] = id.String()
+	}
+	query := `
+		SELECT ` + embeddingProfileColumns + `
+		FROM users
+		WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+	`
+	return r.queryProfiles(ctx, query, pq.Array(ids))
+}
+
+// ListProfiles pages through all non-deleted users in id order.
+func (r *embeddingRepository) ListProfiles(ctx context.Context, afterID uuid.UUID, limit int) ([]*models.EmbeddingProfile, error) {
+	query := `
+		SELECT ` + embeddingProfileColumns + `
+		FROM users
+		WHERE id > $1 AND deleted_at IS NULL
+		ORDER BY id
+		LIMIT $2
+	`
+	return r.queryProfiles(ctx, query, afterID, limit)
+}
+
+func (r *embeddingRepository) queryProfiles(ctx context.Context, query string, args ...interface{}) ([]*models.EmbeddingProfile, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := []*models.EmbeddingProfile{}
+	for rows.Next() {
+		p, err := scanEmbeddingProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, rows.Err()
+}
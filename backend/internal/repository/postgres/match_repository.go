@@ -0,0 +1,621 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrMatchingRunNotStarted = errors.New("matching run for this week is already running or completed")
+	ErrMatchProfileNotFound  = errors.New("user not found")
+	ErrMatchNotFound         = errors.New("match not found")
+	ErrMatchClosed           = errors.New("match is no longer open for this action")
+)
+
+type MatchRepository interface {
+	ListEligibleProfiles(ctx context.Context) ([]*models.MatchProfile, error)
+	GetProfile(ctx context.Context, userID uuid.UUID) (*models.MatchProfile, error)
+	ListCandidateProfiles(ctx context.Context, filter models.CandidateFilter) ([]*models.MatchProfile, error)
+	ListMatchedUserIDs(ctx context.Context, userID uuid.UUID, since time.Time) ([]uuid.UUID, error)
+	ListBlockedUserIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	ListBlockedPairs(ctx context.Context) ([][2]uuid.UUID, error)
+	GetMatch(ctx context.Context, matchID uuid.UUID) (*models.Match, error)
+	ListUserWeekMatches(ctx context.Context, userID uuid.UUID, week, year int) ([]*models.WeeklyMatch, error)
+	ListActiveMatches(ctx context.Context, userID uuid.UUID) ([]*models.ActiveMatch, error)
+	RecordAction(ctx context.Context, matchID, userID uuid.UUID, action string) (*models.Match, error)
+	EndMatch(ctx context.Context, matchID, userID uuid.UUID, reason, feedback string) (*models.Match, error)
+	ExpireMatches(ctx context.Context, limit int) ([]*models.Match, error)
+	ClaimExpiryReminders(ctx context.Context, window time.Duration, limit int) ([]*models.Match, error)
+	ListPairsSince(ctx context.Context, since time.Time) ([][2]uuid.UUID, error)
+	ListWeekMatches(ctx context.Context, week, year int) ([]*models.Match, error)
+	CreateMatches(ctx context.Context, matches []*models.Match) (int, error)
+	BeginRun(ctx context.Context, week, year int, force bool) (*models.MatchingRun, error)
+	FinishRun(ctx context.Context, run *models.MatchingRun, runErr error) error
+}
+
+type matchRepository struct {
+	db *sql.DB
+}
+
+func NewMatchRepository(db *sql.DB) MatchRepository {
+	return &matchRepository{db: db}
+}
+
+const matchColumns = `
+	id, user_id, matched_user_id, week_number, year, status, match_score,
+	score_breakdown, user_action, matched_user_action, mutual_match, expires_at, matched_at,
+	completed_at, ended_by, end_reason, created_at, updated_at
+`
+
+func scanMatch(row rowScanner, extra ...interface{}) (*models.Match, error) {
+	match := &models.Match{}
+	dest := []interface{}{
+		&match.ID, &match.UserID, &match.MatchedUserID, &match.WeekNumber,
+		&match.Year, &match.Status, &match.MatchScore,
+		scoreBreakdown{&match.ScoreBreakdown}, &match.UserAction,
+		&match.MatchedUserAction, &match.MutualMatch, &match.ExpiresAt,
+		&match.MatchedAt, &match.CompletedAt, &match.EndedBy, &match.EndReason,
+		&match.CreatedAt, &match.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return match, nil
+}
+
+// scoreBreakdown scans the nullable JSONB score_breakdown column.
+type scoreBreakdown struct {
+	dest **models.ScoreBreakdown
+}
+
+func (s scoreBreakdown) Scan(src interface{}) error {
+	if src == nil {
+		*s.dest = nil
+		return nil
+	}
+	data, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("unexpected score_breakdown type %T", src)
+	}
+	breakdown := &models.ScoreBreakdown{}
+	if err := json.Unmarshal(data, breakdown); err != nil {
+		return err
+	}
+	*s.dest = breakdown
+	return nil
+}
+
+func marshalBreakdown(b *models.ScoreBreakdown) (interface{}, error) {
+	if b == nil {
+		return nil, nil
+	}
+	return json.Marshal(b)
+}
+
+// pairNotBlocked hides matches between users where either has blocked the
+// other. It expects the matches table to be aliased as m.
+const pairNotBlocked = `
+	NOT EXISTS (
+		SELECT 1 FROM blocks b
+		WHERE (b.blocker_id = m.user_id AND b.blocked_id = m.matched_user_id)
+		   OR (b.blocker_id = m.matched_user_id AND b.blocked_id = m.user_id)
+	)
+`
+
+// matchProfileSelect selects a user's profile joined with their matching
+// preferences, falling back to the column defaults when none are saved.
+const matchProfileSelect = `
+	SELECT u.id, u.gender, u.date_of_birth, u.bio, u.latitude, u.longitude, u.last_login_at,
+	       u.interests, COALESCE(p.interested_in, ARRAY['male', 'female', 'other']),
+	       COALESCE(p.min_age, 18), COALESCE(p.max_age, 99), COALESCE(p.max_distance_km, 0)
+	FROM users u
+	LEFT JOIN user_preferences p ON p.user_id = u.id
+`
+
+// hasVerifiedVideo restricts a matchProfileSelect query to users with a
+// verified video profile.
+const hasVerifiedVideo = `
+	EXISTS (
+		SELECT 1 FROM videos v
+		WHERE v.user_id = u.id AND v.status = 'verified' AND v.deleted_at IS NULL
+	)
+`
+
+func scanMatchProfile(row rowScanner) (*models.MatchProfile, error) {
+	p := &models.MatchProfile{}
+	if err := row.Scan(
+		&p.UserID, &p.Gender, &p.DateOfBirth, &p.Bio, &p.Latitude, &p.Longitude,
+		&p.LastLoginAt, pq.Array(&p.Interests), pq.Array(&p.InterestedIn), &p.MinAge, &p.MaxAge, &p.MaxDistanceKm,
+	); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *matchRepository) queryMatchProfiles(ctx context.Context, query string, args ...interface{}) ([]*models.MatchProfile, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := []*models.MatchProfile{}
+	for rows.Next() {
+		p, err := scanMatchProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, rows.Err()
+}
+
+// ListEligibleProfiles returns active, verified users that have a verified
+// video, along with their matching preferences.
+func (r *matchRepository) ListEligibleProfiles(ctx context.Context) ([]*models.MatchProfile, error) {
+	query := matchProfileSelect + `
+		WHERE u.active = TRUE AND u.verified = TRUE AND u.deleted_at IS NULL
+		  AND ` + hasVerifiedVideo + `
+		ORDER BY u.id
+	`
+	return r.queryMatchProfiles(ctx, query)
+}
+
+func (r *matchRepository) GetProfile(ctx context.Context, userID uuid.UUID) (*models.MatchProfile, error) {
+	query := matchProfileSelect + `
+		WHERE u.id = $1 AND u.deleted_at IS NULL
+	`
+	p, err := scanMatchProfile(r.db.QueryRowContext(ctx, query, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrMatchProfileNotFound
+	}
+	return p, err
+}
+
+// ListCandidateProfiles applies the candidate filter in SQL, ordering by most
+// recent login. Distance uses the haversine formula on the stored location.
+func (r *matchRepository) ListCandidateProfiles(ctx context.Context, filter models.CandidateFilter) ([]*models.MatchProfile, error) {
+	excluded := make([]string, len(filter.ExcludeIDs))
+	for i, id := range filter.ExcludeIDs {
+		excluded[i] = id.String()
+	}
+
+	query := matchProfileSelect + `
+		WHERE u.active = TRUE AND u.verified = TRUE AND u.deleted_at IS NULL
+		  AND ` + hasVerifiedVideo + `
+		  AND (COALESCE(cardinality($1::varchar[]), 0) = 0 OR u.gender = ANY($1))
+		  AND ($2 = 0 OR u.date_of_birth <= CURRENT_DATE - make_interval(years => $2))
+		  AND ($3 = 0 OR u.date_of_birth > CURRENT_DATE - make_interval(years => $3 + 1))
+		  AND ($4::float8 IS NULL OR $5::float8 IS NULL OR $6 = 0 OR (
+			u.latitude IS NOT NULL AND u.longitude IS NOT NULL AND
+			2 * 6371 * asin(sqrt(
+				power(sin(radians(u.latitude - $4) / 2), 2) +
+				cos(radians($4)) * cos(radians(u.latitude)) * power(sin(radians(u.longitude - $5) / 2), 2)
+			)) <= $6
+		  ))
+		  AND NOT (u.id = ANY($7::uuid[]))
+		ORDER BY u.last_login_at DESC NULLS LAST, u.id
+		LIMIT $8 OFFSET $9
+	`
+	return r.queryMatchProfiles(
+		ctx, query,
+		pq.Array(filter.Genders), filter.MinAge, filter.MaxAge,
+		filter.Latitude, filter.Longitude, filter.RadiusKm,
+		pq.Array(excluded), filter.Limit, filter.Offset,
+	)
+}
+
+// ListMatchedUserIDs returns everyone the user has been matched with since
+// the given time, in either direction.
+func (r *matchRepository) ListMatchedUserIDs(ctx context.Context, userID uuid.UUID, since time.Time) ([]uuid.UUID, error) {
+	query := `
+		SELECT CASE WHEN user_id = $1 THEN matched_user_id ELSE user_id END
+		FROM matches
+		WHERE (user_id = $1 OR matched_user_id = $1) AND created_at >= $2 AND deleted_at IS NULL
+	`
+	return r.queryUserIDs(ctx, query, userID, since)
+}
+
+// ListBlockedUserIDs returns everyone the user has blocked or been blocked by.
+func (r *matchRepository) ListBlockedUserIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	query := `
+		SELECT blocked_id FROM blocks WHERE blocker_id = $1
+		UNION
+		SELECT blocker_id FROM blocks WHERE blocked_id = $1
+	`
+	return r.queryUserIDs(ctx, query, userID)
+}
+
+func (r *matchRepository) queryUserIDs(ctx context.Context, query string, args ...interface{}) ([]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ListPairsSince returns every user pair matched since the given time, used to
+// avoid re-matching the same people too often.
+func (r *matchRepository) ListPairsSince(ctx context.Context, since time.Time) ([][2]uuid.UUID, error) {
+	query := `
+		SELECT user_id, matched_user_id
+		FROM matches
+		WHERE created_at >= $1 AND deleted_at IS NULL
+	`
+	return r.queryPairs(ctx, query, since)
+}
+
+// ListBlockedPairs returns every pair where one user blocked the other.
+func (r *matchRepository) ListBlockedPairs(ctx context.Context) ([][2]uuid.UUID, error) {
+	return r.queryPairs(ctx, `SELECT blocker_id, blocked_id FROM blocks`)
+}
+
+func (r *matchRepository) queryPairs(ctx context.Context, query string, args ...interface{}) ([][2]uuid.UUID, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pairs := [][2]uuid.UUID{}
+	for rows.Next() {
+		var pair [2]uuid.UUID
+		if err := rows.Scan(&pair[0], &pair[1]); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, rows.Err()
+}
+
+func (r *matchRepository) ListWeekMatches(ctx context.Context, week, year int) ([]*models.Match, error) {
+	query := `
+		SELECT ` + matchColumns + `
+		FROM matches
+		WHERE week_number = $1 AND year = $2 AND deleted_at IS NULL
+	`
+	return r.queryMatches(ctx, query, week, year)
+}
+
+// CreateMatches inserts the given matches in one transaction, skipping pairs
+// that already exist for the week. It returns how many rows were inserted.
+func (r *matchRepository) CreateMatches(ctx context.Context, matches []*models.Match) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO matches (user_id, matched_user_id, week_number, year, match_score, score_breakdown, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT ON CONSTRAINT unique_match_per_week DO NOTHING
+		RETURNING id, status, created_at, updated_at
+	`
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	inserted := 0
+	for _, m := range matches {
+		breakdown, err := marshalBreakdown(m.ScoreBreakdown)
+		if err != nil {
+			return 0, err
+		}
+		err = stmt.QueryRowContext(
+			ctx, m.UserID, m.MatchedUserID, m.WeekNumber, m.Year, m.MatchScore, breakdown, m.ExpiresAt,
+		).Scan(&m.ID, &m.Status, &m.CreatedAt, &m.UpdatedAt)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		inserted++
+	}
+
+	return inserted, tx.Commit()
+}
+
+// BeginRun records the start of a matching run for a week. It refuses to start
+// while another run for the week is in progress, or if the week already
+// completed unless force is set. Runs stuck for over an hour are taken over.
+func (r *matchRepository) BeginRun(ctx context.Context, week, year int, force bool) (*models.MatchingRun, error) {
+	run := &models.MatchingRun{}
+	query := `
+		INSERT INTO matching_runs (week_number, year, status)
+		VALUES ($1, $2, 'running')
+		ON CONFLICT ON CONSTRAINT unique_matching_run_per_week DO UPDATE
+		SET status = 'running', started_at = NOW(), finished_at = NULL, error = NULL,
+		    eligible_users = 0, matches_created = 0
+		WHERE matching_runs.status = 'failed'
+		   OR (matching_runs.status = 'completed' AND $3)
+		   OR (matching_runs.status = 'running' AND matching_runs.started_at < NOW() - INTERVAL '1 hour')
+		RETURNING id, week_number, year, status, started_at
+	`
+	err := r.db.QueryRowContext(ctx, query, week, year, force).Scan(
+		&run.ID, &run.WeekNumber, &run.Year, &run.Status, &run.StartedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrMatchingRunNotStarted
+	}
+	return run, err
+}
+
+func (r *matchRepository) FinishRun(ctx context.Context, run *models.MatchingRun, runErr error) error {
+	run.Status = models.MatchingRunCompleted
+	var errText *string
+	if runErr != nil {
+		run.Status = models.MatchingRunFailed
+		msg := runErr.Error()
+		errText = &msg
+	}
+	run.Error = errText
+
+	query := `
+		UPDATE matching_runs
+		SET status = $2, eligible_users = $3, matches_created = $4, error = $5, finished_at = NOW()
+		WHERE id = $1
+		RETURNING finished_at
+	`
+	return r.db.QueryRowContext(
+		ctx, query, run.ID, run.Status, run.EligibleUsers, run.MatchesCreated, errText,
+	).Scan(&run.FinishedAt)
+}
+
+func (r *matchRepository) GetMatch(ctx context.Context, matchID uuid.UUID) (*models.Match, error) {
+	query := `SELECT ` + matchColumns + ` FROM matches WHERE id = $1 AND deleted_at IS NULL`
+	match, err := scanMatch(r.db.QueryRowContext(ctx, query, matchID))
+	if err == sql.ErrNoRows {
+		return nil, ErrMatchNotFound
+	}
+	return match, err
+}
+
+// otherUserJoin joins the other participant of match m for the user in $1,
+// along with their verified video if they have one.
+const otherUserJoin = `
+	JOIN users o ON o.id = CASE WHEN m.user_id = $1 THEN m.matched_user_id ELSE m.user_id END
+	LEFT JOIN videos v ON v.id = o.video_id AND v.status = 'verified' AND v.deleted_at IS NULL
+`
+
+func scanMatchUser(user *models.MatchUser, dob *time.Time, thumbnail *sql.NullString, duration *sql.NullInt64) {
+	user.Age = models.AgeAt(*dob, time.Now())
+	if duration.Valid {
+		user.VideoProfile = &models.VideoProfileSummary{Duration: int(duration.Int64)}
+		if thumbnail.Valid {
+			user.VideoProfile.ThumbnailURL = &thumbnail.String
+		}
+	}
+}
+
+// ListUserWeekMatches returns the user's matches for a week, from their side.
+func (r *matchRepository) ListUserWeekMatches(ctx context.Context, userID uuid.UUID, week, year int) ([]*models.WeeklyMatch, error) {
+	query := `
+		SELECT m.id, m.match_score, m.score_breakdown, m.status, m.mutual_match, m.expires_at,
+		       CASE WHEN m.user_id = $1 THEN m.user_action ELSE m.matched_user_action END,
+		       o.id, o.full_name, o.date_of_birth, o.bio, v.thumbnail_url, v.duration
+		FROM matches m
+		` + otherUserJoin + `
+		WHERE (m.user_id = $1 OR m.matched_user_id = $1)
+		  AND m.week_number = $2 AND m.year = $3 AND m.deleted_at IS NULL
+		  AND o.deleted_at IS NULL
+		  AND ` + pairNotBlocked + `
+		ORDER BY m.match_score DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, week, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []*models.WeeklyMatch{}
+	for rows.Next() {
+		var (
+			m         models.WeeklyMatch
+			breakdown *models.ScoreBreakdown
+			dob       time.Time
+			thumbnail sql.NullString
+			duration  sql.NullInt64
+		)
+		if err := rows.Scan(
+			&m.ID, &m.MatchScore, scoreBreakdown{&breakdown}, &m.Status, &m.MutualMatch, &m.ExpiresAt, &m.MyAction,
+			&m.User.ID, &m.User.FullName, &dob, &m.User.Bio, &thumbnail, &duration,
+		); err != nil {
+			return nil, err
+		}
+		scanMatchUser(&m.User, &dob, &thumbnail, &duration)
+		m.Reasons = breakdown.Reasons()
+		matches = append(matches, &m)
+	}
+	return matches, rows.Err()
+}
+
+// ListActiveMatches returns the user's mutual matches that have not ended,
+// with a summary of their calls.
+func (r *matchRepository) ListActiveMatches(ctx context.Context, userID uuid.UUID) ([]*models.ActiveMatch, error) {
+	query := `
+		SELECT m.id, m.score_breakdown, m.matched_at, m.expires_at, m.expires_at > NOW(),
+		       COALESCE(c.calls_count, 0), c.last_call_at,
+		       o.id, o.full_name, o.date_of_birth, o.bio, v.thumbnail_url, v.duration
+		FROM matches m
+		` + otherUserJoin + `
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) FILTER (WHERE vc.status = 'ended') AS calls_count,
+			       MAX(vc.started_at) AS last_call_at
+			FROM video_calls vc
+			WHERE vc.match_id = m.id
+		) c ON TRUE
+		WHERE (m.user_id = $1 OR m.matched_user_id = $1)
+		  AND m.mutual_match = TRUE AND m.status = 'accepted' AND m.deleted_at IS NULL
+		  AND o.deleted_at IS NULL
+		  AND ` + pairNotBlocked + `
+		ORDER BY m.matched_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []*models.ActiveMatch{}
+	for rows.Next() {
+		var (
+			m         models.ActiveMatch
+			breakdown *models.ScoreBreakdown
+			dob       time.Time
+			thumbnail sql.NullString
+			duration  sql.NullInt64
+		)
+		if err := rows.Scan(
+			&m.ID, scoreBreakdown{&breakdown}, &m.MatchedAt, &m.ExpiresAt, &m.CanVideoCall, &m.CallsCount, &m.LastCallAt,
+			&m.User.ID, &m.User.FullName, &dob, &m.User.Bio, &thumbnail, &duration,
+		); err != nil {
+			return nil, err
+		}
+		scanMatchUser(&m.User, &dob, &thumbnail, &duration)
+		m.Reasons = breakdown.Reasons()
+		matches = append(matches, &m)
+	}
+	return matches, rows.Err()
+}
+
+// RecordAction stores a participant's accept or reject in the column for
+// their side of the match. A rejection closes the match; when the other side
+// has already accepted, the check_mutual_match trigger sets mutual_match and
+// the match moves to accepted.
+func (r *matchRepository) RecordAction(ctx context.Context, matchID, userID uuid.UUID, action string) (*models.Match, error) {
+	query := `
+		UPDATE matches m
+		SET user_action = CASE WHEN user_id = $2 THEN $3 ELSE user_action END,
+		    matched_user_action = CASE WHEN matched_user_id = $2 THEN $3 ELSE matched_user_action END,
+		    status = CASE
+		        WHEN $3 = 'rejected' THEN 'rejected'
+		        WHEN (CASE WHEN user_id = $2 THEN matched_user_action ELSE user_action END) = 'accepted' THEN 'accepted'
+		        ELSE status
+		    END
+		WHERE id = $1 AND (user_id = $2 OR matched_user_id = $2)
+		  AND status = 'pending' AND expires_at > NOW() AND deleted_at IS NULL
+		  AND ` + pairNotBlocked + `
+		RETURNING ` + matchColumns
+	match, err := scanMatch(r.db.QueryRowContext(ctx, query, matchID, userID, action))
+	if err == sql.ErrNoRows {
+		return nil, r.explainMissedUpdate(ctx, matchID, userID)
+	}
+	return match, err
+}
+
+// EndMatch completes an active mutual match on behalf of a participant.
+func (r *matchRepository) EndMatch(ctx context.Context, matchID, userID uuid.UUID, reason, feedback string) (*models.Match, error) {
+	query := `
+		UPDATE matches m
+		SET status = 'completed', completed_at = NOW(), ended_by = $2,
+		    end_reason = $3, end_feedback = NULLIF($4, '')
+		WHERE id = $1 AND (user_id = $2 OR matched_user_id = $2)
+		  AND mutual_match = TRUE AND status = 'accepted' AND deleted_at IS NULL
+		  AND ` + pairNotBlocked + `
+		RETURNING ` + matchColumns
+	match, err := scanMatch(r.db.QueryRowContext(ctx, query, matchID, userID, reason, feedback))
+	if err == sql.ErrNoRows {
+		return nil, r.explainMissedUpdate(ctx, matchID, userID)
+	}
+	return match, err
+}
+
+// ExpireMatches closes up to limit matches past their expiry: pending ones
+// become expired and active mutual ones completed. Rows locked by another
+// sweeper are skipped.
+func (r *matchRepository) ExpireMatches(ctx context.Context, limit int) ([]*models.Match, error) {
+	query := `
+		UPDATE matches
+		SET status = CASE WHEN status = 'pending' THEN 'expired' ELSE 'completed' END,
+		    completed_at = CASE WHEN status = 'accepted' THEN NOW() ELSE completed_at END
+		WHERE id IN (
+			SELECT id FROM matches
+			WHERE status IN ('pending', 'accepted') AND expires_at <= NOW() AND deleted_at IS NULL
+			ORDER BY expires_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + matchColumns
+	return r.queryMatches(ctx, query, limit)
+}
+
+// ClaimExpiryReminders marks up to limit open matches expiring within the
+// window as reminded and returns them. Each match is claimed only once.
+func (r *matchRepository) ClaimExpiryReminders(ctx context.Context, window time.Duration, limit int) ([]*models.Match, error) {
+	query := `
+		UPDATE matches
+		SET reminder_sent_at = NOW()
+		WHERE id IN (
+			SELECT id FROM matches m
+			WHERE status IN ('pending', 'accepted') AND reminder_sent_at IS NULL
+			  AND expires_at > NOW() AND expires_at <= NOW() + $1::float8 * INTERVAL '1 second'
+			  AND deleted_at IS NULL AND ` + pairNotBlocked + `
+			ORDER BY expires_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + matchColumns
+	return r.queryMatches(ctx, query, window.Seconds(), limit)
+}
+
+func (r *matchRepository) queryMatches(ctx context.Context, query string, args ...interface{}) ([]*models.Match, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []*models.Match{}
+	for rows.Next() {
+		match, err := scanMatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, match)
+	}
+	return matches, rows.Err()
+}
+
+// explainMissedUpdate tells apart a match the user cannot see from one that
+// is in the wrong state for the attempted change.
+func (r *matchRepository) explainMissedUpdate(ctx context.Context, matchID, userID uuid.UUID) error {
+	match, err := r.GetMatch(ctx, matchID)
+	if err != nil {
+		return err
+	}
+	if match.UserID != userID && match.MatchedUserID != userID {
+		return ErrMatchNotFound
+	}
+
+	var blocked bool
+	query := `SELECT EXISTS(SELECT 1 FROM matches m WHERE m.id = $1 AND NOT ` + pairNotBlocked + `)`
+	if err := r.db.QueryRowContext(ctx, query, matchID).Scan(&blocked); err != nil {
+		return err
+	}
+	if blocked {
+		return ErrMatchNotFound
+	}
+	return ErrMatchClosed
+}
@@ -0,0 +1,411 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrModerationItemNotFound    = errors.New("moderation item not found")
+	ErrModerationItemNotClaimed  = errors.New("moderation item is not claimed by this moderator")
+	ErrModerationItemUnavailable = errors.New("moderation item is already claimed or decided")
+	ErrModerationQueueEmpty      = errors.New("moderation queue is empty")
+)
+
+type ModerationRepository interface {
+	Enqueue(ctx context.Context, item *models.ModerationItem) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ModerationItem, error)
+	List(ctx context.Context, filter models.ModerationQueueFilter) ([]*models.ModerationItem, error)
+	Stats(ctx context.Context) (*models.ModerationQueueStats, error)
+	ClaimNext(ctx context.Context, moderatorID uuid.UUID, itemType string, ttl time.Duration) (*models.ModerationItem, error)
+	Claim(ctx context.Context, id, moderatorID uuid.UUID, ttl time.Duration) (*models.ModerationItem, error)
+	Release(ctx context.Context, id, moderatorID uuid.UUID) error
+	Decide(ctx context.Context, id, moderatorID uuid.UUID, status, reasonCode, notes string) (*models.ModerationItem, error)
+	ListAudit(ctx context.Context, id uuid.UUID) ([]*models.ModerationAuditEntry, error)
+}
+
+type moderationRepository struct {
+	db *sql.DB
+}
+
+func NewModerationRepository(db *sql.DB) ModerationRepository {
+	return &moderationRepository{db: db}
+}
+
+const moderationItemColumns = `
+	id, item_type, item_id, user_id, status, priority, source, auto_score, reason,
+	metadata, claimed_by, claimed_at, claim_expires_at, decision_reason_code,
+	decision_notes, decided_by, decided_at, sla_due_at, created_at, updated_at
+`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanModerationItem scans moderationItemColumns followed by any extra
+// columns the query returns.
+func scanModerationItem(row rowScanner, extra ...interface{}) (*models.ModerationItem, error) {
+	item := &models.ModerationItem{}
+	var metadata []byte
+	dest := []interface{}{
+		&item.ID, &item.ItemType, &item.ItemID, &item.UserID, &item.Status,
+		&item.Priority, &item.Source, &item.AutoScore, &item.Reason,
+		&metadata, &item.ClaimedBy, &item.ClaimedAt, &item.ClaimExpiresAt,
+		&item.DecisionReasonCode, &item.DecisionNotes, &item.DecidedBy,
+		&item.DecidedAt, &item.SLADueAt, &item.CreatedAt, &item.UpdatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &item.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode moderation metadata: %w", err)
+		}
+	}
+	return item, nil
+}
+
+// Enqueue inserts a new review item. If the same entity already has an open
+// review, the existing row is escalated instead of creating a duplicate.
+func (r *moderationRepository) Enqueue(ctx context.Context, item *models.ModerationItem) error {
+	metadata, err := json.Marshal(item.Metadata)
+	if err != nil {
+		return err
+	}
+	if item.Metadata == nil {
+		metadata = []byte("{}")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO moderation_items (item_type, item_id, user_id, priority, source, auto_score, reason, metadata, sla_due_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (item_type, item_id) WHERE status IN ('pending', 'claimed')
+		DO UPDATE SET
+			priority = GREATEST(moderation_items.priority, EXCLUDED.priority),
+			sla_due_at = LEAST(moderation_items.sla_due_at, EXCLUDED.sla_due_at)
+		RETURNING ` + moderationItemColumns + `, (xmax = 0) AS inserted
+	`
+	var inserted bool
+	row := tx.QueryRowContext(
+		ctx, query,
+		item.ItemType, item.ItemID, item.UserID, item.Priority, item.Source,
+		item.AutoScore, item.Reason, metadata, item.SLADueAt,
+	)
+	stored, err := scanModerationItem(row, &inserted)
+	if err != nil {
+		return err
+	}
+
+	if inserted {
+		if err := insertModerationAudit(ctx, tx, stored.ID, nil, models.ModerationActionEnqueued, nil, item.Reason); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	*item = *stored
+	return nil
+}
+
+func (r *moderationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ModerationItem, error) {
+	query := `SELECT ` + moderationItemColumns + ` FROM moderation_items WHERE id = $1`
+	item, err := scanModerationItem(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrModerationItemNotFound
+	}
+	return item, err
+}
+
+func (r *moderationRepository) List(ctx context.Context, filter models.ModerationQueueFilter) ([]*models.ModerationItem, error) {
+	query := `
+		SELECT ` + moderationItemColumns + `
+		FROM moderation_items
+		WHERE ($1 = '' OR status = $1)
+		  AND ($2 = '' OR item_type = $2)
+		ORDER BY priority DESC, sla_due_at ASC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.db.QueryContext(ctx, query, filter.Status, filter.ItemType, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*models.ModerationItem{}
+	for rows.Next() {
+		item, err := scanModerationItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (r *moderationRepository) Stats(ctx context.Context) (*models.ModerationQueueStats, error) {
+	stats := &models.ModerationQueueStats{}
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE status = 'pending'),
+			COUNT(*) FILTER (WHERE status = 'claimed'),
+			COUNT(*) FILTER (WHERE status IN ('pending', 'claimed') AND sla_due_at < NOW()),
+			COUNT(*) FILTER (WHERE status = 'approved' AND decided_at > NOW() - INTERVAL '24 hours'),
+			COUNT(*) FILTER (WHERE status = 'rejected' AND decided_at > NOW() - INTERVAL '24 hours')
+		FROM moderation_items
+	`
+	err := r.db.QueryRowContext(ctx, query).Scan(
+		&stats.Pending, &stats.Claimed, &stats.Overdue, &stats.Approved, &stats.Rejected,
+	)
+	return stats, err
+}
+
+// ClaimNext atomically claims the highest priority open item. Claims whose
+// lease has expired are treated as pending so abandoned reviews are not lost.
+func (r *moderationRepository) ClaimNext(ctx context.Context, moderatorID uuid.UUID, itemType string, ttl time.Duration) (*models.ModerationItem, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE moderation_items
+		SET status = 'claimed', claimed_by = $1, claimed_at = NOW(), claim_expires_at = NOW() + $2::float8 * INTERVAL '1 second'
+		WHERE id = (
+			SELECT id FROM moderation_items
+			WHERE (status = 'pending' OR (status = 'claimed' AND claim_expires_at < NOW()))
+			  AND ($3 = '' OR item_type = $3)
+			ORDER BY priority DESC, sla_due_at ASC
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + moderationItemColumns
+	item, err := scanModerationItem(tx.QueryRowContext(ctx, query, moderatorID, ttl.Seconds(), itemType))
+	if err == sql.ErrNoRows {
+		return nil, ErrModerationQueueEmpty
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertModerationAudit(ctx, tx, item.ID, &moderatorID, models.ModerationActionClaimed, nil, nil); err != nil {
+		return nil, err
+	}
+	return item, tx.Commit()
+}
+
+func (r *moderationRepository) Claim(ctx context.Context, id, moderatorID uuid.UUID, ttl time.Duration) (*models.ModerationItem, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE moderation_items
+		SET status = 'claimed', claimed_by = $2, claimed_at = NOW(), claim_expires_at = NOW() + $3::float8 * INTERVAL '1 second'
+		WHERE id = $1
+		  AND (status = 'pending'
+		       OR (status = 'claimed' AND (claim_expires_at < NOW() OR claimed_by = $2)))
+		RETURNING ` + moderationItemColumns
+	item, err := scanModerationItem(tx.QueryRowContext(ctx, query, id, moderatorID, ttl.Seconds()))
+	if err == sql.ErrNoRows {
+		if _, getErr := r.GetByID(ctx, id); getErr != nil {
+			return nil, getErr
+		}
+		return nil, ErrModerationItemUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := insertModerationAudit(ctx, tx, item.ID, &moderatorID, models.ModerationActionClaimed, nil, nil); err != nil {
+		return nil, err
+	}
+	return item, tx.Commit()
+}
+
+func (r *moderationRepository) Release(ctx context.Context, id, moderatorID uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE moderation_items
+		SET status = 'pending', claimed_by = NULL, claimed_at = NULL, claim_expires_at = NULL
+		WHERE id = $1 AND status = 'claimed' AND claimed_by = $2
+	`
+	result, err := tx.ExecContext(ctx, query, id, moderatorID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		if _, getErr := r.GetByID(ctx, id); getErr != nil {
+			return getErr
+		}
+		return ErrModerationItemNotClaimed
+	}
+
+	if err := insertModerationAudit(ctx, tx, id, &moderatorID, models.ModerationActionReleased, nil, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Decide records an approve/reject decision on an item the moderator holds a
+// live claim on, and propagates the outcome to the reviewed entity.
+func (r *moderationRepository) Decide(ctx context.Context, id, moderatorID uuid.UUID, status, reasonCode, notes string) (*models.ModerationItem, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE moderation_items
+		SET status = $3, decision_reason_code = $4, decision_notes = NULLIF($5, ''),
+		    decided_by = $2, decided_at = NOW(), claim_expires_at = NULL
+		WHERE id = $1 AND status = 'claimed' AND claimed_by = $2 AND claim_expires_at > NOW()
+		RETURNING ` + moderationItemColumns
+	item, err := scanModerationItem(tx.QueryRowContext(ctx, query, id, moderatorID, status, reasonCode, notes))
+	if err == sql.ErrNoRows {
+		if _, getErr := r.GetByID(ctx, id); getErr != nil {
+			return nil, getErr
+		}
+		return nil, ErrModerationItemNotClaimed
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyModerationDecision(ctx, tx, item); err != nil {
+		return nil, err
+	}
+
+	action := models.ModerationActionApproved
+	if status == models.ModerationStatusRejected {
+		action = models.ModerationActionRejected
+	}
+	var notesPtr *string
+	if notes != "" {
+		notesPtr = &notes
+	}
+	if err := insertModerationAudit(ctx, tx, item.ID, &moderatorID, action, &reasonCode, notesPtr); err != nil {
+		return nil, err
+	}
+	return item, tx.Commit()
+}
+
+func (r *moderationRepository) ListAudit(ctx context.Context, id uuid.UUID) ([]*models.ModerationAuditEntry, error) {
+	query := `
+		SELECT id, moderation_item_id, actor_id, action, reason_code, notes, created_at
+		FROM moderation_audit_log
+		WHERE moderation_item_id = $1
+		ORDER BY created_at ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*models.ModerationAuditEntry{}
+	for rows.Next() {
+		entry := &models.ModerationAuditEntry{}
+		if err := rows.Scan(
+			&entry.ID, &entry.ModerationItemID, &entry.ActorID, &entry.Action,
+			&entry.ReasonCode, &entry.Notes, &entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// applyModerationDecision feeds the decision back into the reviewed entity.
+func applyModerationDecision(ctx context.Context, tx *sql.Tx, item *models.ModerationItem) error {
+	rejected := item.Status == models.ModerationStatusRejected
+
+	switch item.ItemType {
+	case models.ModerationItemVideo:
+		videoStatus := "verified"
+		var rejectionReason *string
+		if rejected {
+			videoStatus = "rejected"
+			reason := models.ModerationReasonCodes[*item.DecisionReasonCode]
+			if item.DecisionNotes != nil {
+				reason = reason + ": " + *item.DecisionNotes
+			}
+			rejectionReason = &reason
+		}
+		query := `
+			UPDATE videos
+			SET status = $1, rejection_reason = $2, processed_at = NOW()
+			WHERE id = $3 AND deleted_at IS NULL
+		`
+		_, err := tx.ExecContext(ctx, query, videoStatus, rejectionReason, item.ItemID)
+		return err
+	case models.ModerationItemBio:
+		// An approved bio replaces the one on the profile; a rejected one is
+		// dropped and the profile keeps its previous bio
+		query := `
+			UPDATE users SET bio = pending_bio, pending_bio = NULL
+			WHERE id = $1 AND pending_bio IS NOT NULL AND deleted_at IS NULL
+		`
+		if rejected {
+			query = `UPDATE users SET pending_bio = NULL WHERE id = $1`
+		}
+		_, err := tx.ExecContext(ctx, query, item.ItemID)
+		return err
+	case models.ModerationItemMessage:
+		// An approved message reaches the recipient; a rejected one stays
+		// hidden from them and shows as deleted to its sender
+		query := `UPDATE messages SET held_at = NULL WHERE id = $1`
+		if rejected {
+			query = `UPDATE messages SET deleted_at = COALESCE(deleted_at, NOW()) WHERE id = $1`
+		}
+		_, err := tx.ExecContext(ctx, query, item.ItemID)
+		return err
+	case models.ModerationItemReport:
+		// Rejecting a report item means the reported content broke the rules
+		status := models.ReportStatusDismissed
+		if rejected {
+			status = models.ReportStatusActioned
+		}
+		query := `UPDATE reports SET status = $1 WHERE id = $2`
+		_, err := tx.ExecContext(ctx, query, status, item.ItemID)
+		return err
+	}
+	return nil
+}
+
+func insertModerationAudit(ctx context.Context, tx *sql.Tx, itemID uuid.UUID, actorID *uuid.UUID, action string, reasonCode, notes *string) error {
+	query := `
+		INSERT INTO moderation_audit_log (moderation_item_id, actor_id, action, reason_code, notes)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := tx.ExecContext(ctx, query, itemID, actorID, action, reasonCode, notes)
+	return err
+}
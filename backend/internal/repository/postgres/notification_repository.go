@@ -0,0 +1,149 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/google/uuid"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// NotificationRepository stores each user's in-app notifications.
+type NotificationRepository interface {
+	Create(ctx context.Context, n *models.Notification) error
+	// List returns a page of notifications, newest first, and the cursor of
+	// the next page if there is one
+	List(ctx context.Context, userID uuid.UUID, filter models.NotificationFilter) ([]*models.Notification, *string, error)
+	MarkRead(ctx context.Context, userID, notificationID uuid.UUID) (*models.Notification, error)
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	CountUnread(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+type notificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+const notificationColumns = `id, user_id, type, title, body, data, read_at, created_at`
+
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	n := &models.Notification{}
+	var data []byte
+	err := row.Scan(&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body, &data, &n.ReadAt, &n.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &n.Data); err != nil {
+		return nil, fmt.Errorf("failed to decode notification data: %w", err)
+	}
+	return n, nil
+}
+
+func (r *notificationRepository) Create(ctx context.Context, n *models.Notification) error {
+	data, err := json.Marshal(n.Data)
+	if err != nil {
+		return err
+	}
+	if n.Data == nil {
+		data = []byte("{}")
+	}
+
+	query := `
+		INSERT INTO notifications (user_id, type, title, body, data)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRowContext(ctx, query, n.UserID, n.Type, n.Title, n.Body, data).Scan(&n.ID, &n.CreatedAt)
+}
+
+func (r *notificationRepository) List(ctx context.Context, userID uuid.UUID, filter models.NotificationFilter) ([]*models.Notification, *string, error) {
+	conditions := []string{"user_id = $1"}
+	args := []interface{}{userID}
+
+	if filter.Cursor != "" {
+		createdAt, id, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		args = append(args, createdAt, id)
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+	if filter.UnreadOnly {
+		conditions = append(conditions, "read_at IS NULL")
+	}
+
+	// Fetch one extra row to learn whether there is a next page
+	args = append(args, filter.Limit+1)
+	query := fmt.Sprintf(`
+		SELECT %s FROM notifications
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d
+	`, notificationColumns, strings.Join(conditions, " AND "), len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	notifications := []*models.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var next *string
+	if len(notifications) > filter.Limit {
+		notifications = notifications[:filter.Limit]
+		last := notifications[len(notifications)-1]
+		cursor := encodeCursor(last.CreatedAt, last.ID)
+		next = &cursor
+	}
+	return notifications, next, nil
+}
+
+// MarkRead is idempotent: reading a notification twice keeps the first
+// read time.
+func (r *notificationRepository) MarkRead(ctx context.Context, userID, notificationID uuid.UUID) (*models.Notification, error) {
+	query := `
+		UPDATE notifications SET read_at = COALESCE(read_at, NOW())
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + notificationColumns
+	n, err := scanNotification(r.db.QueryRowContext(ctx, query, notificationID, userID))
+	if err == sql.ErrNoRows {
+		return nil, ErrNotificationNotFound
+	}
+	return n, err
+}
+
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	query := `UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&count)
+	return count, err
+}
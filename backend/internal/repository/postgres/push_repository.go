@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var ErrDeviceNotFound = errors.New("device not registered")
+
+// PushRepository stores the devices pushes are sent to and each user's
+// notification preferences.
+type PushRepository interface {
+	RegisterDevice(ctx context.Context, userID uuid.UUID, platform, token string) (*models.DeviceToken, error)
+	UnregisterDevice(ctx context.Context, userID uuid.UUID, token string) error
+	ListDevices(ctx context.Context, userID uuid.UUID) ([]*models.DeviceToken, error)
+	// DeleteTokens prunes tokens the push providers reported as invalid
+	DeleteTokens(ctx context.Context, tokens []string) error
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*models.NotificationPreferences, error)
+	SavePreferences(ctx context.Context, userID uuid.UUID, prefs *models.NotificationPreferences) (*models.NotificationPreferences, error)
+}
+
+type pushRepository struct {
+	db *sql.DB
+}
+
+func NewPushRepository(db *sql.DB) PushRepository {
+	return &pushRepository{db: db}
+}
+
+const deviceColumns = `id, user_id, platform, token, created_at, last_seen_at`
+
+func scanDevice(row rowScanner) (*models.DeviceToken, error) {
+	d := &models.DeviceToken{}
+	err := row.Scan(&d.ID, &d.UserID, &d.Platform, &d.Token, &d.CreatedAt, &d.LastSeenAt)
+	return d, err
+}
+
+// RegisterDevice is called whenever the app starts. A token already
+// registered moves to the user registering it, since the previous user of
+// the device has signed out.
+func (r *pushRepository) RegisterDevice(ctx context.Context, userID uuid.UUID, platform, token string) (*models.DeviceToken, error) {
+	query := `
+		INSERT INTO device_tokens (user_id, platform, token)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (token) DO UPDATE
+		SET user_id = EXCLUDED.user_id, platform = EXCLUDED.platform, last_seen_at = NOW()
+		RETURNING ` + deviceColumns
+	return scanDevice(r.db.QueryRowContext(ctx, query, userID, platform, token))
+}
+
+func (r *pushRepository) UnregisterDevice(ctx context.Context, userID uuid.UUID, token string) error {
+	query := `DELETE FROM device_tokens WHERE user_id = $1 AND token = $2`
+	result, err := r.db.ExecContext(ctx, query, userID, token)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrDeviceNotFound
+	}
+	return nil
+}
+
+func (r *pushRepository) ListDevices(ctx context.Context, userID uuid.UUID) ([]*models.DeviceToken, error) {
+	query := `SELECT ` + deviceColumns + ` FROM device_tokens WHERE user_id = $1 ORDER BY last_seen_at DESC`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	devices := []*models.DeviceToken{}
+	for rows.Next() {
+		d, err := scanDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, d)
+	}
+	return devices, rows.Err()
+}
+
+func (r *pushRepository) DeleteTokens(ctx context.Context, tokens []string) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	_, err := r.db.ExecContext(ctx, `DELETE FROM device_tokens WHERE token = ANY($1)`, pq.Array(tokens))
+	return err
+}
+
+const preferenceColumns = `new_match, mutual_match, match_expiring, incoming_call, call_missed,
+	verification_result, quiet_hours_start, quiet_hours_end, timezone, updated_at`
+
+func scanPreferences(row rowScanner) (*models.NotificationPreferences, error) {
+	p := &models.NotificationPreferences{}
+	var start, end sql.NullString
+	err := row.Scan(
+		&p.NewMatch, &p.MutualMatch, &p.MatchExpiring, &p.IncomingCall, &p.CallMissed,
+		&p.VerificationResult, &start, &end, &p.Timezone, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	p.QuietHoursStart = clockPtr(start)
+	p.QuietHoursEnd = clockPtr(end)
+	return p, nil
+}
+
+// clockPtr trims a Postgres TIME ("22:00:00") to "HH:MM".
+func clockPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	clock := s.String
+	if len(clock) > 5 {
+		clock = clock[:5]
+	}
+	return &clock
+}
+
+// GetPreferences returns the defaults for users who never saved any.
+func (r *pushRepository) GetPreferences(ctx context.Context, userID uuid.UUID) (*models.NotificationPreferences, error) {
+	query := `SELECT ` + preferenceColumns + ` FROM notification_preferences WHERE user_id = $1`
+	prefs, err := scanPreferences(r.db.QueryRowContext(ctx, query, userID))
+	if err == sql.ErrNoRows {
+		return models.DefaultNotificationPreferences(), nil
+	}
+	return prefs, err
+}
+
+func (r *pushRepository) SavePreferences(ctx context.Context, userID uuid.UUID, p *models.NotificationPreferences) (*models.NotificationPreferences, error) {
+	query := `
+		INSERT INTO notification_preferences (
+			user_id, new_match, mutual_match, match_expiring, incoming_call, call_missed,
+			verification_result, quiet_hours_start, quiet_hours_end, timezone
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (user_id) DO UPDATE SET
+			new_match = EXCLUDED.new_match,
+			mutual_match = EXCLUDED.mutual_match,
+			match_expiring = EXCLUDED.match_expiring,
+			incoming_call = EXCLUDED.incoming_call,
+			call_missed = EXCLUDED.call_missed,
+			verification_result = EXCLUDED.verification_result,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			timezone = EXCLUDED.timezone
+		RETURNING ` + preferenceColumns
+	return scanPreferences(r.db.QueryRowContext(ctx, query,
+		userID, p.NewMatch, p.MutualMatch, p.MatchExpiring, p.IncomingCall, p.CallMissed,
+		p.VerificationResult, p.QuietHoursStart, p.QuietHoursEnd, p.Timezone,
+	))
+}
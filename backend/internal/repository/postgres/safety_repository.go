@@ -0,0 +1,171 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrSafetyTargetNotFound = errors.New("user not found")
+	ErrBlockNotFound        = errors.New("user is not blocked")
+	ErrAlreadyReported      = errors.New("user already has an open report from you")
+)
+
+// uniqueViolation is the Postgres error code for a unique constraint failure.
+const uniqueViolation = "23505"
+
+// SafetyRepository stores blocks between users and reports against them.
+type SafetyRepository interface {
+	Block(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	Unblock(ctx context.Context, blockerID, blockedID uuid.UUID) error
+	ListBlocks(ctx context.Context, blockerID uuid.UUID) ([]*models.BlockedUser, error)
+	IsBlocked(ctx context.Context, a, b uuid.UUID) (bool, error)
+	CreateReport(ctx context.Context, report *models.Report) error
+	DeleteReport(ctx context.Context, id uuid.UUID) error
+	CountReporters(ctx context.Context, reportedID uuid.UUID, since time.Time, categories []string) (int, error)
+}
+
+type safetyRepository struct {
+	db *sql.DB
+}
+
+func NewSafetyRepository(db *sql.DB) SafetyRepository {
+	return &safetyRepository{db: db}
+}
+
+// Block is idempotent: blocking someone twice keeps the original block.
+func (r *safetyRepository) Block(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	query := `
+		INSERT INTO blocks (blocker_id, blocked_id)
+		SELECT $1, id FROM users WHERE id = $2 AND deleted_at IS NULL
+		ON CONFLICT (blocker_id, blocked_id) DO NOTHING
+	`
+	if _, err := r.db.ExecContext(ctx, query, blockerID, blockedID); err != nil {
+		return err
+	}
+	return r.ensureBlocked(ctx, blockerID, blockedID)
+}
+
+// ensureBlocked tells apart a block that exists from a target that does not.
+func (r *safetyRepository) ensureBlocked(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM blocks WHERE blocker_id = $1 AND blocked_id = $2)`
+	if err := r.db.QueryRowContext(ctx, query, blockerID, blockedID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return ErrSafetyTargetNotFound
+	}
+	return nil
+}
+
+func (r *safetyRepository) Unblock(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	query := `DELETE FROM blocks WHERE blocker_id = $1 AND blocked_id = $2`
+	result, err := r.db.ExecContext(ctx, query, blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrBlockNotFound
+	}
+	return nil
+}
+
+func (r *safetyRepository) ListBlocks(ctx context.Context, blockerID uuid.UUID) ([]*models.BlockedUser, error) {
+	query := `
+		SELECT u.id, u.full_name, b.created_at
+		FROM blocks b
+		JOIN users u ON u.id = b.blocked_id
+		WHERE b.blocker_id = $1
+		ORDER BY b.created_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, blockerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocked := []*models.BlockedUser{}
+	for rows.Next() {
+		b := &models.BlockedUser{}
+		if err := rows.Scan(&b.UserID, &b.FullName, &b.BlockedAt); err != nil {
+			return nil, err
+		}
+		blocked = append(blocked, b)
+	}
+	return blocked, rows.Err()
+}
+
+// IsBlocked reports whether either user has blocked the other.
+func (r *safetyRepository) IsBlocked(ctx context.Context, a, b uuid.UUID) (bool, error) {
+	var blocked bool
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM blocks
+			WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1)
+		)
+	`
+	err := r.db.QueryRowContext(ctx, query, a, b).Scan(&blocked)
+	return blocked, err
+}
+
+func (r *safetyRepository) CreateReport(ctx context.Context, report *models.Report) error {
+	if report.Evidence == nil {
+		report.Evidence = []models.ReportEvidence{}
+	}
+	evidence, err := json.Marshal(report.Evidence)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO reports (reporter_id, reported_id, category, description, evidence, match_id, call_id)
+		SELECT $1, id, $3, $4, $5, $6, $7 FROM users WHERE id = $2 AND deleted_at IS NULL
+		RETURNING id, status, created_at, updated_at
+	`
+	err = r.db.QueryRowContext(
+		ctx, query,
+		report.ReporterID, report.ReportedID, report.Category, report.Description,
+		evidence, report.MatchID, report.CallID,
+	).Scan(&report.ID, &report.Status, &report.CreatedAt, &report.UpdatedAt)
+
+	var pqErr *pq.Error
+	switch {
+	case err == sql.ErrNoRows:
+		return ErrSafetyTargetNotFound
+	case errors.As(err, &pqErr) && pqErr.Code == uniqueViolation:
+		return ErrAlreadyReported
+	}
+	return err
+}
+
+// DeleteReport removes a report that never reached the moderation queue.
+func (r *safetyRepository) DeleteReport(ctx context.Context, id uuid.UUID) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM reports WHERE id = $1`, id)
+	return err
+}
+
+// CountReporters returns how many distinct users reported the user since the
+// given time, ignoring dismissed reports. Empty categories count all.
+func (r *safetyRepository) CountReporters(ctx context.Context, reportedID uuid.UUID, since time.Time, categories []string) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(DISTINCT reporter_id)
+		FROM reports
+		WHERE reported_id = $1 AND created_at >= $2 AND status != 'dismissed'
+		  AND (COALESCE(cardinality($3::varchar[]), 0) = 0 OR category = ANY($3))
+	`
+	err := r.db.QueryRowContext(ctx, query, reportedID, since, pq.Array(categories)).Scan(&count)
+	return count, err
+}
@@ -3,12 +3,25 @@ package postgres
 import (
 	"context"
 	"database/sql"
-	"fmt"
+	"errors"
+	"strings"
 
 	"github.com/alexcolls/findme/internal/domain/models"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrEmailTaken   = errors.New("email already registered")
+	// ErrUserTokenInvalid covers verification and password reset tokens
+	// that are unknown or expired.
+	ErrUserTokenInvalid = errors.New("invalid or expired token")
+)
+
+// usersEmailKey is the unique constraint on users.email.
+const usersEmailKey = "users_email_key"
+
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
@@ -18,6 +31,7 @@ type UserRepository interface {
 	UpdateLastLogin(ctx context.Context, id uuid.UUID) error
 	SetEmailVerificationToken(ctx context.Context, id uuid.UUID, token string, expiresAt sql.NullTime) error
 	VerifyEmail(ctx context.Context, token string) error
+	SetPendingBio(ctx context.Context, id uuid.UUID, bio *string) error
 	SetPasswordResetToken(ctx context.Context, email string, token string, expiresAt sql.NullTime) error
 	ResetPassword(ctx context.Context, token string, passwordHash string) error
 }
@@ -34,31 +48,38 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 	query := `
 		INSERT INTO users (email, password_hash, full_name, date_of_birth, gender, bio, verified, active)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, created_at, updated_at
+		RETURNING id, role, token_version, created_at, updated_at
 	`
-	return r.db.QueryRowContext(
+	err := r.db.QueryRowContext(
 		ctx, query,
 		user.Email, user.PasswordHash, user.FullName, user.DateOfBirth,
 		user.Gender, user.Bio, user.Verified, user.Active,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	).Scan(&user.ID, &user.Role, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == usersEmailKey {
+		return ErrEmailTaken
+	}
+	return err
 }
 
 func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, email, password_hash, full_name, date_of_birth, gender, bio,
-		       video_id, verified, last_login_at, active, created_at, updated_at
+		SELECT id, email, password_hash, full_name, date_of_birth, gender, bio, pending_bio, interests,
+		       video_id, verified, last_login_at, active, role, token_version,
+		       created_at, updated_at
 		FROM users
 		WHERE id = $1 AND deleted_at IS NULL
 	`
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID, &user.Email, &user.PasswordHash, &user.FullName,
-		&user.DateOfBirth, &user.Gender, &user.Bio, &user.VideoID,
+		&user.DateOfBirth, &user.Gender, &user.Bio, &user.PendingBio, pq.Array(&user.Interests), &user.VideoID,
 		&user.Verified, &user.LastLoginAt, &user.Active,
-		&user.CreatedAt, &user.UpdatedAt,
+		&user.Role, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
+		return nil, ErrUserNotFound
 	}
 	return user, err
 }
@@ -66,31 +87,57 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Use
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	user := &models.User{}
 	query := `
-		SELECT id, email, password_hash, full_name, date_of_birth, gender, bio,
-		       video_id, verified, last_login_at, active, created_at, updated_at
+		SELECT id, email, password_hash, full_name, date_of_birth, gender, bio, pending_bio, interests,
+		       video_id, verified, last_login_at, active, role, token_version,
+		       created_at, updated_at
 		FROM users
 		WHERE email = $1 AND deleted_at IS NULL
 	`
 	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID, &user.Email, &user.PasswordHash, &user.FullName,
-		&user.DateOfBirth, &user.Gender, &user.Bio, &user.VideoID,
+		&user.DateOfBirth, &user.Gender, &user.Bio, &user.PendingBio, pq.Array(&user.Interests), &user.VideoID,
 		&user.Verified, &user.LastLoginAt, &user.Active,
-		&user.CreatedAt, &user.UpdatedAt,
+		&user.Role, &user.TokenVersion, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("user not found")
+		return nil, ErrUserNotFound
 	}
 	return user, err
 }
 
+// Update saves the editable profile fields. A changed bio replaces any bio
+// still awaiting moderation.
 func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 	query := `
 		UPDATE users
-		SET full_name = $1, bio = $2, updated_at = NOW()
-		WHERE id = $3 AND deleted_at IS NULL
+		SET full_name = $1, bio = $2, interests = $3, updated_at = NOW(),
+		    pending_bio = CASE WHEN bio IS DISTINCT FROM $2 THEN NULL ELSE pending_bio END
+		WHERE id = $4 AND deleted_at IS NULL
 		RETURNING updated_at
 	`
-	return r.db.QueryRowContext(ctx, query, user.FullName, user.Bio, user.ID).Scan(&user.UpdatedAt)
+	err := r.db.QueryRowContext(ctx, query, user.FullName, user.Bio, pq.Array(normalizeInterests(user.Interests)), user.ID).Scan(&user.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// SetPendingBio stores a bio held for moderation without changing the one
+// shown on the profile; nil clears it.
+func (r *userRepository) SetPendingBio(ctx context.Context, id uuid.UUID, bio *string) error {
+	query := `UPDATE users SET pending_bio = $1, updated_at = NOW() WHERE id = $2 AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query, bio, id)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+	return nil
 }
 
 func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
@@ -130,7 +177,7 @@ func (r *userRepository) VerifyEmail(ctx context.Context, token string) error {
 		return err
 	}
 	if rows == 0 {
-		return fmt.Errorf("invalid or expired token")
+		return ErrUserTokenInvalid
 	}
 	return nil
 }
@@ -160,7 +207,23 @@ func (r *userRepository) ResetPassword(ctx context.Context, token string, passwo
 		return err
 	}
 	if rows == 0 {
-		return fmt.Errorf("invalid or expired token")
+		return ErrUserTokenInvalid
 	}
 	return nil
 }
+
+// normalizeInterests lowercases and trims interest tags and drops blanks and
+// duplicates, so shared interests can be compared exactly.
+func normalizeInterests(interests []string) []string {
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, interest := range interests {
+		interest = strings.ToLower(strings.TrimSpace(interest))
+		if interest == "" || seen[interest] {
+			continue
+		}
+		seen[interest] = true
+		normalized = append(normalized, interest)
+	}
+	return normalized
+}
@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/alexcolls/findme/internal/repository/postgres")
+
+// tracedUserRepository records a client span for every query of the
+// repository it wraps.
+type tracedUserRepository struct {
+	UserRepository
+}
+
+// TraceUsers wraps repo so each query shows up as a child span of the
+// request or job that made it.
+func TraceUsers(repo UserRepository) UserRepository {
+	return &tracedUserRepository{UserRepository: repo}
+}
+
+func startUserSpan(ctx context.Context, method, operation string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "UserRepository."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "postgresql"),
+			attribute.String("db.operation.name", operation),
+			attribute.String("db.collection.name", "users"),
+		),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (r *tracedUserRepository) Create(ctx context.Context, user *models.User) (err error) {
+	ctx, span := startUserSpan(ctx, "Create", "INSERT")
+	defer func() { endSpan(span, err) }()
+	return r.UserRepository.Create(ctx, user)
+}
+
+func (r *tracedUserRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *models.User, err error) {
+	ctx, span := startUserSpan(ctx, "GetByID", "SELECT")
+	defer func() { endSpan(span, err) }()
+	return r.UserRepository.GetByID(ctx, id)
+}
+
+func (r *tracedUserRepository) GetByEmail(ctx context.Context, email string) (_ *models.User, err error) {
+	ctx, span := startUserSpan(ctx, "GetByEmail", "SELECT")
+	defer func() { endSpan(span, err) }()
+	return r.UserRepository.GetByEmail(ctx, email)
+}
+
+func (r *tracedUserRepository) Update(ctx context.Context, user *models.User) (err error) {
+	ctx, span := startUserSpan(ctx, "Update", "UPDATE")
+	defer func() { endSpan(span, err) }()
+	return r.UserRepository.Update(ctx, user)
+}
+
+func (r *tracedUserRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startUserSpan(ctx, "Delete", "DELETE")
+	defer func() { endSpan(span, err) }()
+	return r.UserRepository.Delete(ctx, id)
+}
+
+func (r *tracedUserRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startUserSpan(ctx, "UpdateLastLogin", "UPDATE")
+	defer func() { endSpan(span, err) }()
+	return r.UserRepository.UpdateLastLogin(ctx, id)
+}
+
+func (r *tracedUserRepository) SetEmailVerificationToken(ctx context.Context, id uuid.UUID, token string, expiresAt sql.NullTime) (err error) {
+	ctx, span := startUserSpan(ctx, "SetEmailVerificationToken", "UPDATE")
+	defer func() { endSpan(span, err) }()
+	return r.UserRepository.SetEmailVerificationToken(ctx, id, token, expiresAt)
+}
+
+func (r *tracedUserRepository) VerifyEmail(ctx context.Context, token string) (err error) {
+	ctx, span := startUserSpan(ctx, "VerifyEmail", "UPDATE")
+	defer func() { endSpan(span, err) }()
+	return r.UserRepository.VerifyEmail(ctx, token)
+}
+
+func (r *tracedUserRepository) SetPendingBio(ctx context.Context, id uuid.UUID, bio *string) (err error) {
+	ctx, span := startUserSpan(ctx, "SetPendingBio", "UPDATE")
+	defer func() { endSpan(span, err) }()
+	return r.UserRepository.SetPendingBio(ctx, id, bio)
+}
+
+func (r *tracedUserRepository) SetPasswordResetToken(ctx context.Context, email string, token string, expiresAt sql.NullTime) (err error) {
+	ctx, span := startUserSpan(ctx, "SetPasswordResetToken", "UPDATE")
+	defer func() { endSpan(span, err) }()
+	return r.UserRepository.SetPasswordResetToken(ctx, email, token, expiresAt)
+}
+
+func (r *tracedUserRepository) ResetPassword(ctx context.Context, token string, passwordHash string) (err error) {
+	ctx, span := startUserSpan(ctx, "ResetPassword", "UPDATE")
+	defer func() { endSpan(span, err) }()
+	return r.UserRepository.ResetPassword(ctx, token, passwordHash)
+}
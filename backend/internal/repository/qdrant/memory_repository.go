@@ -0,0 +1,188 @@
+package qdrant
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// MemoryProfileRepository is an in-memory ProfileRepository with the same
+// filtering semantics as the Qdrant implementation. It is meant for tests and
+// local development without a Qdrant server.
+type MemoryProfileRepository struct {
+	mu     sync.RWMutex
+	points map[uuid.UUID]*ProfilePoint
+	// Err, when set, is returned by every call to simulate an outage.
+	Err error
+}
+
+func NewMemoryProfileRepository() *MemoryProfileRepository {
+	return &MemoryProfileRepository{points: map[uuid.UUID]*ProfilePoint{}}
+}
+
+func (r *MemoryProfileRepository) EnsureCollection(ctx context.Context, dimension int) error {
+	return r.Err
+}
+
+func (r *MemoryProfileRepository) Upsert(ctx context.Context, points []*ProfilePoint) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range points {
+		copied := *p
+		r.points[p.UserID] = &copied
+	}
+	return nil
+}
+
+func (r *MemoryProfileRepository) Delete(ctx context.Context, userIDs []uuid.UUID) error {
+	if r.Err != nil {
+		return r.Err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range userIDs {
+		delete(r.points, id)
+	}
+	return nil
+}
+
+func (r *MemoryProfileRepository) ListUserIDs(ctx context.Context) ([]uuid.UUID, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]uuid.UUID, 0, len(r.points))
+	for id := range r.points {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (r *MemoryProfileRepository) GetVector(ctx context.Context, userID uuid.UUID) ([]float32, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.points[userID]
+	if !ok {
+		return nil, ErrProfileNotIndexed
+	}
+	return p.Vector, nil
+}
+
+func (r *MemoryProfileRepository) GetVectors(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]float32, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	vectors := map[uuid.UUID][]float32{}
+	for _, id := range userIDs {
+		if p, ok := r.points[id]; ok {
+			vectors[id] = p.Vector
+		}
+	}
+	return vectors, nil
+}
+
+func (r *MemoryProfileRepository) Search(ctx context.Context, search *ProfileSearch) ([]*ScoredProfile, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	excluded := map[uuid.UUID]bool{}
+	for _, id := range search.ExcludeIDs {
+		excluded[id] = true
+	}
+	genders := map[string]bool{}
+	for _, g := range search.Genders {
+		genders[g] = true
+	}
+
+	hits := []*ScoredProfile{}
+	for _, p := range r.points {
+		if !p.Active || !p.Verified || !p.VideoVerified || excluded[p.UserID] {
+			continue
+		}
+		if len(genders) > 0 && !genders[p.Gender] {
+			continue
+		}
+		earliest, latest := BirthDateRange(search.MinAge, search.MaxAge, search.at())
+		if (!latest.IsZero() && p.DateOfBirth.After(latest)) || (!earliest.IsZero() && !p.DateOfBirth.After(earliest)) {
+			continue
+		}
+		if search.RadiusKm > 0 && search.Latitude != nil && search.Longitude != nil {
+			if p.Latitude == nil || p.Longitude == nil {
+				continue
+			}
+			if haversineKm(*search.Latitude, *search.Longitude, *p.Latitude, *p.Longitude) > float64(search.RadiusKm) {
+				continue
+			}
+		}
+
+		score := cosineSimilarity(search.Vector, p.Vector)
+		if search.ScoreThreshold > 0 && score < search.ScoreThreshold {
+			continue
+		}
+		hits = append(hits, &ScoredProfile{
+			UserID:      p.UserID,
+			Score:       score,
+			Gender:      p.Gender,
+			DateOfBirth: p.DateOfBirth,
+			Latitude:    p.Latitude,
+			Longitude:   p.Longitude,
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].UserID.String() < hits[j].UserID.String()
+	})
+
+	if search.Offset >= len(hits) {
+		return []*ScoredProfile{}, nil
+	}
+	hits = hits[search.Offset:]
+	if search.Limit > 0 && len(hits) > search.Limit {
+		hits = hits[:search.Limit]
+	}
+	return hits, nil
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadiusKm = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a))
+}
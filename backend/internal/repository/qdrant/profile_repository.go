@@ -0,0 +1,409 @@
+package qdrant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	goqdrant "github.com/qdrant/go-client/qdrant"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var ErrProfileNotIndexed = errors.New("profile has no embedding yet")
+
+// scrollPageSize is the number of point IDs fetched per page when listing
+// the collection.
+const scrollPageSize = 256
+
+// ProfilePoint is a user's profile embedding together with the payload used
+// for filtering candidate searches. The date of birth is stored rather than
+// the age, which would go stale on the user's birthday.
+type ProfilePoint struct {
+	UserID        uuid.UUID
+	Vector        []float32
+	Gender        string
+	DateOfBirth   time.Time
+	Active        bool
+	Verified      bool
+	VideoVerified bool
+	Latitude      *float64
+	Longitude     *float64
+}
+
+// ProfileSearch describes a nearest-neighbour query over profile embeddings.
+// Only active, verified profiles with a verified video are returned, as in
+// the Postgres candidate search. Ages are computed at At, or now when it is
+// zero. Zero values disable the corresponding filter.
+type ProfileSearch struct {
+	Vector         []float32
+	Genders        []string
+	MinAge         int
+	MaxAge         int
+	Latitude       *float64
+	Longitude      *float64
+	RadiusKm       int
+	ExcludeIDs     []uuid.UUID
+	ScoreThreshold float64
+	Limit          int
+	Offset         int
+	At             time.Time
+}
+
+// ScoredProfile is a search hit, with the cosine similarity as score.
+type ScoredProfile struct {
+	UserID      uuid.UUID
+	Score       float64
+	Gender      string
+	DateOfBirth time.Time
+	Latitude    *float64
+	Longitude   *float64
+}
+
+// BirthDateRange returns the dates of birth of people aged between minAge
+// and maxAge at the given time: born after earliest and on or before latest.
+// A zero age leaves that end open, returned as a zero time.
+func BirthDateRange(minAge, maxAge int, at time.Time) (earliest, latest time.Time) {
+	today := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	if minAge > 0 {
+		latest = today.AddDate(-minAge, 0, 0)
+	}
+	if maxAge > 0 {
+		earliest = today.AddDate(-maxAge-1, 0, 0)
+	}
+	return earliest, latest
+}
+
+func (s *ProfileSearch) at() time.Time {
+	if s.At.IsZero() {
+		return time.Now()
+	}
+	return s.At
+}
+
+// ProfileRepository stores profile embeddings in a Qdrant collection, keyed
+// by user ID.
+type ProfileRepository interface {
+	EnsureCollection(ctx context.Context, dimension int) error
+	Upsert(ctx context.Context, points []*ProfilePoint) error
+	Delete(ctx context.Context, userIDs []uuid.UUID) error
+	ListUserIDs(ctx context.Context) ([]uuid.UUID, error)
+	GetVector(ctx context.Context, userID uuid.UUID) ([]float32, error)
+	GetVectors(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]float32, error)
+	Search(ctx context.Context, search *ProfileSearch) ([]*ScoredProfile, error)
+}
+
+type profileRepository struct {
+	client     *goqdrant.Client
+	collection string
+}
+
+func NewProfileRepository(client *goqdrant.Client, collection string) ProfileRepository {
+	return &profileRepository{
+		client:     client,
+		collection: collection,
+	}
+}
+
+// EnsureCollection creates the collection and its payload indexes if they do
+// not exist yet.
+func (r *profileRepository) EnsureCollection(ctx context.Context, dimension int) error {
+	exists, err := r.client.CollectionExists(ctx, r.collection)
+	if err != nil {
+		return fmt.Errorf("failed to check collection %s: %w", r.collection, err)
+	}
+
+	if !exists {
+		slog.InfoContext(ctx, "Creating Qdrant collection", "collection", r.collection)
+		err := r.client.CreateCollection(ctx, &goqdrant.CreateCollection{
+			CollectionName: r.collection,
+			VectorsConfig: goqdrant.NewVectorsConfig(&goqdrant.VectorParams{
+				Size:     uint64(dimension),
+				Distance: goqdrant.Distance_Cosine,
+			}),
+			OptimizersConfig: &goqdrant.OptimizersConfigDiff{
+				IndexingThreshold: goqdrant.PtrOf(uint64(10000)),
+			},
+			HnswConfig: &goqdrant.HnswConfigDiff{
+				M:                 goqdrant.PtrOf(uint64(16)),
+				EfConstruct:       goqdrant.PtrOf(uint64(100)),
+				FullScanThreshold: goqdrant.PtrOf(uint64(10000)),
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create collection %s: %w", r.collection, err)
+		}
+	}
+
+	// Create payload indexes for filtering
+	indexes := []struct {
+		field string
+		ftype goqdrant.FieldType
+	}{
+		{"user_id", goqdrant.FieldType_FieldTypeKeyword},
+		{"gender", goqdrant.FieldType_FieldTypeKeyword},
+		{"date_of_birth", goqdrant.FieldType_FieldTypeDatetime},
+		{"active", goqdrant.FieldType_FieldTypeBool},
+		{"verified", goqdrant.FieldType_FieldTypeBool},
+		{"video_verified", goqdrant.FieldType_FieldTypeBool},
+		{"location", goqdrant.FieldType_FieldTypeGeo},
+	}
+
+	for _, idx := range indexes {
+		_, err := r.client.CreateFieldIndex(ctx, &goqdrant.CreateFieldIndexCollection{
+			CollectionName: r.collection,
+			Wait:           goqdrant.PtrOf(true),
+			FieldName:      idx.field,
+			FieldType:      goqdrant.PtrOf(idx.ftype),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create index for %s: %w", idx.field, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *profileRepository) Upsert(ctx context.Context, points []*ProfilePoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	structs := make([]*goqdrant.PointStruct, len(points))
+	for i, p := range points {
+		payload := map[string]any{
+			"user_id":        p.UserID.String(),
+			"gender":         p.Gender,
+			"date_of_birth":  p.DateOfBirth.Format(time.RFC3339),
+			"active":         p.Active,
+			"verified":       p.Verified,
+			"video_verified": p.VideoVerified,
+		}
+		if p.Latitude != nil && p.Longitude != nil {
+			payload["location"] = map[string]any{"lat": *p.Latitude, "lon": *p.Longitude}
+		}
+		structs[i] = &goqdrant.PointStruct{
+			Id:      goqdrant.NewID(p.UserID.String()),
+			Vectors: goqdrant.NewVectorsDense(p.Vector),
+			Payload: goqdrant.NewValueMap(payload),
+		}
+	}
+
+	_, err := r.client.Upsert(ctx, &goqdrant.UpsertPoints{
+		CollectionName: r.collection,
+		Wait:           goqdrant.PtrOf(true),
+		Points:         structs,
+	})
+	return err
+}
+
+func (r *profileRepository) Delete(ctx context.Context, userIDs []uuid.UUID) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	ids := make([]*goqdrant.PointId, len(userIDs))
+	for i, id := range userIDs {
+		ids[i] = goqdrant.NewID(id.String())
+	}
+
+	_, err := r.client.Delete(ctx, &goqdrant.DeletePoints{
+		CollectionName: r.collection,
+		Wait:           goqdrant.PtrOf(true),
+		Points:         goqdrant.NewPointsSelectorIDs(ids),
+	})
+	return err
+}
+
+// ListUserIDs returns the IDs of every point in the collection.
+func (r *profileRepository) ListUserIDs(ctx context.Context) ([]uuid.UUID, error) {
+	var (
+		userIDs []uuid.UUID
+		offset  *goqdrant.PointId
+	)
+	for {
+		points, next, err := r.client.ScrollAndOffset(ctx, &goqdrant.ScrollPoints{
+			CollectionName: r.collection,
+			Offset:         offset,
+			Limit:          goqdrant.PtrOf(uint32(scrollPageSize)),
+			WithPayload:    goqdrant.NewWithPayload(false),
+			WithVectors:    goqdrant.NewWithVectors(false),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, point := range points {
+			id, err := uuid.Parse(point.GetId().GetUuid())
+			if err != nil {
+				continue
+			}
+			userIDs = append(userIDs, id)
+		}
+
+		if next == nil {
+			return userIDs, nil
+		}
+		offset = next
+	}
+}
+
+func (r *profileRepository) GetVector(ctx context.Context, userID uuid.UUID) ([]float32, error) {
+	points, err := r.client.Get(ctx, &goqdrant.GetPoints{
+		CollectionName: r.collection,
+		Ids:            []*goqdrant.PointId{goqdrant.NewID(userID.String())},
+		WithPayload:    goqdrant.NewWithPayload(false),
+		WithVectors:    goqdrant.NewWithVectors(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(points) == 0 {
+		return nil, ErrProfileNotIndexed
+	}
+
+	if vector := denseVector(points[0]); vector != nil {
+		return vector, nil
+	}
+	return nil, ErrProfileNotIndexed
+}
+
+// GetVectors fetches the embeddings of many users in pages. Users without an
+// embedding are missing from the result.
+func (r *profileRepository) GetVectors(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]float32, error) {
+	vectors := make(map[uuid.UUID][]float32, len(userIDs))
+	for start := 0; start < len(userIDs); start += scrollPageSize {
+		end := min(start+scrollPageSize, len(userIDs))
+
+		ids := make([]*goqdrant.PointId, 0, end-start)
+		for _, id := range userIDs[start:end] {
+			ids = append(ids, goqdrant.NewID(id.String()))
+		}
+		points, err := r.client.Get(ctx, &goqdrant.GetPoints{
+			CollectionName: r.collection,
+			Ids:            ids,
+			WithPayload:    goqdrant.NewWithPayload(false),
+			WithVectors:    goqdrant.NewWithVectors(true),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, point := range points {
+			id, err := uuid.Parse(point.GetId().GetUuid())
+			if err != nil {
+				continue
+			}
+			if vector := denseVector(point); vector != nil {
+				vectors[id] = vector
+			}
+		}
+	}
+	return vectors, nil
+}
+
+func denseVector(point *goqdrant.RetrievedPoint) []float32 {
+	vector := point.GetVectors().GetVector()
+	if dense := vector.GetDense(); dense != nil {
+		return dense.GetData()
+	}
+	if data := vector.GetData(); len(data) > 0 {
+		return data
+	}
+	return nil
+}
+
+func (r *profileRepository) Search(ctx context.Context, search *ProfileSearch) ([]*ScoredProfile, error) {
+	filter := &goqdrant.Filter{
+		Must: []*goqdrant.Condition{
+			goqdrant.NewMatchBool("active", true),
+			goqdrant.NewMatchBool("verified", true),
+			goqdrant.NewMatchBool("video_verified", true),
+		},
+	}
+	if len(search.Genders) > 0 {
+		filter.Must = append(filter.Must, goqdrant.NewMatchKeywords("gender", search.Genders...))
+	}
+	if search.MinAge > 0 || search.MaxAge > 0 {
+		earliest, latest := BirthDateRange(search.MinAge, search.MaxAge, search.at())
+		born := &goqdrant.DatetimeRange{}
+		if !earliest.IsZero() {
+			born.Gt = timestamppb.New(earliest)
+		}
+		if !latest.IsZero() {
+			born.Lte = timestamppb.New(latest)
+		}
+		filter.Must = append(filter.Must, goqdrant.NewDatetimeRange("date_of_birth", born))
+	}
+	if search.RadiusKm > 0 && search.Latitude != nil && search.Longitude != nil {
+		filter.Must = append(filter.Must, goqdrant.NewGeoRadius(
+			"location", *search.Latitude, *search.Longitude, float32(search.RadiusKm*1000),
+		))
+	}
+	if len(search.ExcludeIDs) > 0 {
+		ids := make([]*goqdrant.PointId, len(search.ExcludeIDs))
+		for i, id := range search.ExcludeIDs {
+			ids[i] = goqdrant.NewID(id.String())
+		}
+		filter.MustNot = append(filter.MustNot, goqdrant.NewHasID(ids...))
+	}
+
+	query := &goqdrant.QueryPoints{
+		CollectionName: r.collection,
+		Query:          goqdrant.NewQueryDense(search.Vector),
+		Filter:         filter,
+		Limit:          goqdrant.PtrOf(uint64(search.Limit)),
+		Offset:         goqdrant.PtrOf(uint64(search.Offset)),
+		WithPayload:    goqdrant.NewWithPayloadInclude("gender", "date_of_birth", "location"),
+	}
+	if search.ScoreThreshold > 0 {
+		query.ScoreThreshold = goqdrant.PtrOf(float32(search.ScoreThreshold))
+	}
+
+	points, err := r.client.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*ScoredProfile, 0, len(points))
+	for _, point := range points {
+		id, err := uuid.Parse(point.GetId().GetUuid())
+		if err != nil {
+			continue
+		}
+		payload := point.GetPayload()
+		hit := &ScoredProfile{
+			UserID: id,
+			Score:  float64(point.GetScore()),
+			Gender: payload["gender"].GetStringValue(),
+		}
+		if dob, err := time.Parse(time.RFC3339, payload["date_of_birth"].GetStringValue()); err == nil {
+			hit.DateOfBirth = dob
+		}
+		if location := payload["location"].GetStructValue().GetFields(); location != nil {
+			lat := location["lat"].GetDoubleValue()
+			lon := location["lon"].GetDoubleValue()
+			hit.Latitude, hit.Longitude = &lat, &lon
+		}
+		results = append(results, hit)
+	}
+	return results, nil
+}
+
+// AgeGroup buckets an age for coarse payload filtering.
+func AgeGroup(age int) string {
+	switch {
+	case age < 25:
+		return "18-24"
+	case age < 35:
+		return "25-34"
+	case age < 45:
+		return "35-44"
+	case age < 55:
+		return "45-54"
+	default:
+		return "55+"
+	}
+}
@@ -0,0 +1,77 @@
+package qdrant
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBirthDateRange(t *testing.T) {
+	at := time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		minAge       int
+		maxAge       int
+		wantEarliest string
+		wantLatest   string
+	}{
+		{"both ends", 25, 35, "1990-10-19", "2001-10-19"},
+		{"no maximum", 25, 0, "", "2001-10-19"},
+		{"no minimum", 0, 35, "1990-10-19", ""},
+	}
+	format := func(d time.Time) string {
+		if d.IsZero() {
+			return ""
+		}
+		return d.Format(time.DateOnly)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			earliest, latest := BirthDateRange(tt.minAge, tt.maxAge, at)
+			if format(earliest) != tt.wantEarliest || format(latest) != tt.wantLatest {
+				t.Errorf("BirthDateRange() = (%s, %s), want (%s, %s)", format(earliest), format(latest), tt.wantEarliest, tt.wantLatest)
+			}
+		})
+	}
+}
+
+// Ages follow the calendar: nothing has to be re-synced on a birthday.
+func TestSearchAgeOnBirthday(t *testing.T) {
+	at := time.Date(2026, 10, 19, 15, 0, 0, 0, time.UTC)
+	born := func(date string) time.Time {
+		d, _ := time.Parse(time.DateOnly, date)
+		return d
+	}
+	people := map[string]time.Time{
+		"turns 25 today":    born("2001-10-19"),
+		"turns 25 tomorrow": born("2001-10-20"),
+		"turns 36 today":    born("1990-10-19"),
+		"turns 36 tomorrow": born("1990-10-20"),
+	}
+
+	repo := NewMemoryProfileRepository()
+	names := map[uuid.UUID]string{}
+	for name, dob := range people {
+		id := uuid.New()
+		names[id] = name
+		repo.Upsert(context.Background(), []*ProfilePoint{{
+			UserID: id, Vector: []float32{1}, DateOfBirth: dob,
+			Active: true, Verified: true, VideoVerified: true,
+		}})
+	}
+
+	hits, err := repo.Search(context.Background(), &ProfileSearch{Vector: []float32{1}, MinAge: 25, MaxAge: 35, At: at})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	got := map[string]bool{}
+	for _, hit := range hits {
+		got[names[hit.UserID]] = true
+	}
+	if len(got) != 2 || !got["turns 25 today"] || !got["turns 36 tomorrow"] {
+		t.Errorf("Search() returned %v, want those aged 25 to 35 today", got)
+	}
+}
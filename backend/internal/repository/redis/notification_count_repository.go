@@ -0,0 +1,90 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/pkg/cache"
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const (
+	// unreadCountTTL bounds how long a cached count can outlive a missed
+	// invalidation.
+	unreadCountTTL = 10 * time.Minute
+	// unreadVersionTTL outlives every count cached under a version, so an
+	// expired version never brings an old count back.
+	unreadVersionTTL = 24 * time.Hour
+)
+
+// NotificationCountRepository caches each user's unread notification count
+// in front of Postgres.
+type NotificationCountRepository interface {
+	GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error)
+	InvalidateUnreadCount(ctx context.Context, userID uuid.UUID) error
+}
+
+type notificationCountRepository struct {
+	cache            *cache.RedisCache
+	notificationRepo postgres.NotificationRepository
+}
+
+func NewNotificationCountRepository(cache *cache.RedisCache, notificationRepo postgres.NotificationRepository) NotificationCountRepository {
+	return &notificationCountRepository{
+		cache:            cache,
+		notificationRepo: notificationRepo,
+	}
+}
+
+// GetUnreadCount serves the count from Redis, falling back to Postgres when
+// it is missing or Redis is unavailable. Counts are cached under the user's
+// current version, so one counted before an invalidation is written to a key
+// nobody reads any more.
+func (r *notificationCountRepository) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var version int64
+	err := r.cache.Get(ctx, unreadVersionKey(userID), &version)
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		slog.WarnContext(ctx, "Failed to read unread count version", "user_id", userID, "error", err)
+		return r.notificationRepo.CountUnread(ctx, userID)
+	}
+
+	key := unreadCountKey(userID, version)
+	var count int64
+	err = r.cache.Get(ctx, key, &count)
+	if err == nil {
+		return count, nil
+	}
+	if !errors.Is(err, goredis.Nil) {
+		slog.WarnContext(ctx, "Failed to read cached unread count", "user_id", userID, "error", err)
+	}
+
+	count, err = r.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if err := r.cache.Set(ctx, key, count, unreadCountTTL); err != nil {
+		// The count is known; the next request simply misses the cache
+		slog.WarnContext(ctx, "Failed to cache unread count", "user_id", userID, "error", err)
+	}
+	return count, nil
+}
+
+// InvalidateUnreadCount moves the user to a new version, orphaning the
+// cached count along with any that is being written concurrently.
+func (r *notificationCountRepository) InvalidateUnreadCount(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.cache.Incr(ctx, unreadVersionKey(userID), unreadVersionTTL)
+	return err
+}
+
+func unreadVersionKey(userID uuid.UUID) string {
+	return fmt.Sprintf("notifications:unread:%s:version", userID)
+}
+
+func unreadCountKey(userID uuid.UUID, version int64) string {
+	return fmt.Sprintf("notifications:unread:%s:%d", userID, version)
+}
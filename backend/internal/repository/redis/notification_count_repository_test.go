@@ -0,0 +1,96 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/pkg/cache"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+)
+
+// fakeUnread counts from a fixed value; during is called mid-count to stand
+// in for a concurrent change.
+type fakeUnread struct {
+	postgres.NotificationRepository
+	unread int64
+	counts int
+	during func()
+}
+
+func (r *fakeUnread) CountUnread(ctx context.Context, userID uuid.UUID) (int64, error) {
+	r.counts++
+	count := r.unread
+	if r.during != nil {
+		r.during()
+		r.during = nil
+	}
+	return count, nil
+}
+
+func newCountRepo(t *testing.T) (*miniredis.Miniredis, *fakeUnread, NotificationCountRepository) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	redisCache, err := cache.NewRedisCache(mr.Addr(), "", 0, "test:")
+	if err != nil {
+		t.Fatalf("NewRedisCache() error = %v", err)
+	}
+	notifications := &fakeUnread{}
+	return mr, notifications, NewNotificationCountRepository(redisCache, notifications)
+}
+
+func TestUnreadCountCached(t *testing.T) {
+	_, notifications, repo := newCountRepo(t)
+	ctx, userID := context.Background(), uuid.New()
+	notifications.unread = 3
+
+	for range 2 {
+		if count, err := repo.GetUnreadCount(ctx, userID); err != nil || count != 3 {
+			t.Fatalf("GetUnreadCount() = %d, %v, want 3", count, err)
+		}
+	}
+	if notifications.counts != 1 {
+		t.Errorf("counted %d times, want the second read cached", notifications.counts)
+	}
+
+	notifications.unread = 4
+	if err := repo.InvalidateUnreadCount(ctx, userID); err != nil {
+		t.Fatalf("InvalidateUnreadCount() error = %v", err)
+	}
+	if count, err := repo.GetUnreadCount(ctx, userID); err != nil || count != 4 {
+		t.Errorf("GetUnreadCount() after invalidation = %d, %v, want 4", count, err)
+	}
+}
+
+func TestUnreadCountNotStaleAfterConcurrentInvalidation(t *testing.T) {
+	_, notifications, repo := newCountRepo(t)
+	ctx, userID := context.Background(), uuid.New()
+	notifications.unread = 3
+	// A notification is read after the count was taken but before it is
+	// cached
+	notifications.during = func() {
+		notifications.unread = 2
+		if err := repo.InvalidateUnreadCount(ctx, userID); err != nil {
+			t.Errorf("InvalidateUnreadCount() error = %v", err)
+		}
+	}
+
+	if count, err := repo.GetUnreadCount(ctx, userID); err != nil || count != 3 {
+		t.Fatalf("GetUnreadCount() = %d, %v, want 3", count, err)
+	}
+	if count, err := repo.GetUnreadCount(ctx, userID); err != nil || count != 2 {
+		t.Errorf("GetUnreadCount() = %d, %v, want 2 rather than the count cached before invalidation", count, err)
+	}
+}
+
+func TestUnreadCountWithoutRedis(t *testing.T) {
+	mr, notifications, repo := newCountRepo(t)
+	notifications.unread = 5
+	mr.Close()
+
+	count, err := repo.GetUnreadCount(context.Background(), uuid.New())
+	if err != nil || count != 5 {
+		t.Errorf("GetUnreadCount() = %d, %v, want 5 from Postgres", count, err)
+	}
+}
@@ -0,0 +1,68 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/pkg/cache"
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// tokenVersionTTL bounds how long a cached version can outlive a missed
+// invalidation.
+const tokenVersionTTL = 10 * time.Minute
+
+// SessionRepository exposes the current token version of each user so that
+// tokens issued before a forced logout can be rejected.
+type SessionRepository interface {
+	GetTokenVersion(ctx context.Context, userID uuid.UUID) (int, error)
+	InvalidateTokenVersion(ctx context.Context, userID uuid.UUID) error
+}
+
+type sessionRepository struct {
+	cache    *cache.RedisCache
+	userRepo postgres.UserRepository
+}
+
+func NewSessionRepository(cache *cache.RedisCache, userRepo postgres.UserRepository) SessionRepository {
+	return &sessionRepository{
+		cache:    cache,
+		userRepo: userRepo,
+	}
+}
+
+func (r *sessionRepository) GetTokenVersion(ctx context.Context, userID uuid.UUID) (int, error) {
+	key := tokenVersionKey(userID)
+
+	var version int
+	err := r.cache.Get(ctx, key, &version)
+	if err == nil {
+		return version, nil
+	}
+	if !errors.Is(err, goredis.Nil) {
+		return 0, err
+	}
+
+	user, err := r.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if err := r.cache.Set(ctx, key, user.TokenVersion, tokenVersionTTL); err != nil {
+		// The version is known; the next request simply misses the cache
+		slog.WarnContext(ctx, "Failed to cache token version", "user_id", userID, "error", err)
+	}
+	return user.TokenVersion, nil
+}
+
+func (r *sessionRepository) InvalidateTokenVersion(ctx context.Context, userID uuid.UUID) error {
+	return r.cache.Delete(ctx, tokenVersionKey(userID))
+}
+
+func tokenVersionKey(userID uuid.UUID) string {
+	return fmt.Sprintf("session:token_version:%s", userID)
+}
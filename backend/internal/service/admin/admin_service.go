@@ -0,0 +1,198 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/repository/redis"
+	"github.com/alexcolls/findme/internal/service/events"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSelfModification = errors.New("staff cannot change their own role, status or sessions")
+	ErrOutranked        = errors.New("only admins can act on staff accounts")
+)
+
+// roleRank orders roles for staff actions. Non-admin staff may only act on
+// users ranked below them.
+var roleRank = map[string]int{
+	models.RoleUser:      0,
+	models.RoleModerator: 1,
+	models.RoleSupport:   1,
+	models.RoleAdmin:     2,
+}
+
+type AdminService interface {
+	SearchUsers(ctx context.Context, filter models.UserSearchFilter) ([]*models.User, error)
+	GetUser(ctx context.Context, userID uuid.UUID) (*models.User, error)
+	ChangeRole(ctx context.Context, actorID, userID uuid.UUID, req *models.ChangeRoleRequest) error
+	Suspend(ctx context.Context, actorID, userID uuid.UUID, reason string) error
+	Unsuspend(ctx context.Context, actorID, userID uuid.UUID, reason string) error
+	ForceLogout(ctx context.Context, actorID, userID uuid.UUID, reason string) error
+	OverrideVerification(ctx context.Context, actorID, userID uuid.UUID, req *models.VerificationOverrideRequest) error
+	AuditTrail(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.AdminAuditEntry, error)
+	BootstrapAdmins(ctx context.Context, emails []string) error
+}
+
+type adminService struct {
+	adminRepo postgres.AdminRepository
+	userRepo  postgres.UserRepository
+	sessions  redis.SessionRepository
+	events    events.Publisher
+}
+
+func NewAdminService(adminRepo postgres.AdminRepository, userRepo postgres.UserRepository, sessions redis.SessionRepository, publisher events.Publisher) AdminService {
+	return &adminService{
+		adminRepo: adminRepo,
+		userRepo:  userRepo,
+		sessions:  sessions,
+		events:    publisher,
+	}
+}
+
+func (s *adminService) SearchUsers(ctx context.Context, filter models.UserSearchFilter) ([]*models.User, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	filter.Query = strings.TrimSpace(filter.Query)
+	return s.adminRepo.SearchUsers(ctx, filter)
+}
+
+func (s *adminService) GetUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, postgres.ErrAdminTargetNotFound
+	}
+	return user, nil
+}
+
+// authorize checks the actor may act on the user: never on themselves, and
+// unless they are an admin, only on users with a lower role.
+func (s *adminService) authorize(ctx context.Context, actorID, userID uuid.UUID) error {
+	if actorID == userID {
+		return ErrSelfModification
+	}
+	actor, err := s.userRepo.GetByID(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	if actor.Role == models.RoleAdmin {
+		return nil
+	}
+
+	target, err := s.userRepo.GetByID(ctx, userID)
+	if errors.Is(err, postgres.ErrUserNotFound) {
+		return postgres.ErrAdminTargetNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if roleRank[target.Role] >= roleRank[actor.Role] {
+		return ErrOutranked
+	}
+	return nil
+}
+
+func (s *adminService) ChangeRole(ctx context.Context, actorID, userID uuid.UUID, req *models.ChangeRoleRequest) error {
+	if err := s.authorize(ctx, actorID, userID); err != nil {
+		return err
+	}
+	if err := s.adminRepo.SetRole(ctx, &actorID, userID, req.Role, req.Reason); err != nil {
+		return err
+	}
+	return s.revokeSessions(ctx, userID)
+}
+
+func (s *adminService) Suspend(ctx context.Context, actorID, userID uuid.UUID, reason string) error {
+	if err := s.authorize(ctx, actorID, userID); err != nil {
+		return err
+	}
+	if err := s.adminRepo.SetActive(ctx, &actorID, userID, false, reason); err != nil {
+		return err
+	}
+	return s.revokeSessions(ctx, userID)
+}
+
+func (s *adminService) Unsuspend(ctx context.Context, actorID, userID uuid.UUID, reason string) error {
+	if err := s.authorize(ctx, actorID, userID); err != nil {
+		return err
+	}
+	return s.adminRepo.SetActive(ctx, &actorID, userID, true, reason)
+}
+
+func (s *adminService) ForceLogout(ctx context.Context, actorID, userID uuid.UUID, reason string) error {
+	if err := s.authorize(ctx, actorID, userID); err != nil {
+		return err
+	}
+	if err := s.adminRepo.RevokeTokens(ctx, &actorID, userID, reason); err != nil {
+		return err
+	}
+	return s.revokeSessions(ctx, userID)
+}
+
+// revokeSessions invalidates the user's tokens and closes their open
+// connections, which were only authenticated when they opened.
+func (s *adminService) revokeSessions(ctx context.Context, userID uuid.UUID) error {
+	if err := s.sessions.InvalidateTokenVersion(ctx, userID); err != nil {
+		return err
+	}
+	s.events.Publish(ctx, events.Event{Type: events.SessionsRevoked, UserIDs: []uuid.UUID{userID}})
+	return nil
+}
+
+func (s *adminService) OverrideVerification(ctx context.Context, actorID, userID uuid.UUID, req *models.VerificationOverrideRequest) error {
+	if err := s.authorize(ctx, actorID, userID); err != nil {
+		return err
+	}
+	if err := s.adminRepo.SetVerified(ctx, &actorID, userID, *req.Verified, req.Reason); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, events.Event{
+		Type:    events.VerificationDecided,
+		UserIDs: []uuid.UUID{userID},
+		Data:    &models.VerificationResult{UserID: userID, Verified: *req.Verified},
+	})
+	return nil
+}
+
+func (s *adminService) AuditTrail(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.AdminAuditEntry, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.adminRepo.ListAudit(ctx, userID, limit, offset)
+}
+
+// BootstrapAdmins grants the admin role to existing accounts with the given
+// emails. Changes are audited as system actions with no actor.
+func (s *adminService) BootstrapAdmins(ctx context.Context, emails []string) error {
+	for _, email := range emails {
+		user, err := s.userRepo.GetByEmail(ctx, email)
+		if err != nil {
+			slog.WarnContext(ctx, "Admin bootstrap: no account, skipping", "email", email)
+			continue
+		}
+		if user.Role == models.RoleAdmin {
+			continue
+		}
+		if err := s.adminRepo.SetRole(ctx, nil, user.ID, models.RoleAdmin, "bootstrap from ADMIN_EMAILS"); err != nil {
+			return err
+		}
+		if err := s.revokeSessions(ctx, user.ID); err != nil {
+			return err
+		}
+		slog.InfoContext(ctx, "Admin bootstrap: granted admin role", "email", email)
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/repository/redis"
+	"github.com/alexcolls/findme/internal/service/events"
+	"github.com/google/uuid"
+)
+
+type fakeUserRepo struct {
+	postgres.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, postgres.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (r *fakeUserRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, postgres.ErrUserNotFound
+}
+
+// fakeAdminRepo applies changes to the user repo and keeps the audit trail
+// the real repository writes in the same transaction.
+type fakeAdminRepo struct {
+	postgres.AdminRepository
+	users *fakeUserRepo
+	audit []*models.AdminAuditEntry
+}
+
+func (r *fakeAdminRepo) record(actorID *uuid.UUID, userID uuid.UUID, action, reason string) {
+	r.audit = append(r.audit, &models.AdminAuditEntry{ActorID: actorID, TargetUserID: userID, Action: action, Reason: &reason})
+}
+
+func (r *fakeAdminRepo) SetRole(ctx context.Context, actorID *uuid.UUID, userID uuid.UUID, role, reason string) error {
+	r.users.users[userID].Role = role
+	r.record(actorID, userID, models.AdminActionRoleChanged, reason)
+	return nil
+}
+
+func (r *fakeAdminRepo) SetActive(ctx context.Context, actorID *uuid.UUID, userID uuid.UUID, active bool, reason string) error {
+	r.users.users[userID].Active = active
+	action := models.AdminActionSuspended
+	if active {
+		action = models.AdminActionUnsuspended
+	}
+	r.record(actorID, userID, action, reason)
+	return nil
+}
+
+func (r *fakeAdminRepo) SetVerified(ctx context.Context, actorID *uuid.UUID, userID uuid.UUID, verified bool, reason string) error {
+	r.users.users[userID].Verified = verified
+	r.record(actorID, userID, models.AdminActionVerificationChanged, reason)
+	return nil
+}
+
+func (r *fakeAdminRepo) RevokeTokens(ctx context.Context, actorID *uuid.UUID, userID uuid.UUID, reason string) error {
+	r.users.users[userID].TokenVersion++
+	r.record(actorID, userID, models.AdminActionForcedLogout, reason)
+	return nil
+}
+
+type fakeSessions struct {
+	redis.SessionRepository
+	invalidated []uuid.UUID
+}
+
+func (s *fakeSessions) InvalidateTokenVersion(ctx context.Context, userID uuid.UUID) error {
+	s.invalidated = append(s.invalidated, userID)
+	return nil
+}
+
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event events.Event) {
+	p.events = append(p.events, event)
+}
+
+type testService struct {
+	AdminService
+	users     *fakeUserRepo
+	repo      *fakeAdminRepo
+	sessions  *fakeSessions
+	publisher *recordingPublisher
+}
+
+func newTestService(users ...*models.User) *testService {
+	ts := &testService{
+		users:     &fakeUserRepo{users: map[uuid.UUID]*models.User{}},
+		sessions:  &fakeSessions{},
+		publisher: &recordingPublisher{},
+	}
+	for _, user := range users {
+		ts.users.users[user.ID] = user
+	}
+	ts.repo = &fakeAdminRepo{users: ts.users}
+	ts.AdminService = NewAdminService(ts.repo, ts.users, ts.sessions, ts.publisher)
+	return ts
+}
+
+func newUser(role string) *models.User {
+	return &models.User{ID: uuid.New(), Email: uuid.NewString() + "@findme.app", Role: role, Active: true}
+}
+
+func TestChangeRole(t *testing.T) {
+	admin, user := newUser(models.RoleAdmin), newUser(models.RoleUser)
+	ts := newTestService(admin, user)
+
+	err := ts.ChangeRole(context.Background(), admin.ID, user.ID, &models.ChangeRoleRequest{Role: models.RoleModerator, Reason: "joined the team"})
+	if err != nil {
+		t.Fatalf("ChangeRole() error = %v", err)
+	}
+	if user.Role != models.RoleModerator {
+		t.Errorf("role = %s, want moderator", user.Role)
+	}
+	if len(ts.sessions.invalidated) != 1 || ts.sessions.invalidated[0] != user.ID {
+		t.Errorf("invalidated sessions = %v, want the user's so the new role applies", ts.sessions.invalidated)
+	}
+	if len(ts.repo.audit) != 1 || *ts.repo.audit[0].ActorID != admin.ID || *ts.repo.audit[0].Reason != "joined the team" {
+		t.Errorf("audit = %+v, want one entry by the admin with the reason", ts.repo.audit)
+	}
+
+	err = ts.ChangeRole(context.Background(), admin.ID, admin.ID, &models.ChangeRoleRequest{Role: models.RoleUser})
+	if !errors.Is(err, ErrSelfModification) {
+		t.Errorf("changing own role error = %v, want ErrSelfModification", err)
+	}
+}
+
+func TestStaffActions(t *testing.T) {
+	type action func(s AdminService, actor, target uuid.UUID) error
+	verified := true
+	actions := map[string]action{
+		"suspend": func(s AdminService, actor, target uuid.UUID) error {
+			return s.Suspend(context.Background(), actor, target, "spam")
+		},
+		"force logout": func(s AdminService, actor, target uuid.UUID) error {
+			return s.ForceLogout(context.Background(), actor, target, "compromised")
+		},
+		"verify": func(s AdminService, actor, target uuid.UUID) error {
+			return s.OverrideVerification(context.Background(), actor, target,
+				&models.VerificationOverrideRequest{Verified: &verified, Reason: "manual check"})
+		},
+	}
+
+	tests := []struct {
+		name   string
+		actor  string
+		target string
+		self   bool
+		want   error
+	}{
+		{"support on user", models.RoleSupport, models.RoleUser, false, nil},
+		{"support on moderator", models.RoleSupport, models.RoleModerator, false, ErrOutranked},
+		{"support on support", models.RoleSupport, models.RoleSupport, false, ErrOutranked},
+		{"support on admin", models.RoleSupport, models.RoleAdmin, false, ErrOutranked},
+		{"admin on support", models.RoleAdmin, models.RoleSupport, false, nil},
+		{"admin on admin", models.RoleAdmin, models.RoleAdmin, false, nil},
+		{"on themselves", models.RoleAdmin, "", true, ErrSelfModification},
+	}
+	for name, act := range actions {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				actor := newUser(tt.actor)
+				target := actor
+				if !tt.self {
+					target = newUser(tt.target)
+				}
+				ts := newTestService(actor, target)
+
+				err := act(ts, actor.ID, target.ID)
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("error = %v, want %v", err, tt.want)
+				}
+				wantAudit := 0
+				if tt.want == nil {
+					wantAudit = 1
+				}
+				if len(ts.repo.audit) != wantAudit {
+					t.Errorf("audit entries = %d, want %d", len(ts.repo.audit), wantAudit)
+				}
+			})
+		}
+	}
+}
+
+func TestSuspendRevokesSessions(t *testing.T) {
+	support, user := newUser(models.RoleSupport), newUser(models.RoleUser)
+	ts := newTestService(support, user)
+
+	if err := ts.Suspend(context.Background(), support.ID, user.ID, "spam"); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+	if user.Active {
+		t.Error("user still active after suspension")
+	}
+	if len(ts.sessions.invalidated) != 1 || ts.sessions.invalidated[0] != user.ID {
+		t.Errorf("invalidated sessions = %v, want the suspended user's", ts.sessions.invalidated)
+	}
+	if len(ts.publisher.events) != 1 || ts.publisher.events[0].Type != events.SessionsRevoked || ts.publisher.events[0].UserIDs[0] != user.ID {
+		t.Errorf("events = %v, want the user's sessions revoked so open sockets close", ts.publisher.events)
+	}
+	if entry := ts.repo.audit[0]; entry.Action != models.AdminActionSuspended || *entry.ActorID != support.ID {
+		t.Errorf("audit entry = %+v, want a suspension by the support agent", entry)
+	}
+
+	if err := ts.Suspend(context.Background(), support.ID, uuid.New(), "spam"); !errors.Is(err, postgres.ErrAdminTargetNotFound) {
+		t.Errorf("suspending an unknown user error = %v, want ErrAdminTargetNotFound", err)
+	}
+}
+
+func TestOverrideVerificationPublishes(t *testing.T) {
+	admin, user := newUser(models.RoleAdmin), newUser(models.RoleUser)
+	ts := newTestService(admin, user)
+	verified := true
+
+	err := ts.OverrideVerification(context.Background(), admin.ID, user.ID, &models.VerificationOverrideRequest{Verified: &verified, Reason: "manual check"})
+	if err != nil {
+		t.Fatalf("OverrideVerification() error = %v", err)
+	}
+	if !user.Verified {
+		t.Error("user not verified")
+	}
+	if len(ts.publisher.events) != 1 || ts.publisher.events[0].Type != events.VerificationDecided {
+		t.Errorf("events = %v, want one verification decision", ts.publisher.events)
+	}
+}
+
+func TestBootstrapAdmins(t *testing.T) {
+	user := newUser(models.RoleUser)
+	ts := newTestService(user)
+
+	if err := ts.BootstrapAdmins(context.Background(), []string{user.Email, "missing@findme.app"}); err != nil {
+		t.Fatalf("BootstrapAdmins() error = %v", err)
+	}
+	if user.Role != models.RoleAdmin {
+		t.Errorf("role = %s, want admin", user.Role)
+	}
+	if len(ts.repo.audit) != 1 || ts.repo.audit[0].ActorID != nil {
+		t.Errorf("audit = %+v, want one system entry with no actor", ts.repo.audit)
+	}
+
+	if err := ts.BootstrapAdmins(context.Background(), []string{user.Email}); err != nil || len(ts.repo.audit) != 1 {
+		t.Errorf("second bootstrap error = %v with %d audit entries, want no change", err, len(ts.repo.audit))
+	}
+}
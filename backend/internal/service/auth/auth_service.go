@@ -5,15 +5,24 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
-	"fmt"
+	"errors"
 	"time"
 
 	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/metrics"
 	"github.com/alexcolls/findme/internal/repository/postgres"
 	"github.com/alexcolls/findme/pkg/jwt"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrAccountInactive     = errors.New("account is inactive")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenRevoked = errors.New("refresh token has been revoked")
+	ErrInvalidDateOfBirth  = errors.New("date of birth must be formatted as YYYY-MM-DD")
+)
+
 type AuthService interface {
 	Register(ctx context.Context, req *models.RegisterRequest) (*models.AuthResponse, error)
 	Login(ctx context.Context, req *models.LoginRequest) (*models.AuthResponse, error)
@@ -36,10 +45,11 @@ func NewAuthService(userRepo postgres.UserRepository, jwtManager *jwt.JWTManager
 }
 
 func (s *authService) Register(ctx context.Context, req *models.RegisterRequest) (*models.AuthResponse, error) {
-	// Check if user exists
-	existingUser, _ := s.userRepo.GetByEmail(ctx, req.Email)
-	if existingUser != nil {
-		return nil, fmt.Errorf("email already registered")
+	// Check if user exists; Create catches registrations racing this one
+	if _, err := s.userRepo.GetByEmail(ctx, req.Email); err == nil {
+		return nil, postgres.ErrEmailTaken
+	} else if !errors.Is(err, postgres.ErrUserNotFound) {
+		return nil, err
 	}
 
 	// Hash password
@@ -51,7 +61,7 @@ func (s *authService) Register(ctx context.Context, req *models.RegisterRequest)
 	// Parse date of birth
 	dob, err := time.Parse("2006-01-02", req.DateOfBirth)
 	if err != nil {
-		return nil, fmt.Errorf("invalid date format")
+		return nil, ErrInvalidDateOfBirth
 	}
 
 	// Create user
@@ -68,6 +78,7 @@ func (s *authService) Register(ctx context.Context, req *models.RegisterRequest)
 	if err := s.userRepo.Create(ctx, user); err != nil {
 		return nil, err
 	}
+	metrics.Registrations.Inc()
 
 	// Generate email verification token
 	verificationToken, err := generateToken()
@@ -78,12 +89,12 @@ func (s *authService) Register(ctx context.Context, req *models.RegisterRequest)
 	}
 
 	// Generate tokens
-	accessToken, err := s.jwtManager.GenerateAccessToken(user.ID, user.Email)
+	accessToken, err := s.jwtManager.GenerateAccessToken(user.ID, user.Email, user.Role, user.TokenVersion)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID, user.Email)
+	refreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID, user.Email, user.Role, user.TokenVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -102,30 +113,37 @@ func (s *authService) Register(ctx context.Context, req *models.RegisterRequest)
 func (s *authService) Login(ctx context.Context, req *models.LoginRequest) (*models.AuthResponse, error) {
 	// Get user by email
 	user, err := s.userRepo.GetByEmail(ctx, req.Email)
+	if errors.Is(err, postgres.ErrUserNotFound) {
+		metrics.LoginFailures.WithLabelValues("invalid_credentials").Inc()
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
-		return nil, fmt.Errorf("invalid credentials")
+		return nil, err
 	}
 
 	// Check password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return nil, fmt.Errorf("invalid credentials")
+		metrics.LoginFailures.WithLabelValues("invalid_credentials").Inc()
+		return nil, ErrInvalidCredentials
 	}
 
 	// Check if user is active
 	if !user.Active {
-		return nil, fmt.Errorf("account is inactive")
+		metrics.LoginFailures.WithLabelValues("inactive").Inc()
+		return nil, ErrAccountInactive
 	}
 
 	// Update last login
 	s.userRepo.UpdateLastLogin(ctx, user.ID)
+	metrics.Logins.Inc()
 
 	// Generate tokens
-	accessToken, err := s.jwtManager.GenerateAccessToken(user.ID, user.Email)
+	accessToken, err := s.jwtManager.GenerateAccessToken(user.ID, user.Email, user.Role, user.TokenVersion)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID, user.Email)
+	refreshToken, err := s.jwtManager.GenerateRefreshToken(user.ID, user.Email, user.Role, user.TokenVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -144,16 +162,27 @@ func (s *authService) Login(ctx context.Context, req *models.LoginRequest) (*mod
 func (s *authService) RefreshToken(ctx context.Context, refreshToken string) (*models.TokenResponse, error) {
 	// Validate refresh token
 	claims, err := s.jwtManager.ValidateToken(refreshToken)
-	if err != nil {
-		return nil, fmt.Errorf("invalid refresh token")
+	if err != nil || claims.Type != "refresh" {
+		return nil, ErrInvalidRefreshToken
 	}
 
-	if claims.Type != "refresh" {
-		return nil, fmt.Errorf("invalid token type")
+	// Reload the user so role changes, suspensions and forced logouts apply
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if errors.Is(err, postgres.ErrUserNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.Active {
+		return nil, ErrAccountInactive
+	}
+	if claims.TokenVersion != user.TokenVersion {
+		return nil, ErrRefreshTokenRevoked
 	}
 
 	// Generate new access token
-	accessToken, err := s.jwtManager.GenerateAccessToken(claims.UserID, claims.Email)
+	accessToken, err := s.jwtManager.GenerateAccessToken(user.ID, user.Email, user.Role, user.TokenVersion)
 	if err != nil {
 		return nil, err
 	}
@@ -173,10 +202,13 @@ func (s *authService) VerifyEmail(ctx context.Context, token string) error {
 func (s *authService) RequestPasswordReset(ctx context.Context, email string) (string, error) {
 	// Check if user exists
 	_, err := s.userRepo.GetByEmail(ctx, email)
-	if err != nil {
+	if errors.Is(err, postgres.ErrUserNotFound) {
 		// Don't reveal if email exists
 		return "", nil
 	}
+	if err != nil {
+		return "", err
+	}
 
 	// Generate reset token
 	resetToken, err := generateToken()
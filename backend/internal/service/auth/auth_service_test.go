@@ -1,15 +1,52 @@
 package auth
 
 import (
+	"context"
+	"errors"
 	"testing"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/pkg/jwt"
+	"golang.org/x/crypto/bcrypt"
 )
 
-// Placeholder test to satisfy CI requirements
-// Full test suite to be implemented in Epic 4
+// usersByEmail serves GetByEmail from a fixed result; other methods are unused.
+type usersByEmail struct {
+	postgres.UserRepository
+	user *models.User
+	err  error
+}
+
+func (r usersByEmail) GetByEmail(context.Context, string) (*models.User, error) {
+	return r.user, r.err
+}
+
+func TestLoginErrors(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errDB := errors.New("connection refused")
 
-func TestAuthService(t *testing.T) {
-	t.Run("Placeholder", func(t *testing.T) {
-		// TODO: Implement comprehensive tests
-		t.Log("Auth service tests pending implementation")
-	})
+	tests := []struct {
+		name     string
+		repo     usersByEmail
+		password string
+		want     error
+	}{
+		{"unknown email", usersByEmail{err: postgres.ErrUserNotFound}, "correct horse", ErrInvalidCredentials},
+		{"database failure", usersByEmail{err: errDB}, "correct horse", errDB},
+		{"wrong password", usersByEmail{user: &models.User{PasswordHash: string(hash), Active: true}}, "wrong", ErrInvalidCredentials},
+		{"inactive account", usersByEmail{user: &models.User{PasswordHash: string(hash)}}, "correct horse", ErrAccountInactive},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewAuthService(tt.repo, jwt.NewJWTManager("secret", 15, 7))
+			_, err := svc.Login(context.Background(), &models.LoginRequest{Email: "a@example.com", Password: tt.password})
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Login() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
 }
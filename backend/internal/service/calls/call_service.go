@@ -0,0 +1,257 @@
+package calls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/metrics"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/ice"
+	"github.com/google/uuid"
+)
+
+var ErrNotCallee = errors.New("only the person being called can answer or decline")
+
+// Notifier delivers realtime messages to a user's connected devices and
+// returns how many received them.
+type Notifier interface {
+	SendToUser(userID uuid.UUID, v interface{}) (int, error)
+}
+
+// Pusher sends mobile pushes to a user's devices, subject to their
+// preferences.
+type Pusher interface {
+	Send(ctx context.Context, userID uuid.UUID, category string, msg *models.PushMessage) (int, error)
+}
+
+// CallService runs video calls between mutual matches through their
+// lifecycle: initiated, ringing, active and finally ended, missed, rejected
+// or failed.
+type CallService interface {
+	Initiate(ctx context.Context, userID uuid.UUID, req *models.InitiateCallRequest) (*models.VideoCall, error)
+	Accept(ctx context.Context, userID, callID uuid.UUID) (*models.VideoCall, error)
+	Reject(ctx context.Context, userID, callID uuid.UUID) (*models.VideoCall, error)
+	End(ctx context.Context, userID, callID uuid.UUID, req *models.EndCallRequest) (*models.VideoCall, error)
+	Review(ctx context.Context, userID, callID uuid.UUID, req *models.CallFeedbackRequest) (*models.VideoCall, error)
+	History(ctx context.Context, userID uuid.UUID, filter models.CallHistoryFilter) (*models.CallHistory, error)
+}
+
+type callService struct {
+	callRepo    postgres.CallRepository
+	iceProvider ice.Provider
+	notifier    Notifier
+	pusher      Pusher
+	ringTimeout time.Duration
+}
+
+func NewCallService(callRepo postgres.CallRepository, iceProvider ice.Provider, notifier Notifier, pusher Pusher, ringTimeout time.Duration) CallService {
+	return &callService{
+		callRepo:    callRepo,
+		iceProvider: iceProvider,
+		notifier:    notifier,
+		pusher:      pusher,
+		ringTimeout: ringTimeout,
+	}
+}
+
+// Initiate issues ICE servers for a new session, creates the call and rings
+// the callee. It stays initiated until at least one of the callee's devices
+// has been told about it. The callee's phones are also pushed, since only
+// the apps in the foreground hold a connection.
+func (s *callService) Initiate(ctx context.Context, userID uuid.UUID, req *models.InitiateCallRequest) (*models.VideoCall, error) {
+	sessionID := uuid.NewString()
+	servers, err := s.iceProvider.Servers(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue ICE servers: %w", err)
+	}
+
+	call, err := s.callRepo.Create(ctx, req.MatchID, userID, sessionID, servers)
+	if err != nil {
+		return nil, err
+	}
+	metrics.CallsStarted.Inc()
+
+	delivered, err := s.notifier.SendToUser(call.CalleeID, models.ServerMessage{
+		Type: models.RealtimeIncomingCall,
+		Data: &models.IncomingCall{
+			CallID:     call.ID,
+			MatchID:    call.MatchID,
+			CallerID:   call.CallerID,
+			ExpiresAt:  call.CreatedAt.Add(s.ringTimeout),
+			ICEServers: call.ICEServers,
+		},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to notify callee", "call_id", call.ID, "error", err)
+	}
+	go s.pushIncomingCall(context.WithoutCancel(ctx), call)
+	if delivered > 0 {
+		ringing, err := s.callRepo.UpdateStatus(ctx, call.ID, models.CallStatusInitiated, models.CallStatusRinging, nil, nil)
+		switch {
+		case err == nil:
+			call = ringing
+			notifyStatus(s.notifier, call)
+		case !errors.Is(err, postgres.ErrCallStateChanged):
+			// The callee answering first also changes the status
+			return nil, err
+		}
+	}
+	return call.ForViewer(userID), nil
+}
+
+// Accept answers a call that is still ringing. Once it has rung for the ring
+// timeout it counts as missed, even if the sweeper has not marked it yet.
+func (s *callService) Accept(ctx context.Context, userID, callID uuid.UUID) (*models.VideoCall, error) {
+	return s.transition(ctx, userID, callID, func(call *models.VideoCall) (string, *string, error) {
+		if call.CalleeID != userID {
+			return "", nil, ErrNotCallee
+		}
+		if time.Since(call.CreatedAt) >= s.ringTimeout {
+			return "", nil, postgres.ErrCallStateChanged
+		}
+		return models.CallStatusActive, nil, nil
+	})
+}
+
+func (s *callService) Reject(ctx context.Context, userID, callID uuid.UUID) (*models.VideoCall, error) {
+	return s.transition(ctx, userID, callID, func(call *models.VideoCall) (string, *string, error) {
+		if call.CalleeID != userID {
+			return "", nil, ErrNotCallee
+		}
+		return models.CallStatusRejected, nil, nil
+	})
+}
+
+// End hangs up. Before the call is answered this cancels it for the caller
+// and declines it for the callee.
+func (s *callService) End(ctx context.Context, userID, callID uuid.UUID, req *models.EndCallRequest) (*models.VideoCall, error) {
+	return s.transition(ctx, userID, callID, func(call *models.VideoCall) (string, *string, error) {
+		switch {
+		case req.Failed:
+			return models.CallStatusFailed, stringPtr(models.CallEndFailed), nil
+		case call.Status == models.CallStatusActive:
+			return models.CallStatusEnded, stringPtr(models.CallEndHangup), nil
+		case call.CalleeID == userID:
+			return models.CallStatusRejected, nil, nil
+		default:
+			return models.CallStatusEnded, stringPtr(models.CallEndCancelled), nil
+		}
+	})
+}
+
+// transition applies the status chosen by next to a call the user takes part
+// in, refusing moves the call lifecycle does not allow.
+func (s *callService) transition(ctx context.Context, userID, callID uuid.UUID, next func(*models.VideoCall) (string, *string, error)) (*models.VideoCall, error) {
+	call, err := s.callRepo.GetByID(ctx, callID)
+	if err != nil {
+		return nil, err
+	}
+	if !call.IsParticipant(userID) {
+		return nil, postgres.ErrCallNotFound
+	}
+
+	to, reason, err := next(call)
+	if err != nil {
+		return nil, err
+	}
+	if !models.CanTransitionCall(call.Status, to) {
+		return nil, postgres.ErrCallStateChanged
+	}
+
+	var endedBy *uuid.UUID
+	if to != models.CallStatusActive {
+		endedBy = &userID
+	}
+	updated, err := s.callRepo.UpdateStatus(ctx, callID, call.Status, to, endedBy, reason)
+	if err != nil {
+		return nil, err
+	}
+	notifyStatus(s.notifier, updated)
+	return updated.ForViewer(userID), nil
+}
+
+func (s *callService) Review(ctx context.Context, userID, callID uuid.UUID, req *models.CallFeedbackRequest) (*models.VideoCall, error) {
+	var feedback *string
+	if req.Feedback != "" {
+		feedback = &req.Feedback
+	}
+	call, err := s.callRepo.Review(ctx, callID, userID, req.QualityRating, feedback)
+	if err != nil {
+		return nil, err
+	}
+	return call.ForViewer(userID), nil
+}
+
+func (s *callService) History(ctx context.Context, userID uuid.UUID, filter models.CallHistoryFilter) (*models.CallHistory, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	history, err := s.callRepo.ListHistory(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, call := range history.Calls {
+		// Credentials of past calls are expired or about to be
+		call.ICEServers = nil
+		call.ForViewer(userID)
+	}
+	return history, nil
+}
+
+// notifyStatus tells both participants' devices the call's new status.
+func notifyStatus(notifier Notifier, call *models.VideoCall) {
+	msg := models.ServerMessage{
+		Type: models.RealtimeCallStatus,
+		Data: &models.CallStatusUpdate{
+			CallID:    call.ID,
+			MatchID:   call.MatchID,
+			Status:    call.Status,
+			StartedAt: call.StartedAt,
+			EndedAt:   call.EndedAt,
+			Duration:  call.Duration,
+			EndReason: call.EndReason,
+		},
+	}
+	for _, userID := range []uuid.UUID{call.CallerID, call.CalleeID} {
+		if _, err := notifier.SendToUser(userID, msg); err != nil {
+			slog.Error("Failed to send call status", "call_id", call.ID, "user_id", userID, "error", err)
+		}
+	}
+}
+
+// pushIncomingCall wakes the callee's devices. The push expires with the
+// ring and shares its collapse key with the missed call notification that
+// may follow.
+func (s *callService) pushIncomingCall(ctx context.Context, call *models.VideoCall) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := s.pusher.Send(ctx, call.CalleeID, models.PushIncomingCall, &models.PushMessage{
+		Title: "Incoming video call",
+		Body:  "One of your matches is calling you.",
+		Data: map[string]string{
+			"type":      models.PushIncomingCall,
+			"call_id":   call.ID.String(),
+			"match_id":  call.MatchID.String(),
+			"caller_id": call.CallerID.String(),
+		},
+		Priority:    models.PushPriorityHigh,
+		CollapseKey: "call:" + call.ID.String(),
+		TTL:         s.ringTimeout,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to push incoming call", "call_id", call.ID, "error", err)
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,225 @@
+package calls
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/ice"
+	"github.com/google/uuid"
+)
+
+// fakeCallRepo keeps calls in memory and applies status updates the way the
+// repository does, including the check on the expected current status.
+type fakeCallRepo struct {
+	postgres.CallRepository
+	calls  map[uuid.UUID]*models.VideoCall
+	callee uuid.UUID
+}
+
+func (r *fakeCallRepo) Create(ctx context.Context, matchID, callerID uuid.UUID, sessionID string, servers []models.ICEServer) (*models.VideoCall, error) {
+	call := &models.VideoCall{
+		ID:         uuid.New(),
+		MatchID:    matchID,
+		CallerID:   callerID,
+		CalleeID:   r.callee,
+		SessionID:  sessionID,
+		Status:     models.CallStatusInitiated,
+		ICEServers: servers,
+		CreatedAt:  time.Now(),
+	}
+	r.calls[call.ID] = call
+	copied := *call
+	return &copied, nil
+}
+
+func (r *fakeCallRepo) GetByID(ctx context.Context, callID uuid.UUID) (*models.VideoCall, error) {
+	call, ok := r.calls[callID]
+	if !ok {
+		return nil, postgres.ErrCallNotFound
+	}
+	copied := *call
+	return &copied, nil
+}
+
+func (r *fakeCallRepo) UpdateStatus(ctx context.Context, callID uuid.UUID, from, to string, endedBy *uuid.UUID, endReason *string) (*models.VideoCall, error) {
+	call := r.calls[callID]
+	if call.Status != from {
+		return nil, postgres.ErrCallStateChanged
+	}
+	call.Status = to
+	if endedBy != nil {
+		call.EndedBy = endedBy
+	}
+	if endReason != nil {
+		call.EndReason = endReason
+	}
+	copied := *call
+	return &copied, nil
+}
+
+type fakeNotifier struct {
+	online map[uuid.UUID]bool
+	sent   map[uuid.UUID][]string
+}
+
+func (n *fakeNotifier) SendToUser(userID uuid.UUID, v interface{}) (int, error) {
+	if !n.online[userID] {
+		return 0, nil
+	}
+	n.sent[userID] = append(n.sent[userID], v.(models.ServerMessage).Type)
+	return 1, nil
+}
+
+type noopPusher struct{}
+
+func (noopPusher) Send(ctx context.Context, userID uuid.UUID, category string, msg *models.PushMessage) (int, error) {
+	return 0, nil
+}
+
+func newTestService() (CallService, *fakeCallRepo, *fakeNotifier) {
+	repo := &fakeCallRepo{calls: map[uuid.UUID]*models.VideoCall{}, callee: uuid.New()}
+	notifier := &fakeNotifier{online: map[uuid.UUID]bool{}, sent: map[uuid.UUID][]string{}}
+	provider, _ := ice.NewProvider(ice.Config{STUNServers: []string{"stun:stun.example.com:3478"}})
+	return NewCallService(repo, provider, notifier, noopPusher{}, 30*time.Second), repo, notifier
+}
+
+func TestInitiateRingsOnlineCallee(t *testing.T) {
+	tests := []struct {
+		name       string
+		online     bool
+		wantStatus string
+	}{
+		{"callee offline", false, models.CallStatusInitiated},
+		{"callee online", true, models.CallStatusRinging},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, notifier := newTestService()
+			notifier.online[repo.callee] = tt.online
+
+			call, err := service.Initiate(context.Background(), uuid.New(), &models.InitiateCallRequest{MatchID: uuid.New()})
+			if err != nil {
+				t.Fatalf("Initiate() error = %v", err)
+			}
+			if call.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", call.Status, tt.wantStatus)
+			}
+			if len(call.ICEServers) != 1 || call.SessionID == "" {
+				t.Errorf("call has %d ICE servers and session %q, want them issued", len(call.ICEServers), call.SessionID)
+			}
+			if tt.online {
+				want := []string{models.RealtimeIncomingCall, models.RealtimeCallStatus}
+				if got := notifier.sent[repo.callee]; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+					t.Errorf("callee was sent %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCallTransitions(t *testing.T) {
+	tests := []struct {
+		name       string
+		from       string
+		byCaller   bool
+		act        func(CallService, uuid.UUID, uuid.UUID) (*models.VideoCall, error)
+		wantStatus string
+		wantReason string
+		wantErr    error
+	}{
+		{"callee accepts", models.CallStatusRinging, false, accept, models.CallStatusActive, "", nil},
+		{"caller cannot accept", models.CallStatusRinging, true, accept, "", "", ErrNotCallee},
+		{"callee rejects", models.CallStatusRinging, false, reject, models.CallStatusRejected, "", nil},
+		{"caller cannot reject", models.CallStatusInitiated, true, reject, "", "", ErrNotCallee},
+		{"cannot reject an active call", models.CallStatusActive, false, reject, "", "", postgres.ErrCallStateChanged},
+		{"cannot accept a missed call", models.CallStatusMissed, false, accept, "", "", postgres.ErrCallStateChanged},
+		{"hang up an active call", models.CallStatusActive, false, hangUp, models.CallStatusEnded, models.CallEndHangup, nil},
+		{"caller cancels while ringing", models.CallStatusRinging, true, hangUp, models.CallStatusEnded, models.CallEndCancelled, nil},
+		{"callee hangs up while ringing", models.CallStatusRinging, false, hangUp, models.CallStatusRejected, "", nil},
+		{"cannot end an ended call", models.CallStatusEnded, true, hangUp, "", "", postgres.ErrCallStateChanged},
+		{"report a failed connection", models.CallStatusActive, true, fail, models.CallStatusFailed, models.CallEndFailed, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, _ := newTestService()
+			call := &models.VideoCall{ID: uuid.New(), CallerID: uuid.New(), CalleeID: uuid.New(), Status: tt.from, CreatedAt: time.Now()}
+			repo.calls[call.ID] = call
+
+			actor := call.CalleeID
+			if tt.byCaller {
+				actor = call.CallerID
+			}
+			got, err := tt.act(service, actor, call.ID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if call.Status != tt.from {
+					t.Errorf("status changed to %s on error", call.Status)
+				}
+				return
+			}
+			if got.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got.Status, tt.wantStatus)
+			}
+			reason := ""
+			if got.EndReason != nil {
+				reason = *got.EndReason
+			}
+			if reason != tt.wantReason {
+				t.Errorf("end reason = %q, want %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestAcceptAfterRingTimeout(t *testing.T) {
+	service, repo, _ := newTestService()
+	call := &models.VideoCall{
+		ID: uuid.New(), CallerID: uuid.New(), CalleeID: uuid.New(),
+		Status: models.CallStatusRinging, CreatedAt: time.Now().Add(-31 * time.Second),
+	}
+	repo.calls[call.ID] = call
+
+	if _, err := accept(service, call.CalleeID, call.ID); !errors.Is(err, postgres.ErrCallStateChanged) {
+		t.Fatalf("error = %v, want ErrCallStateChanged for a call the sweeper has not marked missed yet", err)
+	}
+	if call.Status != models.CallStatusRinging {
+		t.Errorf("status = %s, want it left for the sweeper", call.Status)
+	}
+	if _, err := reject(service, call.CalleeID, call.ID); err != nil {
+		t.Errorf("reject error = %v, want declining still allowed", err)
+	}
+}
+
+func TestOutsiderCannotTouchCall(t *testing.T) {
+	service, repo, _ := newTestService()
+	call := &models.VideoCall{ID: uuid.New(), CallerID: uuid.New(), CalleeID: uuid.New(), Status: models.CallStatusActive}
+	repo.calls[call.ID] = call
+
+	if _, err := hangUp(service, uuid.New(), call.ID); !errors.Is(err, postgres.ErrCallNotFound) {
+		t.Errorf("error = %v, want ErrCallNotFound", err)
+	}
+}
+
+func accept(s CallService, userID, callID uuid.UUID) (*models.VideoCall, error) {
+	return s.Accept(context.Background(), userID, callID)
+}
+
+func reject(s CallService, userID, callID uuid.UUID) (*models.VideoCall, error) {
+	return s.Reject(context.Background(), userID, callID)
+}
+
+func hangUp(s CallService, userID, callID uuid.UUID) (*models.VideoCall, error) {
+	return s.End(context.Background(), userID, callID, &models.EndCallRequest{})
+}
+
+func fail(s CallService, userID, callID uuid.UUID) (*models.VideoCall, error) {
+	return s.End(context.Background(), userID, callID, &models.EndCallRequest{Failed: true})
+}
@@ -0,0 +1,107 @@
+package calls
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/alexcolls/findme/internal/metrics"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/events"
+	"github.com/alexcolls/findme/pkg/tracing"
+	"github.com/google/uuid"
+)
+
+const defaultSweeperBatchSize = 100
+
+type SweeperConfig struct {
+	Interval time.Duration
+	// RingTimeout is how long a call may go unanswered before it is missed
+	RingTimeout time.Duration
+	// MaxDuration ends active calls whose clients never hung up
+	MaxDuration time.Duration
+	BatchSize   int
+}
+
+// Sweeper marks unanswered calls missed and ends abandoned ones. Rows are
+// claimed with SKIP LOCKED, so replicas can sweep concurrently.
+type Sweeper struct {
+	callRepo postgres.CallRepository
+	notifier Notifier
+	events   events.Publisher
+	cfg      SweeperConfig
+}
+
+func NewSweeper(callRepo postgres.CallRepository, notifier Notifier, publisher events.Publisher, cfg SweeperConfig) *Sweeper {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultSweeperBatchSize
+	}
+	return &Sweeper{
+		callRepo: callRepo,
+		notifier: notifier,
+		events:   publisher,
+		cfg:      cfg,
+	}
+}
+
+// Start sweeps immediately and then on every tick until ctx is done.
+func (s *Sweeper) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			runCtx, span := tracing.StartJob(ctx, "call_sweeper")
+			start := time.Now()
+			err := s.sweep(runCtx)
+			tracing.End(span, err)
+			metrics.ObserveJob("call_sweeper", start, err)
+			if err != nil {
+				slog.ErrorContext(runCtx, "Call sweep failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *Sweeper) sweep(ctx context.Context) error {
+	for {
+		calls, err := s.callRepo.ExpireUnanswered(ctx, s.cfg.RingTimeout, s.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, call := range calls {
+			notifyStatus(s.notifier, call)
+			s.events.Publish(ctx, events.Event{
+				Type:    events.CallMissed,
+				UserIDs: []uuid.UUID{call.CalleeID},
+				Data:    call,
+			})
+		}
+		if len(calls) < s.cfg.BatchSize {
+			break
+		}
+	}
+
+	if s.cfg.MaxDuration <= 0 {
+		return nil
+	}
+	for {
+		calls, err := s.callRepo.EndStale(ctx, s.cfg.MaxDuration, s.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, call := range calls {
+			slog.InfoContext(ctx, "Ended call without a hangup", "call_id", call.ID, "max_duration", s.cfg.MaxDuration)
+			notifyStatus(s.notifier, call)
+		}
+		if len(calls) < s.cfg.BatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/contentsafety"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrEmptyMessage   = errors.New("message is empty")
+	ErrMessageTooLong = errors.New("message is too long")
+)
+
+// Notifier delivers realtime messages to a user's connected devices and
+// returns how many received them.
+type Notifier interface {
+	SendToUser(userID uuid.UUID, v interface{}) (int, error)
+}
+
+// Pusher sends mobile pushes to a user's devices, subject to their
+// preferences.
+type Pusher interface {
+	Send(ctx context.Context, userID uuid.UUID, category string, msg *models.PushMessage) (int, error)
+}
+
+// ContentFilter inspects messages before they are stored.
+type ContentFilter interface {
+	Check(content contentsafety.Content) contentsafety.Verdict
+}
+
+// Moderator queues held messages for review.
+type Moderator interface {
+	Enqueue(ctx context.Context, req *models.EnqueueModerationRequest) (*models.ModerationItem, error)
+}
+
+// ChatService lets the two users of a mutual match message each other.
+type ChatService interface {
+	ListConversations(ctx context.Context, userID uuid.UUID) ([]*models.ConversationSummary, error)
+	Messages(ctx context.Context, userID, conversationID uuid.UUID, filter models.MessageFilter) (*models.MessagePage, error)
+	Send(ctx context.Context, userID, conversationID uuid.UUID, req *models.SendMessageRequest) (*models.Message, error)
+	MarkRead(ctx context.Context, userID, conversationID uuid.UUID, req *models.MarkConversationReadRequest) error
+	DeleteMessage(ctx context.Context, userID, conversationID, messageID uuid.UUID) error
+
+	// OpenConversation starts the conversation of a match that just became
+	// mutual
+	OpenConversation(ctx context.Context, match *models.Match) error
+	// CloseMatchConversation closes the conversation of a match that ended
+	CloseMatchConversation(ctx context.Context, match *models.Match) error
+	// CloseConversationsBetween closes the conversations of two users after
+	// one blocked the other
+	CloseConversationsBetween(ctx context.Context, userID, otherUserID uuid.UUID) error
+	// ResolveHeld delivers a held message once moderation approves it, or
+	// tells its sender it was removed
+	ResolveHeld(ctx context.Context, messageID uuid.UUID, approved bool) error
+}
+
+type chatService struct {
+	repo             postgres.ChatRepository
+	notifier         Notifier
+	pusher           Pusher
+	filter           ContentFilter
+	moderator        Moderator
+	maxMessageLength int
+}
+
+func NewChatService(
+	repo postgres.ChatRepository,
+	notifier Notifier,
+	pusher Pusher,
+	filter ContentFilter,
+	moderator Moderator,
+	maxMessageLength int,
+) ChatService {
+	if maxMessageLength <= 0 {
+		maxMessageLength = 2000
+	}
+	return &chatService{
+		repo:             repo,
+		notifier:         notifier,
+		pusher:           pusher,
+		filter:           filter,
+		moderator:        moderator,
+		maxMessageLength: maxMessageLength,
+	}
+}
+
+func (s *chatService) ListConversations(ctx context.Context, userID uuid.UUID) ([]*models.ConversationSummary, error) {
+	return s.repo.ListConversations(ctx, userID)
+}
+
+// Messages returns a page of history. Fetching it counts as receiving the
+// other user's messages, so they get a delivery receipt.
+func (s *chatService) Messages(ctx context.Context, userID, conversationID uuid.UUID, filter models.MessageFilter) (*models.MessagePage, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+
+	conv, err := s.repo.GetConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	messages, next, err := s.repo.ListMessages(ctx, conv.ID, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	s.markDelivered(ctx, conv, userID)
+	return &models.MessagePage{Messages: messages, NextCursor: next}, nil
+}
+
+// Send runs the message through the content filter, stores it and delivers
+// it. Blocked messages are refused and held ones only reach the sender's
+// devices until a moderator approves them. The returned message carries the
+// categories the filter flagged so the app can warn the sender.
+func (s *chatService) Send(ctx context.Context, userID, conversationID uuid.UUID, req *models.SendMessageRequest) (*models.Message, error) {
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return nil, ErrEmptyMessage
+	}
+	if utf8.RuneCountInString(body) > s.maxMessageLength {
+		return nil, fmt.Errorf("%w: at most %d characters", ErrMessageTooLong, s.maxMessageLength)
+	}
+
+	conv, err := s.repo.GetConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.repo.CountMessages(ctx, conv.ID)
+	if err != nil {
+		return nil, err
+	}
+	verdict := s.filter.Check(contentsafety.Content{
+		Surface:      contentsafety.SurfaceMessage,
+		Text:         body,
+		Locale:       req.Locale,
+		MessageCount: count,
+	})
+	if err := verdict.Err(); err != nil {
+		return nil, err
+	}
+
+	held := verdict.Action == contentsafety.ActionHold
+	msg, err := s.repo.CreateMessage(ctx, conv.ID, userID, body, held)
+	if err != nil {
+		return nil, err
+	}
+
+	if held {
+		req := verdict.ModerationRequest(models.ModerationItemMessage, msg.ID, userID)
+		if _, err := s.moderator.Enqueue(ctx, req); err != nil {
+			// Without a queue item nobody would ever release the message
+			if _, delErr := s.repo.DeleteMessage(context.WithoutCancel(ctx), conv.ID, userID, msg.ID); delErr != nil {
+				slog.ErrorContext(ctx, "Failed to remove unqueued message", "message_id", msg.ID, "error", delErr)
+			}
+			return nil, fmt.Errorf("failed to queue message for moderation: %w", err)
+		}
+		s.syncToSender(msg)
+	} else {
+		s.deliver(ctx, conv, msg)
+	}
+
+	// Only the sender learns what was flagged
+	flagged := *msg
+	flagged.Flags = verdict.Categories()
+	return &flagged, nil
+}
+
+// deliver sends a stored message to both users' connected devices, the
+// sender's included so their other devices stay in sync. A recipient with
+// no connection gets a push instead.
+func (s *chatService) deliver(ctx context.Context, conv *models.Conversation, msg *models.Message) {
+	recipientID := conv.OtherUserID(msg.SenderID)
+
+	event := models.ServerMessage{Type: models.RealtimeChatMessage, Data: msg}
+	delivered, err := s.notifier.SendToUser(recipientID, event)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to deliver message", "message_id", msg.ID, "error", err)
+	}
+	s.syncToSender(msg)
+
+	if delivered > 0 {
+		s.markDelivered(ctx, conv, recipientID)
+	} else {
+		s.push(ctx, conv, recipientID)
+	}
+}
+
+func (s *chatService) syncToSender(msg *models.Message) {
+	event := models.ServerMessage{Type: models.RealtimeChatMessage, Data: msg}
+	if _, err := s.notifier.SendToUser(msg.SenderID, event); err != nil {
+		slog.Error("Failed to sync message to the sender", "message_id", msg.ID, "error", err)
+	}
+}
+
+func (s *chatService) MarkRead(ctx context.Context, userID, conversationID uuid.UUID, req *models.MarkConversationReadRequest) error {
+	conv, err := s.repo.GetConversation(ctx, userID, conversationID)
+	if err != nil {
+		return err
+	}
+	receipt, err := s.repo.MarkRead(ctx, conv.ID, userID, req.MessageID)
+	if err != nil {
+		return err
+	}
+	if receipt != nil {
+		s.sendReceipt(conv.OtherUserID(userID), receipt)
+	}
+	return nil
+}
+
+// DeleteMessage soft-deletes one of the user's own messages and tells both
+// users' devices to hide it.
+func (s *chatService) DeleteMessage(ctx context.Context, userID, conversationID, messageID uuid.UUID) error {
+	conv, err := s.repo.GetConversation(ctx, userID, conversationID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.repo.DeleteMessage(ctx, conv.ID, userID, messageID); err != nil {
+		return err
+	}
+
+	event := models.ServerMessage{
+		Type: models.RealtimeMessageDeleted,
+		Data: &models.MessageDeleted{ConversationID: conv.ID, MessageID: messageID},
+	}
+	for _, id := range []uuid.UUID{conv.UserID, conv.MatchedUserID} {
+		if _, err := s.notifier.SendToUser(id, event); err != nil {
+			slog.ErrorContext(ctx, "Failed to send message deletion", "message_id", messageID, "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *chatService) OpenConversation(ctx context.Context, match *models.Match) error {
+	return s.repo.CreateConversation(ctx, match)
+}
+
+func (s *chatService) CloseMatchConversation(ctx context.Context, match *models.Match) error {
+	conv, err := s.repo.CloseMatchConversation(ctx, match.ID, models.ConversationClosedMatchEnded)
+	if err != nil {
+		return err
+	}
+	if conv != nil {
+		s.notifyClosed(conv)
+	}
+	return nil
+}
+
+func (s *chatService) CloseConversationsBetween(ctx context.Context, userID, otherUserID uuid.UUID) error {
+	closed, err := s.repo.CloseConversationsBetween(ctx, userID, otherUserID, models.ConversationClosedBlocked)
+	if err != nil {
+		return err
+	}
+	for _, conv := range closed {
+		s.notifyClosed(conv)
+	}
+	return nil
+}
+
+func (s *chatService) ResolveHeld(ctx context.Context, messageID uuid.UUID, approved bool) error {
+	msg, err := s.repo.GetMessage(ctx, messageID)
+	if err != nil {
+		return err
+	}
+
+	if !approved {
+		_, err := s.notifier.SendToUser(msg.SenderID, models.ServerMessage{
+			Type: models.RealtimeMessageDeleted,
+			Data: &models.MessageDeleted{ConversationID: msg.ConversationID, MessageID: msg.ID},
+		})
+		return err
+	}
+	if msg.Held || msg.Deleted {
+		return nil
+	}
+	conv, err := s.repo.GetConversation(ctx, msg.SenderID, msg.ConversationID)
+	if err != nil {
+		return err
+	}
+	s.deliver(ctx, conv, msg)
+	return nil
+}
+
+// markDelivered records that recipientID received the conversation's
+// messages and sends the other user a receipt.
+func (s *chatService) markDelivered(ctx context.Context, conv *models.Conversation, recipientID uuid.UUID) {
+	receipt, err := s.repo.MarkDelivered(ctx, conv.ID, recipientID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to mark messages delivered", "conversation_id", conv.ID, "error", err)
+		return
+	}
+	if receipt != nil {
+		s.sendReceipt(conv.OtherUserID(recipientID), receipt)
+	}
+}
+
+func (s *chatService) sendReceipt(senderID uuid.UUID, receipt *models.MessageReceipt) {
+	_, err := s.notifier.SendToUser(senderID, models.ServerMessage{Type: models.RealtimeMessageReceipt, Data: receipt})
+	if err != nil {
+		slog.Error("Failed to send receipt", "status", receipt.Status, "conversation_id", receipt.ConversationID, "error", err)
+	}
+}
+
+// push tells an offline recipient about a new message without its text,
+// which could show on a locked screen.
+func (s *chatService) push(ctx context.Context, conv *models.Conversation, recipientID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	_, err := s.pusher.Send(ctx, recipientID, models.PushChatMessage, &models.PushMessage{
+		Title: "New message",
+		Body:  "Your match sent you a message.",
+		Data: map[string]string{
+			"type":            models.PushChatMessage,
+			"conversation_id": conv.ID.String(),
+			"match_id":        conv.MatchID.String(),
+		},
+		Priority: models.PushPriorityHigh,
+		// Several unread messages show as one push
+		CollapseKey: "chat:" + conv.ID.String(),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to push message", "conversation_id", conv.ID, "error", err)
+	}
+}
+
+func (s *chatService) notifyClosed(conv *models.Conversation) {
+	event := models.ServerMessage{
+		Type: models.RealtimeConversationClosed,
+		Data: &models.ConversationClosed{ConversationID: conv.ID, MatchID: conv.MatchID, Reason: *conv.CloseReason},
+	}
+	for _, id := range []uuid.UUID{conv.UserID, conv.MatchedUserID} {
+		if _, err := s.notifier.SendToUser(id, event); err != nil {
+			slog.Error("Failed to send conversation closure", "user_id", id, "conversation_id", conv.ID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/contentsafety"
+	"github.com/alexcolls/findme/internal/service/events"
+	"github.com/google/uuid"
+)
+
+// fakeChatRepo holds a single conversation.
+type fakeChatRepo struct {
+	postgres.ChatRepository
+	conv     *models.Conversation
+	messages []*models.Message
+}
+
+func (r *fakeChatRepo) GetConversation(ctx context.Context, userID, conversationID uuid.UUID) (*models.Conversation, error) {
+	if r.conv.ID != conversationID || !r.conv.IsParticipant(userID) {
+		return nil, postgres.ErrConversationNotFound
+	}
+	copied := *r.conv
+	return &copied, nil
+}
+
+func (r *fakeChatRepo) CreateMessage(ctx context.Context, conversationID, senderID uuid.UUID, body string, held bool) (*models.Message, error) {
+	if r.conv.ClosedAt != nil {
+		return nil, postgres.ErrConversationClosed
+	}
+	m := &models.Message{
+		ID: uuid.New(), ConversationID: conversationID, SenderID: senderID,
+		Body: body, Status: models.MessageSent, Held: held, CreatedAt: time.Now(),
+	}
+	r.messages = append(r.messages, m)
+	return m, nil
+}
+
+func (r *fakeChatRepo) GetMessage(ctx context.Context, messageID uuid.UUID) (*models.Message, error) {
+	for _, m := range r.messages {
+		if m.ID == messageID {
+			copied := *m
+			return &copied, nil
+		}
+	}
+	return nil, postgres.ErrMessageNotFound
+}
+
+func (r *fakeChatRepo) DeleteMessage(ctx context.Context, conversationID, senderID, messageID uuid.UUID) (*models.Message, error) {
+	for _, m := range r.messages {
+		if m.ID == messageID && m.SenderID == senderID && !m.Deleted {
+			m.Deleted = true
+			return m, nil
+		}
+	}
+	return nil, postgres.ErrMessageNotFound
+}
+
+func (r *fakeChatRepo) CountMessages(ctx context.Context, conversationID uuid.UUID) (int, error) {
+	return len(r.messages), nil
+}
+
+func (r *fakeChatRepo) MarkDelivered(ctx context.Context, conversationID, recipientID uuid.UUID) (*models.MessageReceipt, error) {
+	var receipt *models.MessageReceipt
+	for _, m := range r.messages {
+		if m.SenderID != recipientID && m.DeliveredAt == nil && !m.Held {
+			now := time.Now()
+			m.DeliveredAt = &now
+			receipt = &models.MessageReceipt{ConversationID: conversationID, MessageID: m.ID, Status: models.MessageDelivered, At: now}
+		}
+	}
+	return receipt, nil
+}
+
+func (r *fakeChatRepo) CloseMatchConversation(ctx context.Context, matchID uuid.UUID, reason string) (*models.Conversation, error) {
+	if r.conv.MatchID != matchID || r.conv.ClosedAt != nil {
+		return nil, nil
+	}
+	return r.close(reason), nil
+}
+
+func (r *fakeChatRepo) CloseConversationsBetween(ctx context.Context, userID, otherUserID uuid.UUID, reason string) ([]*models.Conversation, error) {
+	if !r.conv.IsParticipant(userID) || r.conv.OtherUserID(userID) != otherUserID || r.conv.ClosedAt != nil {
+		return nil, nil
+	}
+	return []*models.Conversation{r.close(reason)}, nil
+}
+
+func (r *fakeChatRepo) close(reason string) *models.Conversation {
+	now := time.Now()
+	r.conv.ClosedAt, r.conv.CloseReason = &now, &reason
+	copied := *r.conv
+	return &copied
+}
+
+type fakeNotifier struct {
+	online map[uuid.UUID]bool
+	sent   map[uuid.UUID][]models.ServerMessage
+}
+
+func (n *fakeNotifier) SendToUser(userID uuid.UUID, v interface{}) (int, error) {
+	if !n.online[userID] {
+		return 0, nil
+	}
+	n.sent[userID] = append(n.sent[userID], v.(models.ServerMessage))
+	return 1, nil
+}
+
+func (n *fakeNotifier) types(userID uuid.UUID) []string {
+	var types []string
+	for _, msg := range n.sent[userID] {
+		types = append(types, msg.Type)
+	}
+	return types
+}
+
+type fakePusher struct {
+	pushed map[uuid.UUID]int
+}
+
+func (p *fakePusher) Send(ctx context.Context, userID uuid.UUID, category string, msg *models.PushMessage) (int, error) {
+	p.pushed[userID]++
+	return 1, nil
+}
+
+type fakeModerator struct {
+	queued []*models.EnqueueModerationRequest
+	err    error
+}
+
+func (m *fakeModerator) Enqueue(ctx context.Context, req *models.EnqueueModerationRequest) (*models.ModerationItem, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.queued = append(m.queued, req)
+	return &models.ModerationItem{ItemType: req.ItemType, ItemID: req.ItemID}, nil
+}
+
+type testService struct {
+	ChatService
+	repo      *fakeChatRepo
+	notifier  *fakeNotifier
+	pusher    *fakePusher
+	moderator *fakeModerator
+}
+
+func newTestService(t *testing.T, maxMessageLength int) *testService {
+	t.Helper()
+	filter, err := contentsafety.NewFilter(contentsafety.Config{Enabled: true})
+	if err != nil {
+		t.Fatalf("NewFilter() error = %v", err)
+	}
+	ts := &testService{
+		repo: &fakeChatRepo{conv: &models.Conversation{
+			ID: uuid.New(), MatchID: uuid.New(), UserID: uuid.New(), MatchedUserID: uuid.New(),
+		}},
+		notifier:  &fakeNotifier{online: map[uuid.UUID]bool{}, sent: map[uuid.UUID][]models.ServerMessage{}},
+		pusher:    &fakePusher{pushed: map[uuid.UUID]int{}},
+		moderator: &fakeModerator{},
+	}
+	ts.ChatService = NewChatService(ts.repo, ts.notifier, ts.pusher, filter, ts.moderator, maxMessageLength)
+	return ts
+}
+
+func TestSendValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{"ok", "  hello  ", nil},
+		{"blank", "   ", ErrEmptyMessage},
+		{"too long", strings.Repeat("a", 11), ErrMessageTooLong},
+		{"multibyte at the limit", strings.Repeat("é", 10), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, 10)
+			msg, err := ts.Send(context.Background(), ts.repo.conv.UserID, ts.repo.conv.ID, &models.SendMessageRequest{Body: tt.body})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Send() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && msg.Body != strings.TrimSpace(tt.body) {
+				t.Errorf("body = %q, want it trimmed", msg.Body)
+			}
+		})
+	}
+
+	ts := newTestService(t, 10)
+	if _, err := ts.Send(context.Background(), uuid.New(), ts.repo.conv.ID, &models.SendMessageRequest{Body: "hi"}); !errors.Is(err, postgres.ErrConversationNotFound) {
+		t.Errorf("outsider Send() error = %v, want ErrConversationNotFound", err)
+	}
+}
+
+func TestSendDelivery(t *testing.T) {
+	tests := []struct {
+		name       string
+		online     bool
+		wantSender []string
+		wantPushes int
+		wantStatus string
+	}{
+		{"recipient online", true, []string{models.RealtimeChatMessage, models.RealtimeMessageReceipt}, 0, models.MessageDelivered},
+		{"recipient offline", false, []string{models.RealtimeChatMessage}, 1, models.MessageSent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, 10)
+			sender, recipient := ts.repo.conv.UserID, ts.repo.conv.MatchedUserID
+			ts.notifier.online[sender] = true
+			ts.notifier.online[recipient] = tt.online
+
+			if _, err := ts.Send(context.Background(), sender, ts.repo.conv.ID, &models.SendMessageRequest{Body: "hi"}); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if got := ts.notifier.types(sender); strings.Join(got, ",") != strings.Join(tt.wantSender, ",") {
+				t.Errorf("sender got %v, want %v", got, tt.wantSender)
+			}
+			if ts.pusher.pushed[recipient] != tt.wantPushes {
+				t.Errorf("pushed %d times, want %d", ts.pusher.pushed[recipient], tt.wantPushes)
+			}
+			if delivered := ts.repo.messages[0].DeliveredAt != nil; delivered != (tt.wantStatus == models.MessageDelivered) {
+				t.Errorf("delivered = %v, want status %s", delivered, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestConversationClosesOnEvents(t *testing.T) {
+	tests := []struct {
+		name       string
+		event      func(conv *models.Conversation) events.Event
+		wantReason string
+	}{
+		{"match ended", func(conv *models.Conversation) events.Event {
+			return events.Event{Type: events.MatchCompleted, Data: &models.Match{ID: conv.MatchID}}
+		}, models.ConversationClosedMatchEnded},
+		{"user blocked", func(conv *models.Conversation) events.Event {
+			return events.Event{Type: events.UserBlocked, Data: &models.Block{BlockerID: conv.MatchedUserID, BlockedID: conv.UserID}}
+		}, models.ConversationClosedBlocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, 10)
+			ts.notifier.online[ts.repo.conv.UserID] = true
+			bus := events.NewBus()
+			Subscribe(bus, ts)
+
+			bus.Publish(context.Background(), tt.event(ts.repo.conv))
+
+			if ts.repo.conv.CloseReason == nil || *ts.repo.conv.CloseReason != tt.wantReason {
+				t.Fatalf("close reason = %v, want %s", ts.repo.conv.CloseReason, tt.wantReason)
+			}
+			sent := ts.notifier.sent[ts.repo.conv.UserID]
+			if len(sent) != 1 || sent[0].Type != models.RealtimeConversationClosed {
+				t.Errorf("sent %v, want conversation_closed", ts.notifier.types(ts.repo.conv.UserID))
+			}
+			_, err := ts.Send(context.Background(), ts.repo.conv.UserID, ts.repo.conv.ID, &models.SendMessageRequest{Body: "hi"})
+			if !errors.Is(err, postgres.ErrConversationClosed) {
+				t.Errorf("Send() after close error = %v, want ErrConversationClosed", err)
+			}
+		})
+	}
+}
+
+func TestSendContentSafety(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		earlier       int
+		wantErr       error
+		wantFlags     []string
+		wantDelivered bool
+		wantQueued    bool
+	}{
+		{"clean", "dinner on friday?", 0, nil, nil, true, false},
+		{"phone early on", "text me on 555 123 4567", 0, nil, []string{"phone"}, true, false},
+		{"phone later on", "text me on 555 123 4567", 25, nil, nil, true, false},
+		{"harassment held", "just kys", 0, nil, []string{"harassment"}, false, true},
+		{"payment blocked", "can you zelle me for the ticket", 0, contentsafety.ErrContentBlocked, nil, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, 100)
+			sender, recipient := ts.repo.conv.UserID, ts.repo.conv.MatchedUserID
+			for i := 0; i < tt.earlier; i++ {
+				ts.repo.messages = append(ts.repo.messages, &models.Message{ID: uuid.New(), SenderID: recipient})
+			}
+			ts.notifier.online[recipient] = true
+
+			msg, err := ts.Send(context.Background(), sender, ts.repo.conv.ID, &models.SendMessageRequest{Body: tt.body})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Send() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && strings.Join(msg.Flags, ",") != strings.Join(tt.wantFlags, ",") {
+				t.Errorf("flags = %v, want %v", msg.Flags, tt.wantFlags)
+			}
+			if delivered := len(ts.notifier.sent[recipient]) > 0; delivered != tt.wantDelivered {
+				t.Errorf("delivered = %v, want %v", delivered, tt.wantDelivered)
+			}
+			if queued := len(ts.moderator.queued) > 0; queued != tt.wantQueued {
+				t.Errorf("queued = %v, want %v", queued, tt.wantQueued)
+			}
+		})
+	}
+}
+
+func TestSendHeldEnqueueFailure(t *testing.T) {
+	ts := newTestService(t, 100)
+	sender, recipient := ts.repo.conv.UserID, ts.repo.conv.MatchedUserID
+	ts.notifier.online[sender] = true
+	ts.notifier.online[recipient] = true
+	errQueue := errors.New("connection refused")
+	ts.moderator.err = errQueue
+
+	if _, err := ts.Send(context.Background(), sender, ts.repo.conv.ID, &models.SendMessageRequest{Body: "just kys"}); !errors.Is(err, errQueue) {
+		t.Fatalf("Send() error = %v, want the queue error", err)
+	}
+	if len(ts.repo.messages) != 1 || !ts.repo.messages[0].Deleted {
+		t.Errorf("messages = %+v, want the unqueued message deleted", ts.repo.messages)
+	}
+	if got := len(ts.notifier.sent[sender]) + len(ts.notifier.sent[recipient]); got != 0 {
+		t.Errorf("sent %d realtime events, want none for a failed send", got)
+	}
+}
+
+func TestResolveHeld(t *testing.T) {
+	tests := []struct {
+		name          string
+		approved      bool
+		wantRecipient []string
+		wantSender    string
+	}{
+		{"approved", true, []string{models.RealtimeChatMessage}, models.RealtimeChatMessage},
+		{"rejected", false, nil, models.RealtimeMessageDeleted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := newTestService(t, 100)
+			sender, recipient := ts.repo.conv.UserID, ts.repo.conv.MatchedUserID
+			ts.notifier.online[sender] = true
+			ts.notifier.online[recipient] = true
+			bus := events.NewBus()
+			Subscribe(bus, ts)
+
+			msg, err := ts.Send(context.Background(), sender, ts.repo.conv.ID, &models.SendMessageRequest{Body: "just kys"})
+			if err != nil || !msg.Held {
+				t.Fatalf("Send() = %+v, %v; want a held message", msg, err)
+			}
+			// The moderation decision clears or deletes the message
+			ts.repo.messages[0].Held = false
+			ts.repo.messages[0].Deleted = !tt.approved
+			status := models.ModerationStatusRejected
+			if tt.approved {
+				status = models.ModerationStatusApproved
+			}
+			bus.Publish(context.Background(), events.Event{
+				Type: events.MessageModerated,
+				Data: &models.ModerationItem{ItemType: models.ModerationItemMessage, ItemID: msg.ID, Status: status},
+			})
+
+			if got := ts.notifier.types(recipient); strings.Join(got, ",") != strings.Join(tt.wantRecipient, ",") {
+				t.Errorf("recipient got %v, want %v", got, tt.wantRecipient)
+			}
+			// The first message the sender got was the echo of the held message
+			if sent := ts.notifier.types(sender); len(sent) < 2 || sent[1] != tt.wantSender {
+				t.Errorf("sender got %v, want %s after the echo", sent, tt.wantSender)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/service/events"
+)
+
+// eventTimeout bounds the conversation update made for one event.
+const eventTimeout = 10 * time.Second
+
+// Subscribe opens a conversation when a match becomes mutual, closes it when
+// the match ends or either user blocks the other, and settles held messages
+// once moderators decide on them.
+func Subscribe(bus *events.Bus, service ChatService) {
+	bus.Subscribe(events.MatchMutual, func(ctx context.Context, event events.Event) {
+		handleMatch(ctx, event, service.OpenConversation)
+	})
+	bus.Subscribe(events.MatchCompleted, func(ctx context.Context, event events.Event) {
+		handleMatch(ctx, event, service.CloseMatchConversation)
+	})
+	bus.Subscribe(events.UserBlocked, func(ctx context.Context, event events.Event) {
+		block, ok := event.Data.(*models.Block)
+		if !ok {
+			slog.WarnContext(ctx, "Skipping conversations: unexpected event data", "event", event.Type, "data", fmt.Sprintf("%T", event.Data))
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), eventTimeout)
+		defer cancel()
+		if err := service.CloseConversationsBetween(ctx, block.BlockerID, block.BlockedID); err != nil {
+			slog.ErrorContext(ctx, "Failed to close conversations after block", "blocker_id", block.BlockerID, "error", err)
+		}
+	})
+	bus.Subscribe(events.MessageModerated, func(ctx context.Context, event events.Event) {
+		item, ok := event.Data.(*models.ModerationItem)
+		if !ok {
+			slog.WarnContext(ctx, "Skipping held message: unexpected event data", "event", event.Type, "data", fmt.Sprintf("%T", event.Data))
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), eventTimeout)
+		defer cancel()
+		approved := item.Status == models.ModerationStatusApproved
+		if err := service.ResolveHeld(ctx, item.ItemID, approved); err != nil {
+			slog.ErrorContext(ctx, "Failed to settle held message", "message_id", item.ItemID, "error", err)
+		}
+	})
+}
+
+func handleMatch(ctx context.Context, event events.Event, apply func(context.Context, *models.Match) error) {
+	match, ok := event.Data.(*models.Match)
+	if !ok {
+		slog.WarnContext(ctx, "Skipping conversation: unexpected event data", "event", event.Type, "data", fmt.Sprintf("%T", event.Data))
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), eventTimeout)
+	defer cancel()
+	if err := apply(ctx, match); err != nil {
+		slog.ErrorContext(ctx, "Failed to update conversation of match", "match_id", match.ID, "event", event.Type, "error", err)
+	}
+}
@@ -0,0 +1,77 @@
+package contentsafety
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+)
+
+// Moderator queues held content for review.
+type Moderator interface {
+	Enqueue(ctx context.Context, req *models.EnqueueModerationRequest) (*models.ModerationItem, error)
+}
+
+// bioScreeningRepository checks bios before UserRepository.Update stores
+// them, so no caller can skip the filter.
+type bioScreeningRepository struct {
+	postgres.UserRepository
+	filter    *Filter
+	moderator Moderator
+}
+
+// ScreenBios wraps repo so updates with a blocked bio fail with
+// ErrContentBlocked and held bios are kept aside as the pending bio and
+// queued for moderation; the profile shows its previous bio until the item
+// is approved. If queueing fails the previous profile is restored and the
+// update fails.
+func ScreenBios(repo postgres.UserRepository, filter *Filter, moderator Moderator) postgres.UserRepository {
+	return &bioScreeningRepository{UserRepository: repo, filter: filter, moderator: moderator}
+}
+
+func (r *bioScreeningRepository) Update(ctx context.Context, user *models.User) error {
+	if user.Bio == nil || *user.Bio == "" {
+		return r.UserRepository.Update(ctx, user)
+	}
+
+	verdict := r.filter.Check(Content{Surface: SurfaceBio, Text: *user.Bio})
+	if err := verdict.Err(); err != nil {
+		return err
+	}
+	if verdict.Action != ActionHold {
+		return r.UserRepository.Update(ctx, user)
+	}
+
+	// Keep the stored profile so it can be put back if the bio can't be
+	// queued; a held bio nobody reviews would never be promoted
+	previous, err := r.UserRepository.GetByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	held := user.Bio
+	user.Bio, user.PendingBio = previous.Bio, held
+	if err := r.UserRepository.Update(ctx, user); err != nil {
+		return err
+	}
+	if err := r.UserRepository.SetPendingBio(ctx, user.ID, held); err != nil {
+		return err
+	}
+	req := verdict.ModerationRequest(models.ModerationItemBio, user.ID, user.ID)
+	if _, err := r.moderator.Enqueue(ctx, req); err != nil {
+		r.restore(context.WithoutCancel(ctx), previous)
+		return fmt.Errorf("failed to queue bio for moderation: %w", err)
+	}
+	return nil
+}
+
+func (r *bioScreeningRepository) restore(ctx context.Context, previous *models.User) {
+	if err := r.UserRepository.Update(ctx, previous); err != nil {
+		slog.ErrorContext(ctx, "Failed to restore unqueued bio", "user_id", previous.ID, "error", err)
+		return
+	}
+	if err := r.UserRepository.SetPendingBio(ctx, previous.ID, previous.PendingBio); err != nil {
+		slog.ErrorContext(ctx, "Failed to restore pending bio", "user_id", previous.ID, "error", err)
+	}
+}
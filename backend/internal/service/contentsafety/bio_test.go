@@ -0,0 +1,105 @@
+package contentsafety
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+// fakeUsers stores a single user by value so updates can't alias the caller's.
+type fakeUsers struct {
+	postgres.UserRepository
+	stored models.User
+}
+
+func bioOf(bio *string) string {
+	if bio == nil {
+		return ""
+	}
+	return *bio
+}
+
+func (r *fakeUsers) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user := r.stored
+	return &user, nil
+}
+
+// Update keeps the stored pending bio unless the bio changes, like the real
+// query.
+func (r *fakeUsers) Update(ctx context.Context, user *models.User) error {
+	pending := r.stored.PendingBio
+	if bioOf(user.Bio) != bioOf(r.stored.Bio) {
+		pending = nil
+	}
+	r.stored = *user
+	r.stored.PendingBio = pending
+	return nil
+}
+
+func (r *fakeUsers) SetPendingBio(ctx context.Context, id uuid.UUID, bio *string) error {
+	r.stored.PendingBio = bio
+	return nil
+}
+
+type fakeModerator struct {
+	queued []*models.EnqueueModerationRequest
+	err    error
+}
+
+func (m *fakeModerator) Enqueue(ctx context.Context, req *models.EnqueueModerationRequest) (*models.ModerationItem, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.queued = append(m.queued, req)
+	return &models.ModerationItem{ItemType: req.ItemType, ItemID: req.ItemID}, nil
+}
+
+func TestScreenBios(t *testing.T) {
+	filter, err := NewFilter(Config{Enabled: true})
+	if err != nil {
+		t.Fatalf("NewFilter() error = %v", err)
+	}
+	errQueue := errors.New("connection refused")
+
+	tests := []struct {
+		name        string
+		bio         string
+		queueErr    error
+		wantErr     error
+		wantBio     string
+		wantPending string
+		wantQueued  bool
+	}{
+		{"clean", "Hiking and jazz", nil, nil, "Hiking and jazz", "", false},
+		{"blocked", "insta: example.com/jane, 555 123 4567", nil, ErrContentBlocked, "Old bio", "Earlier held bio", false},
+		{"held", "no bullshit, no bitch drama", nil, nil, "Old bio", "no bullshit, no bitch drama", true},
+		{"held but not queued", "no bullshit, no bitch drama", errQueue, errQueue, "Old bio", "Earlier held bio", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldBio, oldPending := "Old bio", "Earlier held bio"
+			users := &fakeUsers{stored: models.User{ID: uuid.New(), FullName: "Jane", Bio: &oldBio, PendingBio: &oldPending}}
+			moderator := &fakeModerator{err: tt.queueErr}
+			repo := ScreenBios(users, filter, moderator)
+
+			bio := tt.bio
+			err := repo.Update(context.Background(), &models.User{ID: users.stored.ID, FullName: "Jane", Bio: &bio})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := bioOf(users.stored.Bio); got != tt.wantBio {
+				t.Errorf("stored bio = %q, want %q", got, tt.wantBio)
+			}
+			if got := bioOf(users.stored.PendingBio); got != tt.wantPending {
+				t.Errorf("pending bio = %q, want %q", got, tt.wantPending)
+			}
+			if queued := len(moderator.queued) > 0; queued != tt.wantQueued {
+				t.Errorf("queued = %v, want %v", queued, tt.wantQueued)
+			}
+		})
+	}
+}
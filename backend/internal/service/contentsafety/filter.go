@@ -0,0 +1,202 @@
+package contentsafety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/google/uuid"
+)
+
+var ErrContentBlocked = errors.New("content is not allowed")
+
+// Content is a piece of user-written text to inspect.
+type Content struct {
+	Surface string
+	Text    string
+	// Locale picks the word lists to apply; empty applies all of them
+	Locale string
+	// MessageCount is how many messages the conversation already holds,
+	// for chat messages
+	MessageCount int
+}
+
+// Finding is one problem an inspector found. Match is the offending text,
+// kept for moderators.
+type Finding struct {
+	Category string `json:"category"`
+	Match    string `json:"match"`
+}
+
+// Verdict is the outcome of inspecting content: the most severe action of
+// its findings.
+type Verdict struct {
+	Action   string
+	Findings []Finding
+}
+
+// Categories returns the distinct categories found, sorted.
+func (v Verdict) Categories() []string {
+	seen := map[string]bool{}
+	var categories []string
+	for _, f := range v.Findings {
+		if !seen[f.Category] {
+			seen[f.Category] = true
+			categories = append(categories, f.Category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// Err returns ErrContentBlocked naming the categories found if the content
+// must not be stored.
+func (v Verdict) Err() error {
+	if v.Action != ActionBlock {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrContentBlocked, strings.Join(v.Categories(), ", "))
+}
+
+// ModerationRequest queues held content for review. Harassment goes ahead
+// of the rest of the queue.
+func (v Verdict) ModerationRequest(itemType string, itemID, userID uuid.UUID) *models.EnqueueModerationRequest {
+	priority := 50
+	categories := v.Categories()
+	for _, category := range categories {
+		if category == CategoryHarassment {
+			priority = 80
+		}
+	}
+	return &models.EnqueueModerationRequest{
+		ItemType: itemType,
+		ItemID:   itemID,
+		UserID:   &userID,
+		Priority: priority,
+		Source:   "auto",
+		Reason:   "content_filter",
+		Metadata: map[string]interface{}{
+			"categories": categories,
+			"findings":   v.Findings,
+		},
+	}
+}
+
+type Config struct {
+	Enabled bool
+	// RulesFile replaces the built-in rules when set and is reloaded when
+	// it changes
+	RulesFile      string
+	ReloadInterval time.Duration
+}
+
+// Filter runs content through a pipeline of inspectors and decides what to
+// do with it according to rules that can be swapped while it runs.
+type Filter struct {
+	cfg        Config
+	inspectors []Inspector
+	rules      atomic.Pointer[Rules]
+	modTime    time.Time
+}
+
+// NewFilter loads the rules and returns a filter running the given
+// inspectors, or DefaultInspectors if there are none.
+func NewFilter(cfg Config, inspectors ...Inspector) (*Filter, error) {
+	if len(inspectors) == 0 {
+		inspectors = DefaultInspectors()
+	}
+	f := &Filter{cfg: cfg, inspectors: inspectors}
+
+	if cfg.RulesFile == "" {
+		rules, err := DefaultRules()
+		if err != nil {
+			return nil, err
+		}
+		f.rules.Store(rules)
+		return f, nil
+	}
+	if _, err := f.reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Check inspects content. Contact details only count in chat while the
+// conversation is young.
+func (f *Filter) Check(content Content) Verdict {
+	verdict := Verdict{Action: ActionAllow}
+	if !f.cfg.Enabled {
+		return verdict
+	}
+
+	rules := f.rules.Load()
+	early := content.MessageCount < rules.EarlyConversationMessages
+	for _, inspector := range f.inspectors {
+		for _, finding := range inspector.Inspect(content, rules) {
+			if content.Surface == SurfaceMessage && contactCategories[finding.Category] && !early {
+				continue
+			}
+			action := rules.action(content.Surface, finding.Category)
+			if action == ActionAllow {
+				continue
+			}
+			verdict.Findings = append(verdict.Findings, finding)
+			if actionSeverity[action] > actionSeverity[verdict.Action] {
+				verdict.Action = action
+			}
+		}
+	}
+	return verdict
+}
+
+// Start reloads the rules file whenever it changes until ctx is done. A file
+// that fails to load is logged and the rules in force are kept.
+func (f *Filter) Start(ctx context.Context) {
+	if f.cfg.RulesFile == "" || f.cfg.ReloadInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(f.cfg.ReloadInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			reloaded, err := f.reload()
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to reload content rules", "file", f.cfg.RulesFile, "error", err)
+			} else if reloaded {
+				slog.InfoContext(ctx, "Reloaded content rules", "file", f.cfg.RulesFile)
+			}
+		}
+	}()
+}
+
+// reload loads the rules file if it changed since it was last loaded.
+func (f *Filter) reload() (bool, error) {
+	info, err := os.Stat(f.cfg.RulesFile)
+	if err != nil {
+		return false, err
+	}
+	if info.ModTime().Equal(f.modTime) {
+		return false, nil
+	}
+	// A broken file is reported once, not on every tick until it is fixed
+	f.modTime = info.ModTime()
+	rules, err := LoadRules(f.cfg.RulesFile)
+	if err != nil {
+		return false, err
+	}
+	f.rules.Store(rules)
+	return true, nil
+}
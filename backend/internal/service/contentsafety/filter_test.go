@@ -0,0 +1,120 @@
+package contentsafety
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+	filter, err := NewFilter(Config{Enabled: true})
+	if err != nil {
+		t.Fatalf("NewFilter() error = %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		content        Content
+		wantAction     string
+		wantCategories string
+	}{
+		{"clean", Content{Surface: SurfaceMessage, Text: "Loved your hiking photos!"}, ActionAllow, ""},
+		{"disguised profanity", Content{Surface: SurfaceMessage, Text: "sh1t, I'm late"}, ActionWarn, "profanity"},
+		{"stretched profanity", Content{Surface: SurfaceMessage, Text: "shiiiit"}, ActionWarn, "profanity"},
+		{"word inside another", Content{Surface: SurfaceMessage, Text: "I grew up in Scunthorpe"}, ActionAllow, ""},
+		{"harassment phrase", Content{Surface: SurfaceMessage, Text: "Just KILL yourself."}, ActionHold, "harassment"},
+		{"locale list", Content{Surface: SurfaceMessage, Text: "eres una zorra", Locale: "es-MX"}, ActionHold, "harassment"},
+		{"other locale list", Content{Surface: SurfaceMessage, Text: "eres una zorra", Locale: "en-US"}, ActionAllow, ""},
+		{"phone", Content{Surface: SurfaceMessage, Text: "call me +1 (555) 123-4567"}, ActionWarn, "phone"},
+		{"date is not a phone", Content{Surface: SurfaceMessage, Text: "free on 2026-10-18?"}, ActionAllow, ""},
+		{"email", Content{Surface: SurfaceMessage, Text: "write to jane.doe@example.com"}, ActionWarn, "email"},
+		{"spelled out email", Content{Surface: SurfaceMessage, Text: "janedoe at gmail"}, ActionWarn, "email"},
+		{"link", Content{Surface: SurfaceMessage, Text: "see https://example.org/me"}, ActionHold, "link"},
+		{"messenger", Content{Surface: SurfaceMessage, Text: "add me on Telegram"}, ActionHold, "link"},
+		{"cashtag", Content{Surface: SurfaceMessage, Text: "send it to $janedoe"}, ActionBlock, "payment"},
+		{"amount is not a cashtag", Content{Surface: SurfaceMessage, Text: "tickets are $20"}, ActionAllow, ""},
+		{"contact after early messages", Content{Surface: SurfaceMessage, Text: "call me 555 123 4567", MessageCount: 20}, ActionAllow, ""},
+		{"abuse after early messages", Content{Surface: SurfaceMessage, Text: "kys", MessageCount: 20}, ActionHold, "harassment"},
+		{"bio contact", Content{Surface: SurfaceBio, Text: "insta: example.com/jane, 555 123 4567"}, ActionBlock, "link, phone"},
+		{"bio profanity", Content{Surface: SurfaceBio, Text: "no bullshit, no bitch drama"}, ActionHold, "profanity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := filter.Check(tt.content)
+			if verdict.Action != tt.wantAction {
+				t.Errorf("action = %s, want %s (findings %v)", verdict.Action, tt.wantAction, verdict.Findings)
+			}
+			if got := strings.Join(verdict.Categories(), ", "); got != tt.wantCategories {
+				t.Errorf("categories = %q, want %q", got, tt.wantCategories)
+			}
+		})
+	}
+}
+
+func TestVerdictErr(t *testing.T) {
+	blocked := Verdict{Action: ActionBlock, Findings: []Finding{{Category: CategoryPayment}, {Category: CategoryLink}}}
+	if err := blocked.Err(); !errors.Is(err, ErrContentBlocked) || !strings.Contains(err.Error(), "link, payment") {
+		t.Errorf("Err() = %v, want ErrContentBlocked naming link, payment", err)
+	}
+	if err := (Verdict{Action: ActionHold}).Err(); err != nil {
+		t.Errorf("held Err() = %v, want nil", err)
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	filter, err := NewFilter(Config{Enabled: false})
+	if err != nil {
+		t.Fatalf("NewFilter() error = %v", err)
+	}
+	if verdict := filter.Check(Content{Surface: SurfaceBio, Text: "kys"}); verdict.Action != ActionAllow {
+		t.Errorf("disabled filter action = %s, want allow", verdict.Action)
+	}
+}
+
+func TestRulesReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	write := func(rules string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(rules), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := time.Now().Add(-time.Hour)
+	write(`{"actions": {"message": {"spam": "warn"}}, "words": {"*": {"spam": ["buy followers"]}}}`, start)
+
+	filter, err := NewFilter(Config{Enabled: true, RulesFile: path, ReloadInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewFilter() error = %v", err)
+	}
+	content := Content{Surface: SurfaceMessage, Text: "buy followers here"}
+	if got := filter.Check(content).Action; got != ActionWarn {
+		t.Fatalf("action = %s, want warn", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	filter.Start(ctx)
+
+	// A broken file keeps the rules in force
+	write(`{"actions": {"message": {"spam": "explode"}}}`, start.Add(time.Minute))
+	time.Sleep(50 * time.Millisecond)
+	if got := filter.Check(content).Action; got != ActionWarn {
+		t.Fatalf("action after broken file = %s, want warn", got)
+	}
+
+	write(`{"actions": {"message": {"spam": "block"}}, "words": {"*": {"spam": ["buy followers"]}}}`, start.Add(2*time.Minute))
+	deadline := time.Now().Add(time.Second)
+	for filter.Check(content).Action != ActionBlock {
+		if time.Now().After(deadline) {
+			t.Fatal("rules were not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
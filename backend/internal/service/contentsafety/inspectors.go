@@ -0,0 +1,158 @@
+package contentsafety
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Inspector finds problems in a piece of content under the rules in force.
+type Inspector interface {
+	Inspect(content Content, rules *Rules) []Finding
+}
+
+// InspectorFunc adapts a function to Inspector.
+type InspectorFunc func(content Content, rules *Rules) []Finding
+
+func (f InspectorFunc) Inspect(content Content, rules *Rules) []Finding {
+	return f(content, rules)
+}
+
+// DefaultInspectors returns the word lists followed by the contact detectors.
+func DefaultInspectors() []Inspector {
+	return []Inspector{
+		InspectorFunc(inspectWords),
+		InspectorFunc(inspectEmails),
+		InspectorFunc(inspectPhones),
+		InspectorFunc(inspectLinks),
+		InspectorFunc(inspectPaymentHandles),
+	}
+}
+
+// leet undoes the substitutions used to slip words past a list.
+var leet = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s")
+
+// normalize lowercases text into space-separated words with a space at
+// either end, so a phrase matches on whole words with strings.Contains. Digits
+// and symbols inside words are read as the letters they stand in for and
+// runs of a repeated letter are shortened ("fuuuck").
+func normalize(text string) string {
+	var b strings.Builder
+	b.WriteByte(' ')
+	for _, field := range strings.FieldsFunc(strings.ToLower(text), unicode.IsSpace) {
+		if strings.IndexFunc(field, unicode.IsLetter) >= 0 {
+			field = leet.Replace(field)
+		}
+		word := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return ' '
+		}, field)
+		for _, part := range strings.Fields(word) {
+			b.WriteString(squeeze(part))
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
+// squeeze shortens runs of three or more of the same rune to one.
+func squeeze(word string) string {
+	runes := []rune(word)
+	out := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && runes[j] == runes[i] {
+			j++
+		}
+		if j-i >= 3 {
+			out = append(out, runes[i])
+		} else {
+			out = append(out, runes[i:j]...)
+		}
+		i = j
+	}
+	return string(out)
+}
+
+func inspectWords(content Content, rules *Rules) []Finding {
+	text := normalize(content.Text)
+	var findings []Finding
+	for _, lists := range rules.wordLists(content.Locale) {
+		for category, words := range lists {
+			for _, word := range words {
+				if strings.Contains(text, word) {
+					findings = append(findings, Finding{Category: category, Match: strings.TrimSpace(word)})
+				}
+			}
+		}
+	}
+	return findings
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[a-z0-9._%+-]+@[a-z0-9-]+(?:\.[a-z0-9-]+)*\.[a-z]{2,}`)
+	// Addresses spelled out to dodge the plain pattern, as in "jane at gmail"
+	obfuscatedEmailPattern = regexp.MustCompile(
+		`[a-z0-9._%+-]+\s*(?:\(at\)|\[at\]|\bat\b)\s*(?:gmail|hotmail|yahoo|outlook|icloud|protonmail|proton|live|aol)\b`,
+	)
+	phonePattern = regexp.MustCompile(`\+?\(?\d[\d\s().-]{5,}\d`)
+	datePattern  = regexp.MustCompile(`^\d{4}[-./]\d{1,2}[-./]\d{1,2}$|^\d{1,2}[-./]\d{1,2}[-./]\d{2,4}$`)
+	linkPattern  = regexp.MustCompile(
+		`https?://\S+|www\.\S+|\b[a-z0-9-]+(?:\.[a-z0-9-]+)*\.(?:com|net|org|io|me|co|app|ly|gg|link|xyz|info|biz|ru|tk|site|online|club|top|live)\b(?:/\S*)?`,
+	)
+	// Cash App style "$cashtag" handles; amounts like "$20" do not match
+	cashtagPattern = regexp.MustCompile(`(?:^|\s)\$[a-z][a-z0-9_]{1,19}\b`)
+)
+
+func inspectEmails(content Content, rules *Rules) []Finding {
+	text := strings.ToLower(content.Text)
+	matches := emailPattern.FindAllString(text, -1)
+	matches = append(matches, obfuscatedEmailPattern.FindAllString(text, -1)...)
+	return findingsOf(CategoryEmail, matches)
+}
+
+// inspectPhones flags runs of 7 to 15 digits, the lengths of real numbers
+// with or without a country code, written with any separators except for
+// dates.
+func inspectPhones(content Content, rules *Rules) []Finding {
+	var matches []string
+	for _, match := range phonePattern.FindAllString(content.Text, -1) {
+		digits := len(strings.Map(keepDigits, match))
+		if digits >= 7 && digits <= 15 && !datePattern.MatchString(strings.TrimSpace(match)) {
+			matches = append(matches, strings.TrimSpace(match))
+		}
+	}
+	return findingsOf(CategoryPhone, matches)
+}
+
+func keepDigits(r rune) rune {
+	if r >= '0' && r <= '9' {
+		return r
+	}
+	return -1
+}
+
+// inspectLinks flags URLs and bare domains, leaving out email addresses,
+// which inspectEmails reports.
+func inspectLinks(content Content, rules *Rules) []Finding {
+	text := emailPattern.ReplaceAllString(strings.ToLower(content.Text), " ")
+	return findingsOf(CategoryLink, linkPattern.FindAllString(text, -1))
+}
+
+func inspectPaymentHandles(content Content, rules *Rules) []Finding {
+	var matches []string
+	for _, match := range cashtagPattern.FindAllString(strings.ToLower(content.Text), -1) {
+		matches = append(matches, strings.TrimSpace(match))
+	}
+	return findingsOf(CategoryPayment, matches)
+}
+
+func findingsOf(category string, matches []string) []Finding {
+	findings := make([]Finding, 0, len(matches))
+	for _, match := range matches {
+		findings = append(findings, Finding{Category: category, Match: match})
+	}
+	return findings
+}
@@ -0,0 +1,156 @@
+package contentsafety
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Actions taken on inspected content, from least to most severe
+const (
+	ActionAllow = "allow"
+	ActionWarn  = "warn"
+	ActionHold  = "hold"
+	ActionBlock = "block"
+)
+
+var actionSeverity = map[string]int{
+	ActionAllow: 0,
+	ActionWarn:  1,
+	ActionHold:  2,
+	ActionBlock: 3,
+}
+
+// Surfaces where user-written content is inspected
+const (
+	SurfaceMessage = "message"
+	SurfaceBio     = "bio"
+)
+
+// Finding categories. Word lists may add categories of their own.
+const (
+	CategoryProfanity  = "profanity"
+	CategoryHarassment = "harassment"
+	CategoryPhone      = "phone"
+	CategoryEmail      = "email"
+	CategoryPayment    = "payment"
+	CategoryLink       = "link"
+)
+
+// contactCategories move a conversation off the app. In chat they only
+// count while the conversation is new, which is when scammers push for it.
+var contactCategories = map[string]bool{
+	CategoryPhone:   true,
+	CategoryEmail:   true,
+	CategoryPayment: true,
+	CategoryLink:    true,
+}
+
+// AnyLocale keys the word lists applied whatever the locale.
+const AnyLocale = "*"
+
+//go:embed default_rules.json
+var defaultRules []byte
+
+// Rules configure what the filter looks for and what it does about it.
+type Rules struct {
+	// EarlyConversationMessages is how many messages a conversation holds
+	// before contact details stop being flagged in it
+	EarlyConversationMessages int `json:"early_conversation_messages"`
+	// Actions maps a surface to the action for each category found on it;
+	// categories without an action are allowed
+	Actions map[string]map[string]string `json:"actions"`
+	// Words maps a locale, or AnyLocale, to the words and phrases of each
+	// category. They are normalized like inspected text when loaded.
+	Words map[string]map[string][]string `json:"words"`
+}
+
+// DefaultRules returns the rules built into the binary.
+func DefaultRules() (*Rules, error) {
+	return parseRules(defaultRules)
+}
+
+// LoadRules reads rules from a JSON file.
+func LoadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := parseRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+func parseRules(data []byte) (*Rules, error) {
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	if err := rules.validate(); err != nil {
+		return nil, err
+	}
+	for _, lists := range rules.Words {
+		for category, words := range lists {
+			normalized := make([]string, 0, len(words))
+			for _, word := range words {
+				if word = normalize(word); strings.TrimSpace(word) != "" {
+					normalized = append(normalized, word)
+				}
+			}
+			lists[category] = normalized
+		}
+	}
+	return &rules, nil
+}
+
+func (r *Rules) validate() error {
+	if r.EarlyConversationMessages < 0 {
+		return fmt.Errorf("early_conversation_messages must not be negative")
+	}
+	for surface, actions := range r.Actions {
+		if surface != SurfaceMessage && surface != SurfaceBio {
+			return fmt.Errorf("unknown surface %q", surface)
+		}
+		for category, action := range actions {
+			if _, ok := actionSeverity[action]; !ok {
+				return fmt.Errorf("unknown action %q for %s %s", action, surface, category)
+			}
+		}
+	}
+	return nil
+}
+
+// action returns what to do about a category found on a surface.
+func (r *Rules) action(surface, category string) string {
+	if action, ok := r.Actions[surface][category]; ok {
+		return action
+	}
+	return ActionAllow
+}
+
+// wordLists returns the lists that apply to a locale: the AnyLocale lists,
+// then those of the locale and of its language ("es" for "es-MX"). An empty
+// locale gets every list since the language is unknown.
+func (r *Rules) wordLists(locale string) []map[string][]string {
+	if locale == "" {
+		lists := make([]map[string][]string, 0, len(r.Words))
+		for _, words := range r.Words {
+			lists = append(lists, words)
+		}
+		return lists
+	}
+
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	lists := []map[string][]string{r.Words[AnyLocale]}
+	if words, ok := r.Words[locale]; ok {
+		lists = append(lists, words)
+	}
+	if lang, _, found := strings.Cut(locale, "-"); found {
+		lists = append(lists, r.Words[lang])
+	}
+	return lists
+}
@@ -0,0 +1,112 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/qdrant"
+)
+
+// Embedding providers
+const (
+	ProviderLocal  = "local"
+	ProviderOpenAI = "openai"
+)
+
+// Embedder turns profile text into fixed-size vectors.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	Dimension() int
+}
+
+// NewEmbedder returns the embedder for the configured provider.
+func NewEmbedder(provider, apiKey, model string, dimension int) (Embedder, error) {
+	switch provider {
+	case ProviderLocal, "":
+		return NewHashEmbedder(dimension), nil
+	case ProviderOpenAI:
+		if apiKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY is required for the openai embedding provider")
+		}
+		return NewOpenAIEmbedder(apiKey, model, dimension), nil
+	default:
+		return nil, fmt.Errorf("unknown embedding provider %q", provider)
+	}
+}
+
+// ProfileText builds the text embedded for a profile. Names and contact
+// details are deliberately left out.
+func ProfileText(p *models.EmbeddingProfile, at time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "gender: %s. age group: %s.", p.Gender, qdrant.AgeGroup(models.AgeAt(p.DateOfBirth, at)))
+	if p.Bio != nil && strings.TrimSpace(*p.Bio) != "" {
+		fmt.Fprintf(&b, " bio: %s", strings.TrimSpace(*p.Bio))
+	}
+	return b.String()
+}
+
+// HashEmbedder is a deterministic bag-of-words embedder using the hashing
+// trick. It needs no external service, which makes it suitable for tests and
+// local development, but it only captures shared vocabulary.
+type HashEmbedder struct {
+	dimension int
+}
+
+func NewHashEmbedder(dimension int) *HashEmbedder {
+	return &HashEmbedder{dimension: dimension}
+}
+
+func (e *HashEmbedder) Dimension() int {
+	return e.dimension
+}
+
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = e.embed(text)
+	}
+	return vectors, nil
+}
+
+func (e *HashEmbedder) embed(text string) []float32 {
+	vector := make([]float32, e.dimension)
+	tokens := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '-'
+	})
+
+	for _, token := range tokens {
+		h := fnv.New64a()
+		h.Write([]byte(token))
+		sum := h.Sum64()
+
+		// The top bit picks the sign so that collisions tend to cancel out
+		// instead of piling up.
+		weight := float32(1)
+		if sum>>63 == 1 {
+			weight = -1
+		}
+		vector[sum%uint64(e.dimension)] += weight
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		// Cosine similarity is undefined for the zero vector
+		vector[0] = 1
+		return vector
+	}
+
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range vector {
+		vector[i] *= scale
+	}
+	return vector
+}
@@ -0,0 +1,99 @@
+package embedding
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/google/uuid"
+)
+
+func cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+func TestHashEmbedderDeterministic(t *testing.T) {
+	embedder := NewHashEmbedder(512)
+	texts := []string{"Hiking, climbing and cooking", "", "Jazz & late-night ramen"}
+
+	first, err := embedder.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	second, _ := NewHashEmbedder(512).Embed(context.Background(), texts)
+
+	for i := range texts {
+		if len(first[i]) != 512 {
+			t.Fatalf("vector %d has %d dimensions, want 512", i, len(first[i]))
+		}
+		for j := range first[i] {
+			if first[i][j] != second[i][j] {
+				t.Fatalf("vector %d differs between runs at %d", i, j)
+			}
+		}
+		if norm := cosine(first[i], first[i]); math.Abs(norm-1) > 1e-6 {
+			t.Errorf("vector %d self-similarity = %f, want 1", i, norm)
+		}
+	}
+}
+
+func TestHashEmbedderSimilarity(t *testing.T) {
+	vectors, _ := NewHashEmbedder(512).Embed(context.Background(), []string{
+		"love hiking in the mountains and cooking",
+		"hiking in the mountains every weekend, also cooking",
+		"crypto trading and fast cars",
+	})
+
+	related := cosine(vectors[0], vectors[1])
+	unrelated := cosine(vectors[0], vectors[2])
+	if related <= unrelated {
+		t.Errorf("related similarity %f should exceed unrelated %f", related, unrelated)
+	}
+}
+
+func TestProfileText(t *testing.T) {
+	bio := "  Coffee snob and amateur astronomer  "
+	profile := &models.EmbeddingProfile{
+		UserID:      uuid.New(),
+		Gender:      "female",
+		DateOfBirth: time.Date(1995, time.March, 10, 0, 0, 0, 0, time.UTC),
+		Bio:         &bio,
+	}
+
+	text := ProfileText(profile, time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC))
+	want := "gender: female. age group: 25-34. bio: Coffee snob and amateur astronomer"
+	if text != want {
+		t.Errorf("ProfileText() = %q, want %q", text, want)
+	}
+
+	profile.Bio = nil
+	if text := ProfileText(profile, time.Now()); strings.Contains(text, "bio:") {
+		t.Errorf("ProfileText() without bio = %q", text)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 5 * time.Second},
+		{1, 10 * time.Second},
+		{3, 40 * time.Second},
+		{20, time.Hour},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/repository/qdrant"
+	"github.com/google/uuid"
+)
+
+const (
+	// outboxLease is how long claimed entries stay hidden from other workers.
+	outboxLease = time.Minute
+	// Failed entries are retried with exponential backoff between these bounds.
+	minRetryDelay = 5 * time.Second
+	maxRetryDelay = time.Hour
+)
+
+type Config struct {
+	BatchSize int
+}
+
+// ReindexResult summarizes a full reindex.
+type ReindexResult struct {
+	Indexed int `json:"indexed"`
+	Pruned  int `json:"pruned"`
+}
+
+// SyncService keeps the Qdrant profile collection in step with Postgres.
+type SyncService interface {
+	// ProcessOutbox syncs one batch of pending profile changes and returns
+	// how many outbox entries it handled.
+	ProcessOutbox(ctx context.Context) (int, error)
+	// PurgeOutbox removes entries processed before the given time.
+	PurgeOutbox(ctx context.Context, before time.Time) (int64, error)
+	// Reindex re-embeds every profile. With prune set, points for users that
+	// no longer exist in Postgres are deleted as well.
+	Reindex(ctx context.Context, prune bool) (*ReindexResult, error)
+}
+
+type syncService struct {
+	embeddingRepo postgres.EmbeddingRepository
+	profileRepo   qdrant.ProfileRepository
+	embedder      Embedder
+	cfg           Config
+}
+
+func NewSyncService(embeddingRepo postgres.EmbeddingRepository, profileRepo qdrant.ProfileRepository, embedder Embedder, cfg Config) SyncService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	return &syncService{
+		embeddingRepo: embeddingRepo,
+		profileRepo:   profileRepo,
+		embedder:      embedder,
+		cfg:           cfg,
+	}
+}
+
+// ProcessOutbox always syncs the current state of each user rather than
+// replaying individual operations, so entries can be retried, reordered or
+// coalesced safely.
+func (s *syncService) ProcessOutbox(ctx context.Context) (int, error) {
+	entries, err := s.embeddingRepo.ClaimOutbox(ctx, s.cfg.BatchSize, outboxLease)
+	if err != nil {
+		return 0, err
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	// Coalesce entries per user
+	entryIDs := map[uuid.UUID][]int64{}
+	attempts := map[uuid.UUID]int{}
+	userIDs := []uuid.UUID{}
+	for _, e := range entries {
+		if _, ok := entryIDs[e.UserID]; !ok {
+			userIDs = append(userIDs, e.UserID)
+		}
+		entryIDs[e.UserID] = append(entryIDs[e.UserID], e.ID)
+		if e.Attempts > attempts[e.UserID] {
+			attempts[e.UserID] = e.Attempts
+		}
+	}
+
+	if err := s.syncUsers(ctx, userIDs); err == nil {
+		return len(entries), s.embeddingRepo.MarkProcessed(ctx, flattenIDs(entryIDs, userIDs))
+	} else if len(userIDs) == 1 {
+		return len(entries), s.markFailed(ctx, entryIDs[userIDs[0]], attempts[userIDs[0]], err)
+	}
+
+	// Retry users one by one so a single bad profile does not hold back the
+	// whole batch.
+	for _, userID := range userIDs {
+		if err := s.syncUsers(ctx, []uuid.UUID{userID}); err != nil {
+			if err := s.markFailed(ctx, entryIDs[userID], attempts[userID], err); err != nil {
+				return 0, err
+			}
+			continue
+		}
+		if err := s.embeddingRepo.MarkProcessed(ctx, entryIDs[userID]); err != nil {
+			return 0, err
+		}
+	}
+	return len(entries), nil
+}
+
+func (s *syncService) PurgeOutbox(ctx context.Context, before time.Time) (int64, error) {
+	return s.embeddingRepo.PurgeProcessed(ctx, before)
+}
+
+func (s *syncService) Reindex(ctx context.Context, prune bool) (*ReindexResult, error) {
+	if err := s.profileRepo.EnsureCollection(ctx, s.embedder.Dimension()); err != nil {
+		return nil, err
+	}
+
+	result := &ReindexResult{}
+	seen := map[uuid.UUID]bool{}
+	afterID := uuid.Nil
+	for {
+		profiles, err := s.embeddingRepo.ListProfiles(ctx, afterID, s.cfg.BatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(profiles) == 0 {
+			break
+		}
+
+		if err := s.upsertProfiles(ctx, profiles); err != nil {
+			return result, err
+		}
+		for _, p := range profiles {
+			seen[p.UserID] = true
+		}
+		result.Indexed += len(profiles)
+		afterID = profiles[len(profiles)-1].UserID
+		slog.InfoContext(ctx, "Reindexed profiles", "indexed", result.Indexed)
+	}
+
+	if !prune {
+		return result, nil
+	}
+
+	indexed, err := s.profileRepo.ListUserIDs(ctx)
+	if err != nil {
+		return result, err
+	}
+	stale := []uuid.UUID{}
+	for _, id := range indexed {
+		if !seen[id] {
+			stale = append(stale, id)
+		}
+	}
+	if err := s.profileRepo.Delete(ctx, stale); err != nil {
+		return result, err
+	}
+	result.Pruned = len(stale)
+	return result, nil
+}
+
+// syncUsers upserts the embeddings of existing users and deletes those of
+// users that are gone.
+func (s *syncService) syncUsers(ctx context.Context, userIDs []uuid.UUID) error {
+	profiles, err := s.embeddingRepo.GetProfiles(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+
+	found := map[uuid.UUID]bool{}
+	for _, p := range profiles {
+		found[p.UserID] = true
+	}
+	deleted := []uuid.UUID{}
+	for _, id := range userIDs {
+		if !found[id] {
+			deleted = append(deleted, id)
+		}
+	}
+
+	if err := s.upsertProfiles(ctx, profiles); err != nil {
+		return err
+	}
+	return s.profileRepo.Delete(ctx, deleted)
+}
+
+func (s *syncService) upsertProfiles(ctx context.Context, profiles []*models.EmbeddingProfile) error {
+	if len(profiles) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	texts := make([]string, len(profiles))
+	for i, p := range profiles {
+		texts[i] = ProfileText(p, now)
+	}
+	vectors, err := s.embedder.Embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+	if len(vectors) != len(profiles) {
+		return fmt.Errorf("embedder returned %d vectors for %d profiles", len(vectors), len(profiles))
+	}
+
+	points := make([]*qdrant.ProfilePoint, len(profiles))
+	for i, p := range profiles {
+		points[i] = &qdrant.ProfilePoint{
+			UserID:        p.UserID,
+			Vector:        vectors[i],
+			Gender:        p.Gender,
+			DateOfBirth:   p.DateOfBirth,
+			Active:        p.Active,
+			Verified:      p.Verified,
+			VideoVerified: p.VideoVerified,
+			Latitude:      p.Latitude,
+			Longitude:     p.Longitude,
+		}
+	}
+	return s.profileRepo.Upsert(ctx, points)
+}
+
+func (s *syncService) markFailed(ctx context.Context, ids []int64, attempts int, cause error) error {
+	slog.WarnContext(ctx, "Embedding sync failed", "attempt", attempts+1, "error", cause)
+	return s.embeddingRepo.MarkFailed(ctx, ids, cause, retryDelay(attempts))
+}
+
+// retryDelay doubles the delay with every failed attempt, up to maxRetryDelay.
+func retryDelay(attempts int) time.Duration {
+	delay := minRetryDelay
+	for i := 0; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+func flattenIDs(entryIDs map[uuid.UUID][]int64, userIDs []uuid.UUID) []int64 {
+	ids := []int64{}
+	for _, userID := range userIDs {
+		ids = append(ids, entryIDs[userID]...)
+	}
+	return ids
+}
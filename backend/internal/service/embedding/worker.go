@@ -0,0 +1,81 @@
+package embedding
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/alexcolls/findme/internal/metrics"
+	"github.com/alexcolls/findme/pkg/tracing"
+)
+
+const (
+	// outboxRetention is how long processed outbox entries are kept around
+	// for debugging before being purged.
+	outboxRetention = 7 * 24 * time.Hour
+	purgeInterval   = time.Hour
+)
+
+// Worker drains the embedding outbox on a fixed interval.
+type Worker struct {
+	service   SyncService
+	interval  time.Duration
+	batchSize int
+	lastPurge time.Time
+}
+
+func NewWorker(service SyncService, interval time.Duration, batchSize int) *Worker {
+	return &Worker{
+		service:   service,
+		interval:  interval,
+		batchSize: batchSize,
+	}
+}
+
+// Start processes the outbox immediately and then on every tick until ctx is
+// done.
+func (w *Worker) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			w.runOnce(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (w *Worker) runOnce(ctx context.Context) {
+	ctx, span := tracing.StartJob(ctx, "embedding_sync")
+	defer span.End()
+
+	// Keep going while full batches come back so a backlog drains quickly
+	start := time.Now()
+	for ctx.Err() == nil {
+		n, err := w.service.ProcessOutbox(ctx)
+		if err != nil {
+			span.RecordError(err)
+			metrics.ObserveJob("embedding_sync", start, err)
+			slog.ErrorContext(ctx, "Embedding outbox processing failed", "error", err)
+			return
+		}
+		if n == 0 || n < w.batchSize {
+			break
+		}
+	}
+	metrics.ObserveJob("embedding_sync", start, nil)
+
+	if time.Since(w.lastPurge) < purgeInterval {
+		return
+	}
+	w.lastPurge = time.Now()
+	if _, err := w.service.PurgeOutbox(ctx, time.Now().Add(-outboxRetention)); err != nil {
+		slog.ErrorContext(ctx, "Embedding outbox purge failed", "error", err)
+	}
+}
@@ -0,0 +1,91 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/alexcolls/findme/internal/service/events")
+
+// Event types
+const (
+	MatchCreated        = "match.created"
+	MatchMutual         = "match.mutual"
+	MatchExpiring       = "match.expiring"
+	MatchExpired        = "match.expired"
+	MatchCompleted      = "match.completed"
+	UserBlocked         = "user.blocked"
+	CallMissed          = "call.missed"
+	VerificationDecided = "verification.decided"
+	MessageModerated    = "message.moderated"
+	SessionsRevoked     = "sessions.revoked"
+)
+
+// Event is a domain event addressed to one or more users.
+type Event struct {
+	Type       string      `json:"type"`
+	UserIDs    []uuid.UUID `json:"-"`
+	Data       interface{} `json:"data"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+type Handler func(ctx context.Context, event Event)
+
+// Publisher is implemented by anything services can emit events to.
+type Publisher interface {
+	Publish(ctx context.Context, event Event)
+}
+
+// Bus is an in-process publisher that fans events out to subscribers
+// synchronously. Handlers should hand slow work off to a goroutine.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: map[string][]Handler{}}
+}
+
+// Subscribe registers a handler for an event type.
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+func (b *Bus) Publish(ctx context.Context, event Event) {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	b.mu.RLock()
+	handlers := b.handlers[event.Type]
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		b.dispatch(ctx, handler, event)
+	}
+}
+
+// dispatch isolates publishers from panicking handlers. Each handler runs in
+// a span under the publisher's.
+func (b *Bus) dispatch(ctx context.Context, handler Handler, event Event) {
+	ctx, span := tracer.Start(ctx, "event "+event.Type, trace.WithAttributes(attribute.String("event.type", event.Type)))
+	defer span.End()
+	defer func() {
+		if r := recover(); r != nil {
+			span.SetStatus(codes.Error, "handler panicked")
+			slog.ErrorContext(ctx, "Event handler panicked", "event", event.Type, "panic", r)
+		}
+	}()
+	handler(ctx, event)
+}
@@ -0,0 +1,138 @@
+package ice
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+)
+
+// ICE server providers
+const (
+	ProviderStatic = "static"
+	ProviderCoturn = "coturn"
+	ProviderTwilio = "twilio"
+)
+
+// Provider issues the STUN and TURN servers for a call session.
+type Provider interface {
+	Servers(ctx context.Context, sessionID string) ([]models.ICEServer, error)
+}
+
+type Config struct {
+	Provider    string
+	STUNServers []string
+	TURNServers []string
+	// TURNUsername and TURNCredential are fixed credentials for the static
+	// provider
+	TURNUsername   string
+	TURNCredential string
+	// TURNSharedSecret is the coturn static-auth-secret
+	TURNSharedSecret string
+	// CredentialTTL is how long issued TURN credentials stay valid
+	CredentialTTL time.Duration
+
+	TwilioAccountSID string
+	TwilioAuthToken  string
+	TwilioAPIKey     string
+	TwilioAPISecret  string
+}
+
+// NewProvider returns the provider selected by cfg.Provider.
+func NewProvider(cfg Config) (Provider, error) {
+	if cfg.CredentialTTL <= 0 {
+		cfg.CredentialTTL = 3 * time.Hour
+	}
+
+	switch cfg.Provider {
+	case ProviderStatic, "":
+		return &StaticProvider{servers: staticServers(cfg)}, nil
+	case ProviderCoturn:
+		if cfg.TURNSharedSecret == "" || len(cfg.TURNServers) == 0 {
+			return nil, fmt.Errorf("TURN_SHARED_SECRET and WEBRTC_TURN_SERVERS are required for the coturn ICE provider")
+		}
+		return NewCoturnProvider(cfg.STUNServers, cfg.TURNServers, cfg.TURNSharedSecret, cfg.CredentialTTL), nil
+	case ProviderTwilio:
+		hasKey := cfg.TwilioAPIKey != "" && cfg.TwilioAPISecret != ""
+		if (cfg.TwilioAPIKey != "") != (cfg.TwilioAPISecret != "") {
+			return nil, fmt.Errorf("TWILIO_API_KEY and TWILIO_API_SECRET must be set together for the twilio ICE provider")
+		}
+		if cfg.TwilioAccountSID == "" || (cfg.TwilioAuthToken == "" && !hasKey) {
+			return nil, fmt.Errorf("TWILIO_ACCOUNT_SID and an auth token or API key are required for the twilio ICE provider")
+		}
+		return NewTwilioProvider(cfg.TwilioAccountSID, cfg.TwilioAuthToken, cfg.TwilioAPIKey, cfg.TwilioAPISecret, cfg.CredentialTTL), nil
+	default:
+		return nil, fmt.Errorf("unknown ICE provider %q", cfg.Provider)
+	}
+}
+
+// StaticProvider hands out the configured servers as they are. Fixed TURN
+// credentials never expire, so it suits development rather than production.
+type StaticProvider struct {
+	servers []models.ICEServer
+}
+
+func staticServers(cfg Config) []models.ICEServer {
+	servers := []models.ICEServer{}
+	if len(cfg.STUNServers) > 0 {
+		servers = append(servers, models.ICEServer{URLs: cfg.STUNServers})
+	}
+	if len(cfg.TURNServers) > 0 && cfg.TURNUsername != "" {
+		servers = append(servers, models.ICEServer{
+			URLs:       cfg.TURNServers,
+			Username:   cfg.TURNUsername,
+			Credential: cfg.TURNCredential,
+		})
+	}
+	return servers
+}
+
+func (p *StaticProvider) Servers(ctx context.Context, sessionID string) ([]models.ICEServer, error) {
+	return p.servers, nil
+}
+
+// CoturnProvider issues time-limited credentials under the TURN REST API
+// scheme that coturn implements with use-auth-secret: the username is
+// "<expiry unix time>:<session>" and the password is the base64
+// HMAC-SHA1 of the username keyed with the shared secret. The TURN server
+// verifies them without calling back to us.
+type CoturnProvider struct {
+	stun   []string
+	turn   []string
+	secret []byte
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewCoturnProvider(stun, turn []string, secret string, ttl time.Duration) *CoturnProvider {
+	return &CoturnProvider{
+		stun:   stun,
+		turn:   turn,
+		secret: []byte(secret),
+		ttl:    ttl,
+		now:    time.Now,
+	}
+}
+
+func (p *CoturnProvider) Servers(ctx context.Context, sessionID string) ([]models.ICEServer, error) {
+	username := strconv.FormatInt(p.now().Add(p.ttl).Unix(), 10) + ":" + sessionID
+
+	mac := hmac.New(sha1.New, p.secret)
+	mac.Write([]byte(username))
+	credential := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	servers := []models.ICEServer{}
+	if len(p.stun) > 0 {
+		servers = append(servers, models.ICEServer{URLs: p.stun})
+	}
+	return append(servers, models.ICEServer{
+		URLs:       p.turn,
+		Username:   username,
+		Credential: credential,
+	}), nil
+}
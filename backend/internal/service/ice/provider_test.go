@@ -0,0 +1,100 @@
+package ice
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCoturnCredentials(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	p := NewCoturnProvider([]string{"stun:turn.example.com:3478"}, []string{"turn:turn.example.com:3478"}, "secret", time.Hour)
+	p.now = func() time.Time { return now }
+
+	servers, err := p.Servers(context.Background(), "session-1")
+	if err != nil {
+		t.Fatalf("Servers() error = %v", err)
+	}
+	if len(servers) != 2 {
+		t.Fatalf("got %d servers, want STUN and TURN", len(servers))
+	}
+
+	turn := servers[1]
+	if want := "1700003600:session-1"; turn.Username != want {
+		t.Errorf("username = %q, want %q", turn.Username, want)
+	}
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write([]byte(turn.Username))
+	if want := base64.StdEncoding.EncodeToString(mac.Sum(nil)); turn.Credential != want {
+		t.Errorf("credential = %q, want %q", turn.Credential, want)
+	}
+}
+
+func TestTwilioServers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, _ := r.BasicAuth()
+		if r.URL.Path != "/Accounts/AC123/Tokens.json" || user != "SK456" || pass != "key-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message":"Authenticate"}`))
+			return
+		}
+		if ttl := r.FormValue("Ttl"); ttl != "600" {
+			t.Errorf("Ttl = %q, want 600", ttl)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"ice_servers":[
+			{"urls":"stun:global.stun.twilio.com:3478"},
+			{"urls":"turn:global.turn.twilio.com:3478?transport=udp","username":"u","credential":"c"}
+		]}`))
+	}))
+	defer server.Close()
+
+	p := NewTwilioProvider("AC123", "auth-token", "SK456", "key-secret", 10*time.Minute)
+	p.baseURL = server.URL
+
+	servers, err := p.Servers(context.Background(), "session-1")
+	if err != nil {
+		t.Fatalf("Servers() error = %v", err)
+	}
+	if len(servers) != 2 || servers[1].Username != "u" || servers[1].Credential != "c" {
+		t.Errorf("servers = %+v", servers)
+	}
+
+	p.password = "wrong"
+	if _, err := p.Servers(context.Background(), "session-2"); err == nil {
+		t.Error("expected an error when Twilio rejects the credentials")
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"static by default", Config{}, false},
+		{"coturn without secret", Config{Provider: ProviderCoturn, TURNServers: []string{"turn:x"}}, true},
+		{"coturn", Config{Provider: ProviderCoturn, TURNServers: []string{"turn:x"}, TURNSharedSecret: "s"}, false},
+		{"twilio without account", Config{Provider: ProviderTwilio, TwilioAuthToken: "t"}, true},
+		{"twilio with auth token", Config{Provider: ProviderTwilio, TwilioAccountSID: "AC", TwilioAuthToken: "t"}, false},
+		{"twilio with API key", Config{Provider: ProviderTwilio, TwilioAccountSID: "AC", TwilioAPIKey: "SK", TwilioAPISecret: "s"}, false},
+		{"twilio with API secret only", Config{Provider: ProviderTwilio, TwilioAccountSID: "AC", TwilioAPISecret: "s"}, true},
+		{"twilio with API key only", Config{Provider: ProviderTwilio, TwilioAccountSID: "AC", TwilioAPIKey: "SK"}, true},
+		{"twilio with auth token and half a key", Config{Provider: ProviderTwilio, TwilioAccountSID: "AC", TwilioAuthToken: "t", TwilioAPIKey: "SK"}, true},
+		{"unknown", Config{Provider: "xirsys"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewProvider(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewProvider() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package ice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+)
+
+const twilioAPIURL = "https://api.twilio.com/2010-04-01"
+
+// TwilioProvider issues servers from Twilio's Network Traversal Service by
+// creating a token per call.
+type TwilioProvider struct {
+	accountSID string
+	// username and password authenticate requests: an API key and secret
+	// when configured, otherwise the account SID and auth token
+	username   string
+	password   string
+	ttl        time.Duration
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewTwilioProvider(accountSID, authToken, apiKey, apiSecret string, ttl time.Duration) *TwilioProvider {
+	username, password := accountSID, authToken
+	if apiKey != "" && apiSecret != "" {
+		username, password = apiKey, apiSecret
+	}
+	return &TwilioProvider{
+		accountSID: accountSID,
+		username:   username,
+		password:   password,
+		ttl:        ttl,
+		baseURL:    twilioAPIURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type twilioTokenResponse struct {
+	ICEServers []struct {
+		URLs       string `json:"urls"`
+		Username   string `json:"username"`
+		Credential string `json:"credential"`
+	} `json:"ice_servers"`
+	Message string `json:"message"`
+}
+
+func (p *TwilioProvider) Servers(ctx context.Context, sessionID string) ([]models.ICEServer, error) {
+	form := url.Values{"Ttl": {strconv.Itoa(int(p.ttl.Seconds()))}}
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Tokens.json", p.baseURL, p.accountSID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(p.username, p.password)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("twilio token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result twilioTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode twilio token response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("twilio token request failed with status %d: %s", resp.StatusCode, result.Message)
+	}
+
+	servers := make([]models.ICEServer, 0, len(result.ICEServers))
+	for _, s := range result.ICEServers {
+		servers = append(servers, models.ICEServer{
+			URLs:       []string{s.URLs},
+			Username:   s.Username,
+			Credential: s.Credential,
+		})
+	}
+	return servers, nil
+}
@@ -0,0 +1,96 @@
+package match
+
+import (
+	"context"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/events"
+	"github.com/alexcolls/findme/internal/service/matching"
+	"github.com/google/uuid"
+)
+
+// MatchService handles a user's weekly matches: viewing them, deciding on
+// them and ending mutual matches.
+type MatchService interface {
+	WeeklyMatches(ctx context.Context, userID uuid.UUID) (week, year int, matches []*models.WeeklyMatch, err error)
+	Accept(ctx context.Context, userID, matchID uuid.UUID) (*models.MatchActionResponse, error)
+	Reject(ctx context.Context, userID, matchID uuid.UUID) (*models.MatchActionResponse, error)
+	ActiveMatches(ctx context.Context, userID uuid.UUID) ([]*models.ActiveMatch, error)
+	End(ctx context.Context, userID, matchID uuid.UUID, req *models.EndMatchRequest) error
+}
+
+type matchService struct {
+	matchRepo postgres.MatchRepository
+	events    events.Publisher
+}
+
+func NewMatchService(matchRepo postgres.MatchRepository, publisher events.Publisher) MatchService {
+	return &matchService{
+		matchRepo: matchRepo,
+		events:    publisher,
+	}
+}
+
+func (s *matchService) WeeklyMatches(ctx context.Context, userID uuid.UUID) (int, int, []*models.WeeklyMatch, error) {
+	week, year := matching.CurrentWeek(time.Now())
+	matches, err := s.matchRepo.ListUserWeekMatches(ctx, userID, week, year)
+	return week, year, matches, err
+}
+
+func (s *matchService) Accept(ctx context.Context, userID, matchID uuid.UUID) (*models.MatchActionResponse, error) {
+	match, err := s.matchRepo.RecordAction(ctx, matchID, userID, models.MatchActionAccepted)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.MatchActionResponse{
+		MatchID:     match.ID,
+		Status:      models.MatchActionAccepted,
+		MutualMatch: match.MutualMatch,
+		Message:     "Waiting for other user to accept",
+	}
+
+	// Only the update that completes the pair can see mutual_match set,
+	// since the match is no longer pending afterwards.
+	if match.MutualMatch {
+		resp.Message = "It's a match!"
+		s.events.Publish(ctx, events.Event{
+			Type:    events.MatchMutual,
+			UserIDs: []uuid.UUID{match.UserID, match.MatchedUserID},
+			Data:    match,
+		})
+	}
+	return resp, nil
+}
+
+func (s *matchService) Reject(ctx context.Context, userID, matchID uuid.UUID) (*models.MatchActionResponse, error) {
+	match, err := s.matchRepo.RecordAction(ctx, matchID, userID, models.MatchActionRejected)
+	if err != nil {
+		return nil, err
+	}
+	return &models.MatchActionResponse{
+		MatchID: match.ID,
+		Status:  models.MatchActionRejected,
+		Message: "Match rejected",
+	}, nil
+}
+
+func (s *matchService) ActiveMatches(ctx context.Context, userID uuid.UUID) ([]*models.ActiveMatch, error) {
+	return s.matchRepo.ListActiveMatches(ctx, userID)
+}
+
+// End completes the match early, just as the sweeper does when it expires.
+func (s *matchService) End(ctx context.Context, userID, matchID uuid.UUID, req *models.EndMatchRequest) error {
+	match, err := s.matchRepo.EndMatch(ctx, matchID, userID, req.Reason, req.Feedback)
+	if err != nil {
+		return err
+	}
+	s.events.Publish(ctx, events.Event{
+		Type:    events.MatchCompleted,
+		UserIDs: []uuid.UUID{match.UserID, match.MatchedUserID},
+		Data:    match,
+	})
+	return nil
+}
@@ -0,0 +1,114 @@
+package match
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/events"
+	"github.com/google/uuid"
+)
+
+// fakeMatchRepo applies actions to a single in-memory match the way the
+// repository and check_mutual_match trigger do.
+type fakeMatchRepo struct {
+	postgres.MatchRepository
+	match *models.Match
+}
+
+func (r *fakeMatchRepo) RecordAction(ctx context.Context, matchID, userID uuid.UUID, action string) (*models.Match, error) {
+	m := r.match
+	if m.ID != matchID || (m.UserID != userID && m.MatchedUserID != userID) {
+		return nil, postgres.ErrMatchNotFound
+	}
+	if m.Status != models.MatchStatusPending {
+		return nil, postgres.ErrMatchClosed
+	}
+
+	if m.UserID == userID {
+		m.UserAction = &action
+	} else {
+		m.MatchedUserAction = &action
+	}
+	if action == models.MatchActionRejected {
+		m.Status = models.MatchStatusRejected
+	}
+	if m.UserAction != nil && *m.UserAction == models.MatchActionAccepted &&
+		m.MatchedUserAction != nil && *m.MatchedUserAction == models.MatchActionAccepted {
+		m.MutualMatch = true
+		m.Status = models.MatchStatusAccepted
+	}
+	copied := *m
+	return &copied, nil
+}
+
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event events.Event) {
+	p.events = append(p.events, event)
+}
+
+func newTestMatch() *models.Match {
+	return &models.Match{
+		ID:            uuid.New(),
+		UserID:        uuid.New(),
+		MatchedUserID: uuid.New(),
+		Status:        models.MatchStatusPending,
+	}
+}
+
+func TestAcceptEmitsMutualMatchOnce(t *testing.T) {
+	m := newTestMatch()
+	publisher := &recordingPublisher{}
+	service := NewMatchService(&fakeMatchRepo{match: m}, publisher)
+	ctx := context.Background()
+
+	resp, err := service.Accept(ctx, m.MatchedUserID, m.ID)
+	if err != nil {
+		t.Fatalf("first Accept() error = %v", err)
+	}
+	if resp.MutualMatch || len(publisher.events) != 0 {
+		t.Fatalf("one-sided accept reported mutual match or emitted %d events", len(publisher.events))
+	}
+
+	resp, err = service.Accept(ctx, m.UserID, m.ID)
+	if err != nil {
+		t.Fatalf("second Accept() error = %v", err)
+	}
+	if !resp.MutualMatch {
+		t.Error("second accept should complete the mutual match")
+	}
+	if len(publisher.events) != 1 || publisher.events[0].Type != events.MatchMutual {
+		t.Fatalf("got events %v, want one %s", publisher.events, events.MatchMutual)
+	}
+	if got := publisher.events[0].UserIDs; len(got) != 2 {
+		t.Errorf("mutual match event addressed to %d users, want 2", len(got))
+	}
+
+	if _, err := service.Accept(ctx, m.UserID, m.ID); err != postgres.ErrMatchClosed {
+		t.Errorf("accepting a closed match error = %v, want ErrMatchClosed", err)
+	}
+}
+
+func TestRejectClosesMatch(t *testing.T) {
+	m := newTestMatch()
+	publisher := &recordingPublisher{}
+	service := NewMatchService(&fakeMatchRepo{match: m}, publisher)
+	ctx := context.Background()
+
+	if _, err := service.Reject(ctx, m.UserID, m.ID); err != nil {
+		t.Fatalf("Reject() error = %v", err)
+	}
+	if _, err := service.Accept(ctx, m.MatchedUserID, m.ID); err != postgres.ErrMatchClosed {
+		t.Errorf("accepting a rejected match error = %v, want ErrMatchClosed", err)
+	}
+	if _, err := service.Accept(ctx, uuid.New(), m.ID); err != postgres.ErrMatchNotFound {
+		t.Errorf("accepting someone else's match error = %v, want ErrMatchNotFound", err)
+	}
+	if len(publisher.events) != 0 {
+		t.Errorf("rejection emitted %d events", len(publisher.events))
+	}
+}
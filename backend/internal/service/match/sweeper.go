@@ -0,0 +1,214 @@
+package match
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/metrics"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/events"
+	"github.com/alexcolls/findme/pkg/cache"
+	"github.com/alexcolls/findme/pkg/tracing"
+	"github.com/google/uuid"
+)
+
+const (
+	sweeperLockKey          = "locks:match_sweeper"
+	defaultSweeperBatchSize = 500
+)
+
+type SweeperConfig struct {
+	Interval time.Duration
+	// BatchSize bounds how many matches are updated per statement.
+	BatchSize int
+	// ReminderBefore is how long before expiry the "last chance" reminder
+	// goes out.
+	ReminderBefore time.Duration
+}
+
+// SweeperStats are cumulative counters since the process started.
+type SweeperStats struct {
+	Runs      int64 `json:"runs"`
+	Skipped   int64 `json:"skipped"`
+	Failed    int64 `json:"failed"`
+	Expired   int64 `json:"expired"`
+	Completed int64 `json:"completed"`
+	Reminded  int64 `json:"reminded"`
+}
+
+// Sweeper closes matches once they pass expires_at and reminds users shortly
+// before that happens. A Redis lock ensures only one replica sweeps at a time.
+type Sweeper struct {
+	matchRepo postgres.MatchRepository
+	cache     *cache.RedisCache
+	events    events.Publisher
+	cfg       SweeperConfig
+
+	runs, skipped, failed        atomic.Int64
+	expired, completed, reminded atomic.Int64
+}
+
+func NewSweeper(matchRepo postgres.MatchRepository, redisCache *cache.RedisCache, publisher events.Publisher, cfg SweeperConfig) *Sweeper {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultSweeperBatchSize
+	}
+	return &Sweeper{
+		matchRepo: matchRepo,
+		cache:     redisCache,
+		events:    publisher,
+		cfg:       cfg,
+	}
+}
+
+// Start sweeps immediately and then on every tick until ctx is done.
+func (s *Sweeper) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+
+		for {
+			s.runOnce(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *Sweeper) Stats() SweeperStats {
+	return SweeperStats{
+		Runs:      s.runs.Load(),
+		Skipped:   s.skipped.Load(),
+		Failed:    s.failed.Load(),
+		Expired:   s.expired.Load(),
+		Completed: s.completed.Load(),
+		Reminded:  s.reminded.Load(),
+	}
+}
+
+func (s *Sweeper) runOnce(ctx context.Context) {
+	ctx, span := tracing.StartJob(ctx, "match_sweeper")
+	var err error
+	defer func() { tracing.End(span, err) }()
+
+	// The lock outlives a single interval so a slow sweep is not joined by
+	// another replica; it is extended after every batch.
+	ttl := 2 * s.cfg.Interval
+	start := time.Now()
+	lock, err := s.cache.AcquireLock(ctx, sweeperLockKey, ttl)
+	if errors.Is(err, cache.ErrLockNotAcquired) {
+		s.skipped.Add(1)
+		metrics.SkipJob("match_sweeper")
+		err = nil
+		return
+	}
+	if err != nil {
+		s.failed.Add(1)
+		metrics.ObserveJob("match_sweeper", start, err)
+		slog.ErrorContext(ctx, "Match sweeper could not acquire lock", "error", err)
+		return
+	}
+	defer func() {
+		if err := lock.Release(context.Background()); err != nil {
+			slog.ErrorContext(ctx, "Match sweeper failed to release lock", "error", err)
+		}
+	}()
+
+	s.runs.Add(1)
+	err = s.sweep(ctx, func() error { return lock.Extend(ctx, ttl) })
+	metrics.ObserveJob("match_sweeper", start, err)
+	if err != nil {
+		s.failed.Add(1)
+		slog.ErrorContext(ctx, "Match sweep failed", "error", err)
+	}
+}
+
+// sweep sends due reminders and then expires matches, batch by batch.
+// keepAlive is called between batches and aborts the sweep if it fails.
+func (s *Sweeper) sweep(ctx context.Context, keepAlive func() error) error {
+	var expired, completed, reminded int
+
+	for {
+		matches, err := s.matchRepo.ClaimExpiryReminders(ctx, s.cfg.ReminderBefore, s.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, m := range matches {
+			if recipients := reminderRecipients(m); len(recipients) > 0 {
+				s.publish(ctx, events.MatchExpiring, recipients, m)
+			}
+		}
+		reminded += len(matches)
+		s.reminded.Add(int64(len(matches)))
+		metrics.MatchesSwept.WithLabelValues("reminded").Add(float64(len(matches)))
+
+		if len(matches) < s.cfg.BatchSize {
+			break
+		}
+		if err := keepAlive(); err != nil {
+			return err
+		}
+	}
+
+	for {
+		matches, err := s.matchRepo.ExpireMatches(ctx, s.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, m := range matches {
+			if m.Status == models.MatchStatusCompleted {
+				completed++
+				s.completed.Add(1)
+				metrics.MatchesSwept.WithLabelValues("completed").Inc()
+				s.publish(ctx, events.MatchCompleted, []uuid.UUID{m.UserID, m.MatchedUserID}, m)
+			} else {
+				expired++
+				s.expired.Add(1)
+				metrics.MatchesSwept.WithLabelValues("expired").Inc()
+				s.publish(ctx, events.MatchExpired, []uuid.UUID{m.UserID, m.MatchedUserID}, m)
+			}
+		}
+
+		if len(matches) < s.cfg.BatchSize {
+			break
+		}
+		if err := keepAlive(); err != nil {
+			return err
+		}
+	}
+
+	if expired+completed+reminded > 0 {
+		slog.InfoContext(ctx, "Match sweep completed", "expired", expired, "completed", completed, "reminded", reminded)
+	}
+	return nil
+}
+
+func (s *Sweeper) publish(ctx context.Context, eventType string, userIDs []uuid.UUID, match *models.Match) {
+	s.events.Publish(ctx, events.Event{
+		Type:    eventType,
+		UserIDs: userIDs,
+		Data:    match,
+	})
+}
+
+// reminderRecipients returns who should hear that a match is about to
+// expire: both users of a mutual match, otherwise whoever has not answered.
+func reminderRecipients(m *models.Match) []uuid.UUID {
+	if m.MutualMatch {
+		return []uuid.UUID{m.UserID, m.MatchedUserID}
+	}
+	recipients := []uuid.UUID{}
+	for _, id := range []uuid.UUID{m.UserID, m.MatchedUserID} {
+		if m.ActionOf(id) == nil {
+			recipients = append(recipients, id)
+		}
+	}
+	return recipients
+}
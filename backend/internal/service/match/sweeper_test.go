@@ -0,0 +1,114 @@
+package match
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/events"
+	"github.com/google/uuid"
+)
+
+// batchMatchRepo hands out queued matches limit at a time.
+type batchMatchRepo struct {
+	postgres.MatchRepository
+	due, expiring []*models.Match
+}
+
+func take(queue *[]*models.Match, limit int) []*models.Match {
+	n := limit
+	if n > len(*queue) {
+		n = len(*queue)
+	}
+	batch := (*queue)[:n]
+	*queue = (*queue)[n:]
+	return batch
+}
+
+func (r *batchMatchRepo) ExpireMatches(ctx context.Context, limit int) ([]*models.Match, error) {
+	return take(&r.due, limit), nil
+}
+
+func (r *batchMatchRepo) ClaimExpiryReminders(ctx context.Context, window time.Duration, limit int) ([]*models.Match, error) {
+	return take(&r.expiring, limit), nil
+}
+
+func TestSweepExpiresInBatches(t *testing.T) {
+	accepted := models.MatchActionAccepted
+	repo := &batchMatchRepo{}
+	for i := 0; i < 5; i++ {
+		m := newTestMatch()
+		m.Status = models.MatchStatusExpired
+		repo.due = append(repo.due, m)
+	}
+	completed := newTestMatch()
+	completed.Status = models.MatchStatusCompleted
+	repo.due = append(repo.due, completed)
+
+	halfAnswered := newTestMatch()
+	halfAnswered.UserAction = &accepted
+	mutual := newTestMatch()
+	mutual.MutualMatch = true
+	repo.expiring = []*models.Match{halfAnswered, mutual}
+
+	publisher := &recordingPublisher{}
+	sweeper := NewSweeper(repo, nil, publisher, SweeperConfig{BatchSize: 2, ReminderBefore: time.Hour})
+
+	keepAlives := 0
+	if err := sweeper.sweep(context.Background(), func() error { keepAlives++; return nil }); err != nil {
+		t.Fatalf("sweep() error = %v", err)
+	}
+
+	stats := sweeper.Stats()
+	if stats.Expired != 5 || stats.Completed != 1 || stats.Reminded != 2 {
+		t.Errorf("stats = %+v, want 5 expired, 1 completed, 2 reminded", stats)
+	}
+	// Full batches are followed by another query, so the lock is extended
+	// after each of them
+	if keepAlives != 4 {
+		t.Errorf("lock extended %d times, want 4", keepAlives)
+	}
+
+	counts := map[string]int{}
+	for _, e := range publisher.events {
+		counts[e.Type]++
+	}
+	if counts[events.MatchExpiring] != 2 || counts[events.MatchExpired] != 5 || counts[events.MatchCompleted] != 1 {
+		t.Errorf("published %v", counts)
+	}
+}
+
+func TestReminderRecipients(t *testing.T) {
+	accepted := models.MatchActionAccepted
+
+	fresh := newTestMatch()
+	halfAnswered := newTestMatch()
+	halfAnswered.MatchedUserAction = &accepted
+	mutual := newTestMatch()
+	mutual.UserAction, mutual.MatchedUserAction, mutual.MutualMatch = &accepted, &accepted, true
+
+	tests := []struct {
+		name  string
+		match *models.Match
+		want  []uuid.UUID
+	}{
+		{"nobody answered", fresh, []uuid.UUID{fresh.UserID, fresh.MatchedUserID}},
+		{"one answered", halfAnswered, []uuid.UUID{halfAnswered.UserID}},
+		{"mutual", mutual, []uuid.UUID{mutual.UserID, mutual.MatchedUserID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := reminderRecipients(tt.match)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("recipient %d = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
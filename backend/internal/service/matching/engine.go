@@ -0,0 +1,244 @@
+package matching
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/google/uuid"
+)
+
+const earthRadiusKm = 6371.0
+
+type pairKey [2]uuid.UUID
+
+// newPairKey returns an order-independent key for a pair of users. The
+// smaller ID always comes first, which is also the order rows are stored in.
+func newPairKey(a, b uuid.UUID) pairKey {
+	if a.String() < b.String() {
+		return pairKey{a, b}
+	}
+	return pairKey{b, a}
+}
+
+type scoredPair struct {
+	key       pairKey
+	score     float64
+	breakdown *models.ScoreBreakdown
+}
+
+// scoreFunc explains the score of a compatible pair.
+type scoreFunc func(a, b *models.MatchProfile) *models.ScoreBreakdown
+
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
+
+// distanceKm returns the distance between two profiles, or false if either
+// has no known location.
+func distanceKm(a, b *models.MatchProfile) (float64, bool) {
+	if a.Latitude == nil || a.Longitude == nil || b.Latitude == nil || b.Longitude == nil {
+		return 0, false
+	}
+	return haversineKm(*a.Latitude, *a.Longitude, *b.Latitude, *b.Longitude), true
+}
+
+// accepts reports whether a's preferences allow b as a match.
+func accepts(a, b *models.MatchProfile, at time.Time) bool {
+	genderOK := false
+	for _, g := range a.InterestedIn {
+		if g == b.Gender {
+			genderOK = true
+			break
+		}
+	}
+	if !genderOK {
+		return false
+	}
+
+	age := models.AgeAt(b.DateOfBirth, at)
+	if age < a.MinAge || age > a.MaxAge {
+		return false
+	}
+
+	if a.MaxDistanceKm > 0 {
+		if d, ok := distanceKm(a, b); ok && d > float64(a.MaxDistanceKm) {
+			return false
+		}
+	}
+	return true
+}
+
+// compatible reports whether both users' preferences allow each other.
+func compatible(a, b *models.MatchProfile, at time.Time) bool {
+	return a.UserID != b.UserID && accepts(a, b, at) && accepts(b, a, at)
+}
+
+// selectTopK picks up to k new matches for each user in turn, highest score
+// first. It only caps the user being served, so popular profiles can end up
+// with far more than k matches; the weekly job uses allocate instead, and
+// selectTopK remains as the baseline for simulations. exclude and counts
+// work as in allocate.
+func selectTopK(profiles []*models.MatchProfile, exclude map[pairKey]bool, counts map[uuid.UUID]int, k int, minScore float64, at time.Time, score scoreFunc) []scoredPair {
+	chosen := map[pairKey]bool{}
+	var selected []scoredPair
+
+	for _, user := range profiles {
+		if counts[user.UserID] >= k {
+			continue
+		}
+
+		var candidates []scoredPair
+		for _, other := range profiles {
+			key := newPairKey(user.UserID, other.UserID)
+			if exclude[key] || chosen[key] || !compatible(user, other, at) {
+				continue
+			}
+			breakdown := score(user, other)
+			if breakdown.Total < minScore {
+				continue
+			}
+			candidates = append(candidates, scoredPair{key: key, score: breakdown.Total, breakdown: breakdown})
+		}
+
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].score > candidates[j].score
+		})
+
+		for _, c := range candidates {
+			if counts[user.UserID] >= k {
+				break
+			}
+			chosen[c.key] = true
+			counts[c.key[0]]++
+			counts[c.key[1]]++
+			selected = append(selected, c)
+		}
+	}
+	return selected
+}
+
+// candidatesPerMatch sets how many of each user's best pairs allocate keeps
+// per weekly match. The rest are dropped while scoring, so memory grows with
+// users rather than with compatible pairs.
+const candidatesPerMatch = 50
+
+// candidateHeap is a min-heap of pairs by score, holding one user's best
+// candidates.
+type candidateHeap []scoredPair
+
+func (h candidateHeap) Len() int           { return len(h) }
+func (h candidateHeap) Less(i, j int) bool { return h[i].score < h[j].score }
+func (h candidateHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *candidateHeap) Push(x any)        { *h = append(*h, x.(scoredPair)) }
+func (h *candidateHeap) Pop() any {
+	old := *h
+	p := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return p
+}
+
+// offer adds p if it is among the best limit pairs seen so far.
+func (h *candidateHeap) offer(p scoredPair, limit int) {
+	switch {
+	case h.Len() < limit:
+		heap.Push(h, p)
+	case h.Len() > 0 && p.score > (*h)[0].score:
+		(*h)[0] = p
+		heap.Fix(h, 0)
+	}
+}
+
+// topCandidates scores every compatible pair not in exclude and keeps those
+// among the best limit of either user. options counts all of each user's
+// compatible pairs, kept or not.
+func topCandidates(profiles []*models.MatchProfile, exclude map[pairKey]bool, limit int, minScore float64, at time.Time, score scoreFunc) ([]scoredPair, map[uuid.UUID]int) {
+	best := make(map[uuid.UUID]*candidateHeap, len(profiles))
+	for _, p := range profiles {
+		best[p.UserID] = &candidateHeap{}
+	}
+	options := map[uuid.UUID]int{}
+	for i, a := range profiles {
+		for _, b := range profiles[i+1:] {
+			key := newPairKey(a.UserID, b.UserID)
+			if exclude[key] || !compatible(a, b, at) {
+				continue
+			}
+			breakdown := score(a, b)
+			if breakdown.Total < minScore {
+				continue
+			}
+			pair := scoredPair{key: key, score: breakdown.Total, breakdown: breakdown}
+			best[a.UserID].offer(pair, limit)
+			best[b.UserID].offer(pair, limit)
+			options[a.UserID]++
+			options[b.UserID]++
+		}
+	}
+
+	// A pair in both users' heaps is kept once; profiles fixes the order
+	kept := map[pairKey]bool{}
+	var pairs []scoredPair
+	for _, p := range profiles {
+		for _, pair := range *best[p.UserID] {
+			if !kept[pair.key] {
+				kept[pair.key] = true
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+	return pairs, options
+}
+
+// allocate assigns the week's matches globally, as a capacity-constrained
+// assignment over compatible pairs rather than user by user:
+//
+//   - nobody, on either side, ends up with more than k matches this week;
+//   - allocation happens in rounds, and in round r a user can only reach r
+//     matches, so everyone who can get a first match does so before anyone
+//     gets a second;
+//   - within a round, pairs are taken by a priority that blends score with
+//     scarcity, so users with few compatible partners are served before their
+//     options are used up by popular profiles. fairness sets the blend, from
+//     0 (score only) to 1 (scarcity only).
+//
+// Only pairs among the candidatesPerMatch*k best of either user are
+// considered. Users with fewer compatible partners than that keep all of
+// them, so the scarce users fairness protects lose nothing.
+//
+// Pairs in exclude are never proposed, and counts holds how many matches each
+// user already has this week; it is updated in place.
+func allocate(profiles []*models.MatchProfile, exclude map[pairKey]bool, counts map[uuid.UUID]int, k int, minScore, fairness float64, at time.Time, score scoreFunc) []scoredPair {
+	pairs, options := topCandidates(profiles, exclude, candidatesPerMatch*k, minScore, at, score)
+
+	fairness = math.Max(0, math.Min(1, fairness))
+	priority := func(p scoredPair) float64 {
+		scarcity := math.Max(1/float64(options[p.key[0]]), 1/float64(options[p.key[1]]))
+		return (1-fairness)*p.score + fairness*scarcity
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return priority(pairs[i]) > priority(pairs[j])
+	})
+
+	chosen := make([]bool, len(pairs))
+	var selected []scoredPair
+	for round := 1; round <= k; round++ {
+		for i, p := range pairs {
+			if chosen[i] || counts[p.key[0]] >= round || counts[p.key[1]] >= round {
+				continue
+			}
+			chosen[i] = true
+			counts[p.key[0]]++
+			counts[p.key[1]]++
+			selected = append(selected, p)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,249 @@
+package matching
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/google/uuid"
+)
+
+var testNow = time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+
+func newProfile(gender string, age int, interestedIn ...string) *models.MatchProfile {
+	lastLogin := testNow.Add(-24 * time.Hour)
+	return &models.MatchProfile{
+		UserID:       uuid.New(),
+		Gender:       gender,
+		DateOfBirth:  testNow.AddDate(-age, 0, -1),
+		LastLoginAt:  &lastLogin,
+		InterestedIn: interestedIn,
+		MinAge:       18,
+		MaxAge:       99,
+	}
+}
+
+func withLocation(p *models.MatchProfile, lat, lon float64, maxKm int) *models.MatchProfile {
+	p.Latitude = &lat
+	p.Longitude = &lon
+	p.MaxDistanceKm = maxKm
+	return p
+}
+
+func TestCompatible(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *models.MatchProfile
+		want bool
+	}{
+		{
+			name: "mutual gender preference",
+			a:    newProfile("male", 30, "female"),
+			b:    newProfile("female", 28, "male"),
+			want: true,
+		},
+		{
+			name: "one-sided gender preference",
+			a:    newProfile("male", 30, "female"),
+			b:    newProfile("female", 28, "female"),
+			want: false,
+		},
+		{
+			name: "outside age range",
+			a:    &models.MatchProfile{UserID: uuid.New(), Gender: "male", DateOfBirth: testNow.AddDate(-30, 0, 0), InterestedIn: []string{"female"}, MinAge: 25, MaxAge: 27},
+			b:    newProfile("female", 28, "male"),
+			want: false,
+		},
+		{
+			name: "beyond max distance",
+			a:    withLocation(newProfile("male", 30, "female"), 41.39, 2.17, 50), // Barcelona
+			b:    withLocation(newProfile("female", 28, "male"), 40.42, -3.70, 0), // Madrid
+			want: false,
+		},
+		{
+			name: "within max distance",
+			a:    withLocation(newProfile("male", 30, "female"), 41.39, 2.17, 50),
+			b:    withLocation(newProfile("female", 28, "male"), 41.40, 2.19, 0),
+			want: true,
+		},
+		{
+			name: "unknown location passes distance filter",
+			a:    withLocation(newProfile("male", 30, "female"), 41.39, 2.17, 50),
+			b:    newProfile("female", 28, "male"),
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compatible(tt.a, tt.b, testNow); got != tt.want {
+				t.Errorf("compatible() = %v, want %v", got, tt.want)
+			}
+			if got := compatible(tt.b, tt.a, testNow); got != tt.want {
+				t.Errorf("compatible() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func defaultScore(a, b *models.MatchProfile) *models.ScoreBreakdown {
+	return Explain(a, b, nil, DefaultScoreWeights(), testNow)
+}
+
+func TestSelectTopKRespectsExistingMatches(t *testing.T) {
+	a := newProfile("male", 30, "female")
+	b := newProfile("female", 29, "male")
+	c := newProfile("female", 31, "male")
+	profiles := []*models.MatchProfile{a, b, c}
+
+	exclude := map[pairKey]bool{newPairKey(a.UserID, b.UserID): true}
+	counts := map[uuid.UUID]int{a.UserID: 1, b.UserID: 1}
+
+	pairs := selectTopK(profiles, exclude, counts, 2, 0, testNow, defaultScore)
+	if len(pairs) != 1 {
+		t.Fatalf("got %d pairs, want 1", len(pairs))
+	}
+	if pairs[0].key != newPairKey(a.UserID, c.UserID) {
+		t.Errorf("got pair %v, want a-c", pairs[0].key)
+	}
+
+	// Re-running with the new pair excluded and counted adds nothing
+	exclude[pairs[0].key] = true
+	if again := selectTopK(profiles, exclude, counts, 2, 0, testNow, defaultScore); len(again) != 0 {
+		t.Errorf("re-run produced %d pairs, want 0", len(again))
+	}
+}
+
+func TestWeekBounds(t *testing.T) {
+	start, end := WeekBounds(1, 2026)
+	if want := time.Date(2025, time.December, 29, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("start = %v, want %v", start, want)
+	}
+	if end.Sub(start) != 7*24*time.Hour {
+		t.Errorf("week length = %v", end.Sub(start))
+	}
+	if week, year := CurrentWeek(start); week != 1 || year != 2026 {
+		t.Errorf("CurrentWeek(start) = %d/%d, want 1/2026", week, year)
+	}
+}
+
+func TestAllocateCapsBothSides(t *testing.T) {
+	// Four men, each compatible with both women
+	popular := newProfile("female", 30, "male")
+	other := newProfile("female", 30, "male")
+	profiles := []*models.MatchProfile{popular, other}
+	for i := 0; i < 4; i++ {
+		profiles = append(profiles, newProfile("male", 30, "female"))
+	}
+
+	counts := map[uuid.UUID]int{}
+	pairs := allocate(profiles, map[pairKey]bool{}, counts, 1, 0, 0, testNow, defaultScore)
+
+	if counts[popular.UserID] != 1 || counts[other.UserID] != 1 {
+		t.Errorf("women got %d and %d matches, want 1 each", counts[popular.UserID], counts[other.UserID])
+	}
+	if len(pairs) != 2 {
+		t.Errorf("got %d pairs, want 2", len(pairs))
+	}
+	for _, p := range pairs {
+		if p.breakdown == nil || p.breakdown.Total != p.score {
+			t.Errorf("pair %v is missing its score breakdown", p.key)
+		}
+	}
+
+	// The per-user baseline hands the popular profile to every man
+	topKCounts := map[uuid.UUID]int{}
+	selectTopK(profiles, map[pairKey]bool{}, topKCounts, 1, 0, testNow, defaultScore)
+	if topKCounts[popular.UserID] <= 1 {
+		t.Errorf("baseline gave the popular profile %d matches, expected overexposure", topKCounts[popular.UserID])
+	}
+}
+
+func TestAllocateLevelsRounds(t *testing.T) {
+	a := newProfile("male", 30, "female")
+	b := newProfile("male", 30, "female")
+	c := newProfile("female", 30, "male")
+	d := newProfile("female", 30, "male")
+	profiles := []*models.MatchProfile{a, b, c, d}
+
+	// a already has a match this week, so the first round serves b before
+	// a can get a second match
+	counts := map[uuid.UUID]int{a.UserID: 1}
+	pairs := allocate(profiles, map[pairKey]bool{}, counts, 2, 0, 0.5, testNow, defaultScore)
+
+	if len(pairs) != 3 {
+		t.Fatalf("got %d pairs, want 3", len(pairs))
+	}
+	if pairs[0].key[0] != b.UserID && pairs[0].key[1] != b.UserID {
+		t.Errorf("first pair %v does not include b", pairs[0].key)
+	}
+	for _, p := range profiles {
+		if n := counts[p.UserID]; n < 1 || n > 2 {
+			t.Errorf("user has %d matches, want 1 or 2", n)
+		}
+	}
+}
+
+func TestTopCandidatesKeepsBestOfEitherUser(t *testing.T) {
+	m1, m2 := newProfile("male", 30, "female"), newProfile("male", 30, "female")
+	w1, w2 := newProfile("female", 30, "male"), newProfile("female", 30, "male")
+	scores := map[pairKey]float64{
+		newPairKey(m1.UserID, w1.UserID): 0.9,
+		newPairKey(m1.UserID, w2.UserID): 0.8,
+		newPairKey(m2.UserID, w1.UserID): 0.85,
+		newPairKey(m2.UserID, w2.UserID): 0.3,
+	}
+	score := func(a, b *models.MatchProfile) *models.ScoreBreakdown {
+		return &models.ScoreBreakdown{Total: scores[newPairKey(a.UserID, b.UserID)]}
+	}
+
+	// Keeping one candidate each, m2-w2 is neither user's best
+	pairs, options := topCandidates([]*models.MatchProfile{m1, m2, w1, w2}, map[pairKey]bool{}, 1, 0, testNow, score)
+	if len(pairs) != 3 {
+		t.Fatalf("kept %d pairs, want 3", len(pairs))
+	}
+	for _, p := range pairs {
+		if p.key == newPairKey(m2.UserID, w2.UserID) {
+			t.Errorf("kept %v, which is neither user's best", p.key)
+		}
+	}
+	if options[w2.UserID] != 2 {
+		t.Errorf("w2 has %d options, want dropped pairs still counted", options[w2.UserID])
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	report := Simulate(SimulationConfig{Users: 300, WeeklyCount: 3, MinScore: 0.5, FairnessWeight: 0.3, Seed: 7})
+
+	if report.Allocation.OverCap != 0 || report.Allocation.MaxMatches > 3 {
+		t.Errorf("allocation exceeded the weekly cap: %+v", report.Allocation)
+	}
+	if report.Allocation.Gini > report.TopK.Gini {
+		t.Errorf("allocation gini %.3f is worse than top-k %.3f", report.Allocation.Gini, report.TopK.Gini)
+	}
+	if report.Allocation.Coverage == 0 {
+		t.Error("allocation matched nobody")
+	}
+}
+
+func TestAllocateAtProductionScale(t *testing.T) {
+	if testing.Short() {
+		t.Skip("scores every pair of 10,000 users")
+	}
+	const users, k = 10000, 4
+	profiles := syntheticProfiles(rand.New(rand.NewSource(1)), users, testNow)
+	score := func(a, b *models.MatchProfile) *models.ScoreBreakdown {
+		return Explain(a, b, nil, DefaultScoreWeights(), testNow)
+	}
+
+	counts := map[uuid.UUID]int{}
+	selected := allocate(profiles, map[pairKey]bool{}, counts, k, 0.6, 0.3, testNow, score)
+	stats := computeAllocationStats(profiles, selected, counts, k)
+	if stats.OverCap != 0 {
+		t.Errorf("%d users over the weekly cap", stats.OverCap)
+	}
+	if stats.Coverage < 0.95 || stats.Saturated < 0.9 {
+		t.Errorf("coverage %.3f, saturated %.3f: the candidate cap starved allocation", stats.Coverage, stats.Saturated)
+	}
+}
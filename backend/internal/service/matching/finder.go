@@ -0,0 +1,165 @@
+package matching
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/repository/qdrant"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultCandidateLimit = 20
+	maxCandidateLimit     = 100
+)
+
+// CandidateFinder returns potential matches for a user, filtered by their
+// preferences and ranked by profile similarity.
+type CandidateFinder interface {
+	FindCandidates(ctx context.Context, userID uuid.UUID, query models.CandidateQuery) ([]*models.Candidate, error)
+}
+
+type candidateFinder struct {
+	matchRepo postgres.MatchRepository
+	vectors   qdrant.ProfileRepository
+	cfg       Config
+}
+
+func NewCandidateFinder(matchRepo postgres.MatchRepository, vectors qdrant.ProfileRepository, cfg Config) CandidateFinder {
+	return &candidateFinder{
+		matchRepo: matchRepo,
+		vectors:   vectors,
+		cfg:       cfg,
+	}
+}
+
+// FindCandidates searches the vector index first. When the index is
+// unavailable, or the user has not been embedded yet, it falls back to
+// filtering in Postgres and ranking with the heuristic score.
+func (f *candidateFinder) FindCandidates(ctx context.Context, userID uuid.UUID, query models.CandidateQuery) ([]*models.Candidate, error) {
+	if query.Limit <= 0 || query.Limit > maxCandidateLimit {
+		query.Limit = defaultCandidateLimit
+	}
+	if query.Offset < 0 {
+		query.Offset = 0
+	}
+
+	profile, err := f.matchRepo.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	exclude, err := f.excludedUsers(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := f.searchVectors(ctx, profile, exclude, query, now)
+	if err == nil {
+		return candidates, nil
+	}
+	if !errors.Is(err, qdrant.ErrProfileNotIndexed) {
+		slog.WarnContext(ctx, "Vector candidate search failed, falling back to Postgres", "user_id", userID, "error", err)
+	}
+	return f.searchPostgres(ctx, profile, exclude, query, now)
+}
+
+// excludedUsers returns the user, everyone they were matched with recently
+// and everyone on either side of a block with them.
+func (f *candidateFinder) excludedUsers(ctx context.Context, userID uuid.UUID, now time.Time) ([]uuid.UUID, error) {
+	since := now.AddDate(0, 0, -f.cfg.ExcludePreviousDays)
+	matched, err := f.matchRepo.ListMatchedUserIDs(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := f.matchRepo.ListBlockedUserIDs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(matched, blocked...), userID), nil
+}
+
+func (f *candidateFinder) searchVectors(ctx context.Context, profile *models.MatchProfile, exclude []uuid.UUID, query models.CandidateQuery, now time.Time) ([]*models.Candidate, error) {
+	vector, err := f.vectors.GetVector(ctx, profile.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	hits, err := f.vectors.Search(ctx, &qdrant.ProfileSearch{
+		Vector:         vector,
+		Genders:        profile.InterestedIn,
+		MinAge:         profile.MinAge,
+		MaxAge:         profile.MaxAge,
+		Latitude:       profile.Latitude,
+		Longitude:      profile.Longitude,
+		RadiusKm:       profile.MaxDistanceKm,
+		ExcludeIDs:     exclude,
+		ScoreThreshold: query.MinScore,
+		Limit:          query.Limit,
+		Offset:         query.Offset,
+		At:             now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*models.Candidate, len(hits))
+	for i, hit := range hits {
+		candidates[i] = &models.Candidate{
+			UserID: hit.UserID,
+			Score:  hit.Score,
+			Gender: hit.Gender,
+			Age:    models.AgeAt(hit.DateOfBirth, now),
+			Source: models.CandidateSourceVector,
+		}
+		if profile.Latitude != nil && profile.Longitude != nil && hit.Latitude != nil && hit.Longitude != nil {
+			d := haversineKm(*profile.Latitude, *profile.Longitude, *hit.Latitude, *hit.Longitude)
+			candidates[i].DistanceKm = &d
+		}
+	}
+	return candidates, nil
+}
+
+// searchPostgres pages through filtered profiles in login order and applies
+// the score threshold to each page, so pages may come back short.
+func (f *candidateFinder) searchPostgres(ctx context.Context, profile *models.MatchProfile, exclude []uuid.UUID, query models.CandidateQuery, now time.Time) ([]*models.Candidate, error) {
+	profiles, err := f.matchRepo.ListCandidateProfiles(ctx, models.CandidateFilter{
+		Genders:    profile.InterestedIn,
+		MinAge:     profile.MinAge,
+		MaxAge:     profile.MaxAge,
+		Latitude:   profile.Latitude,
+		Longitude:  profile.Longitude,
+		RadiusKm:   profile.MaxDistanceKm,
+		ExcludeIDs: exclude,
+		Limit:      query.Limit,
+		Offset:     query.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []*models.Candidate{}
+	for _, p := range profiles {
+		score := Explain(profile, p, nil, f.cfg.Weights, now).Total
+		if score < query.MinScore {
+			continue
+		}
+		candidate := &models.Candidate{
+			UserID: p.UserID,
+			Score:  score,
+			Gender: p.Gender,
+			Age:    models.AgeAt(p.DateOfBirth, now),
+			Source: models.CandidateSourceFallback,
+		}
+		if d, ok := distanceKm(profile, p); ok {
+			candidate.DistanceKm = &d
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
@@ -0,0 +1,192 @@
+package matching
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/repository/qdrant"
+	"github.com/google/uuid"
+)
+
+// fakeMatchRepo serves profiles from memory. Methods the finder does not use
+// panic through the embedded nil interface.
+type fakeMatchRepo struct {
+	postgres.MatchRepository
+	profiles map[uuid.UUID]*models.MatchProfile
+	matched  []uuid.UUID
+	blocked  []uuid.UUID
+	filters  []models.CandidateFilter
+}
+
+func (r *fakeMatchRepo) GetProfile(ctx context.Context, userID uuid.UUID) (*models.MatchProfile, error) {
+	p, ok := r.profiles[userID]
+	if !ok {
+		return nil, postgres.ErrMatchProfileNotFound
+	}
+	return p, nil
+}
+
+func (r *fakeMatchRepo) ListMatchedUserIDs(ctx context.Context, userID uuid.UUID, since time.Time) ([]uuid.UUID, error) {
+	return r.matched, nil
+}
+
+func (r *fakeMatchRepo) ListBlockedUserIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	return r.blocked, nil
+}
+
+func (r *fakeMatchRepo) ListCandidateProfiles(ctx context.Context, filter models.CandidateFilter) ([]*models.MatchProfile, error) {
+	r.filters = append(r.filters, filter)
+	excluded := map[uuid.UUID]bool{}
+	for _, id := range filter.ExcludeIDs {
+		excluded[id] = true
+	}
+	result := []*models.MatchProfile{}
+	for _, p := range r.profiles {
+		if !excluded[p.UserID] {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func point(p *models.MatchProfile, vector ...float32) *qdrant.ProfilePoint {
+	return &qdrant.ProfilePoint{
+		UserID:        p.UserID,
+		Vector:        vector,
+		Gender:        p.Gender,
+		DateOfBirth:   p.DateOfBirth,
+		Active:        true,
+		Verified:      true,
+		VideoVerified: true,
+		Latitude:      p.Latitude,
+		Longitude:     p.Longitude,
+	}
+}
+
+func setupFinder(t *testing.T) (*fakeMatchRepo, *qdrant.MemoryProfileRepository, map[string]*models.MatchProfile) {
+	t.Helper()
+
+	people := map[string]*models.MatchProfile{
+		"seeker":   withLocation(newProfile("male", 30, "female"), 41.39, 2.17, 50),
+		"close":    withLocation(newProfile("female", 29, "male"), 41.40, 2.16, 0),
+		"similar":  withLocation(newProfile("female", 31, "male"), 41.38, 2.18, 0),
+		"far":      withLocation(newProfile("female", 30, "male"), 40.42, -3.70, 0),
+		"man":      withLocation(newProfile("male", 30, "male"), 41.39, 2.17, 0),
+		"previous": withLocation(newProfile("female", 30, "male"), 41.39, 2.17, 0),
+		"blocked":  withLocation(newProfile("female", 30, "male"), 41.39, 2.17, 0),
+		"no video": withLocation(newProfile("female", 30, "male"), 41.39, 2.17, 0),
+	}
+	people["seeker"].MinAge, people["seeker"].MaxAge = 25, 35
+
+	repo := &fakeMatchRepo{
+		profiles: map[uuid.UUID]*models.MatchProfile{},
+		matched:  []uuid.UUID{people["previous"].UserID},
+		blocked:  []uuid.UUID{people["blocked"].UserID},
+	}
+	for _, p := range people {
+		repo.profiles[p.UserID] = p
+	}
+
+	noVideo := point(people["no video"], 1, 0, 0)
+	noVideo.VideoVerified = false
+
+	vectors := qdrant.NewMemoryProfileRepository()
+	err := vectors.Upsert(context.Background(), []*qdrant.ProfilePoint{
+		point(people["seeker"], 1, 0, 0),
+		point(people["close"], 0.5, 0.5, 0),
+		point(people["similar"], 0.9, 0.1, 0),
+		point(people["far"], 1, 0, 0),
+		point(people["man"], 1, 0, 0),
+		point(people["previous"], 1, 0, 0),
+		point(people["blocked"], 1, 0, 0),
+		noVideo,
+	})
+	if err != nil {
+		t.Fatalf("Upsert() error = %v", err)
+	}
+	return repo, vectors, people
+}
+
+func TestFindCandidatesVectorSearch(t *testing.T) {
+	repo, vectors, people := setupFinder(t)
+	finder := NewCandidateFinder(repo, vectors, Config{ExcludePreviousDays: 90})
+
+	candidates, err := finder.FindCandidates(context.Background(), people["seeker"].UserID, models.CandidateQuery{})
+	if err != nil {
+		t.Fatalf("FindCandidates() error = %v", err)
+	}
+
+	// "far" is outside the radius, "man" the wrong gender, "previous" was
+	// matched before, "blocked" is blocked and "no video" has no verified
+	// video, which the Postgres search requires too
+	want := []uuid.UUID{people["similar"].UserID, people["close"].UserID}
+	if len(candidates) != len(want) {
+		t.Fatalf("got %d candidates, want %d", len(candidates), len(want))
+	}
+	for i, c := range candidates {
+		if c.UserID != want[i] {
+			t.Errorf("candidate %d = %s, want %s", i, c.UserID, want[i])
+		}
+		if c.Source != models.CandidateSourceVector {
+			t.Errorf("candidate %d source = %s, want vector", i, c.Source)
+		}
+		if c.DistanceKm == nil || *c.DistanceKm > 50 {
+			t.Errorf("candidate %d distance = %v, want within 50km", i, c.DistanceKm)
+		}
+	}
+
+	page, _ := finder.FindCandidates(context.Background(), people["seeker"].UserID, models.CandidateQuery{Limit: 1, Offset: 1})
+	if len(page) != 1 || page[0].UserID != people["close"].UserID {
+		t.Errorf("second page = %v, want only the close candidate", page)
+	}
+
+	strict, _ := finder.FindCandidates(context.Background(), people["seeker"].UserID, models.CandidateQuery{MinScore: 0.9})
+	if len(strict) != 1 || strict[0].UserID != people["similar"].UserID {
+		t.Errorf("score threshold kept %d candidates, want only the similar one", len(strict))
+	}
+}
+
+func TestFindCandidatesFallsBackToPostgres(t *testing.T) {
+	repo, vectors, people := setupFinder(t)
+	vectors.Err = errors.New("connection refused")
+	finder := NewCandidateFinder(repo, vectors, Config{ExcludePreviousDays: 90})
+
+	candidates, err := finder.FindCandidates(context.Background(), people["seeker"].UserID, models.CandidateQuery{Limit: 10})
+	if err != nil {
+		t.Fatalf("FindCandidates() error = %v", err)
+	}
+	if len(repo.filters) != 1 {
+		t.Fatalf("Postgres was queried %d times, want 1", len(repo.filters))
+	}
+
+	filter := repo.filters[0]
+	if filter.RadiusKm != 50 || filter.MinAge != 25 || filter.MaxAge != 35 || filter.Limit != 10 {
+		t.Errorf("filter = %+v, want the seeker's preferences", filter)
+	}
+	for _, c := range candidates {
+		if c.Source != models.CandidateSourceFallback {
+			t.Errorf("candidate source = %s, want fallback", c.Source)
+		}
+		switch c.UserID {
+		case people["seeker"].UserID, people["previous"].UserID, people["blocked"].UserID:
+			t.Errorf("excluded user %s returned", c.UserID)
+		}
+	}
+}
+
+func TestFindCandidatesUnindexedUserFallsBack(t *testing.T) {
+	repo, vectors, people := setupFinder(t)
+	vectors.Delete(context.Background(), []uuid.UUID{people["seeker"].UserID})
+	finder := NewCandidateFinder(repo, vectors, Config{ExcludePreviousDays: 90})
+
+	if _, err := finder.FindCandidates(context.Background(), people["seeker"].UserID, models.CandidateQuery{}); err != nil {
+		t.Fatalf("FindCandidates() error = %v", err)
+	}
+	if len(repo.filters) != 1 {
+		t.Errorf("Postgres was queried %d times, want 1", len(repo.filters))
+	}
+}
@@ -0,0 +1,187 @@
+package matching
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/metrics"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/repository/qdrant"
+	"github.com/alexcolls/findme/internal/service/events"
+	"github.com/google/uuid"
+)
+
+type Config struct {
+	WeeklyCount         int
+	MinScore            float64
+	ExcludePreviousDays int
+	Weights             ScoreWeights
+	// FairnessWeight trades score for serving users with few compatible
+	// partners first, from 0 to 1
+	FairnessWeight float64
+}
+
+type MatchingService interface {
+	// RunWeek generates matches for the given ISO week. Re-running a week is
+	// safe: existing matches count toward weekly limits and duplicate pairs
+	// are skipped. A completed week is only re-run when force is set.
+	RunWeek(ctx context.Context, week, year int, force bool) (*models.MatchingRun, error)
+}
+
+type matchingService struct {
+	matchRepo postgres.MatchRepository
+	vectors   qdrant.ProfileRepository
+	events    events.Publisher
+	cfg       Config
+}
+
+func NewMatchingService(matchRepo postgres.MatchRepository, vectors qdrant.ProfileRepository, publisher events.Publisher, cfg Config) MatchingService {
+	return &matchingService{
+		matchRepo: matchRepo,
+		vectors:   vectors,
+		events:    publisher,
+		cfg:       cfg,
+	}
+}
+
+// CurrentWeek returns the ISO week and year for t in UTC.
+func CurrentWeek(t time.Time) (week, year int) {
+	year, week = t.UTC().ISOWeek()
+	return week, year
+}
+
+// WeekBounds returns the start (Monday 00:00 UTC) and end (the following
+// Monday 00:00 UTC) of an ISO week.
+func WeekBounds(week, year int) (time.Time, time.Time) {
+	// January 4th is always in ISO week 1
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	offset := (int(jan4.Weekday()) + 6) % 7
+	start := jan4.AddDate(0, 0, -offset+(week-1)*7)
+	return start, start.AddDate(0, 0, 7)
+}
+
+func (s *matchingService) RunWeek(ctx context.Context, week, year int, force bool) (*models.MatchingRun, error) {
+	if week < 1 || week > 53 || year < 2025 {
+		return nil, fmt.Errorf("invalid week %d of %d", week, year)
+	}
+
+	run, err := s.matchRepo.BeginRun(ctx, week, year, force)
+	if err != nil {
+		return nil, err
+	}
+
+	runErr := s.generate(ctx, run)
+	if err := s.matchRepo.FinishRun(ctx, run, runErr); err != nil {
+		return nil, err
+	}
+	if runErr != nil {
+		return run, runErr
+	}
+
+	metrics.MatchesCreated.Add(float64(run.MatchesCreated))
+	slog.InfoContext(ctx, "Matching week completed", "week", week, "year", year,
+		"eligible_users", run.EligibleUsers, "matches_created", run.MatchesCreated)
+	return run, nil
+}
+
+func (s *matchingService) generate(ctx context.Context, run *models.MatchingRun) error {
+	weekStart, weekEnd := WeekBounds(run.WeekNumber, run.Year)
+
+	profiles, err := s.matchRepo.ListEligibleProfiles(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load eligible users: %w", err)
+	}
+	run.EligibleUsers = len(profiles)
+
+	// Exclude recent pairs and count matches already created this week
+	exclude := map[pairKey]bool{}
+	since := weekStart.AddDate(0, 0, -s.cfg.ExcludePreviousDays)
+	recent, err := s.matchRepo.ListPairsSince(ctx, since)
+	if err != nil {
+		return fmt.Errorf("failed to load previous matches: %w", err)
+	}
+	blocked, err := s.matchRepo.ListBlockedPairs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load blocks: %w", err)
+	}
+	for _, pair := range append(recent, blocked...) {
+		exclude[newPairKey(pair[0], pair[1])] = true
+	}
+
+	existing, err := s.matchRepo.ListWeekMatches(ctx, run.WeekNumber, run.Year)
+	if err != nil {
+		return fmt.Errorf("failed to load this week's matches: %w", err)
+	}
+	counts := map[uuid.UUID]int{}
+	for _, m := range existing {
+		exclude[newPairKey(m.UserID, m.MatchedUserID)] = true
+		counts[m.UserID]++
+		counts[m.MatchedUserID]++
+	}
+
+	score := s.scorer(ctx, profiles, weekStart)
+	pairs := allocate(profiles, exclude, counts, s.cfg.WeeklyCount, s.cfg.MinScore, s.cfg.FairnessWeight, weekStart, score)
+	stats := computeAllocationStats(profiles, pairs, counts, s.cfg.WeeklyCount)
+	slog.InfoContext(ctx, "Matching week allocated", "week", run.WeekNumber, "year", run.Year,
+		"coverage", stats.Coverage, "gini", stats.Gini, "max_matches", stats.MaxMatches)
+
+	matches := make([]*models.Match, 0, len(pairs))
+	for _, p := range pairs {
+		matches = append(matches, &models.Match{
+			UserID:         p.key[0],
+			MatchedUserID:  p.key[1],
+			WeekNumber:     run.WeekNumber,
+			Year:           run.Year,
+			MatchScore:     p.score,
+			ScoreBreakdown: p.breakdown,
+			ExpiresAt:      weekEnd.Add(-time.Second),
+		})
+	}
+
+	created, err := s.matchRepo.CreateMatches(ctx, matches)
+	if err != nil {
+		return fmt.Errorf("failed to insert matches: %w", err)
+	}
+	run.MatchesCreated = created
+
+	// Pairs that already existed were skipped and have no id
+	for _, m := range matches {
+		if m.ID != uuid.Nil {
+			s.events.Publish(ctx, events.Event{
+				Type:    events.MatchCreated,
+				UserIDs: []uuid.UUID{m.UserID, m.MatchedUserID},
+				Data:    m,
+			})
+		}
+	}
+	return nil
+}
+
+// scorer loads the profiles' embeddings so pairs can be compared by
+// similarity. If the vector index is unavailable the run goes ahead without
+// that component rather than failing.
+func (s *matchingService) scorer(ctx context.Context, profiles []*models.MatchProfile, at time.Time) scoreFunc {
+	ids := make([]uuid.UUID, len(profiles))
+	for i, p := range profiles {
+		ids[i] = p.UserID
+	}
+	vectors, err := s.vectors.GetVectors(ctx, ids)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to load profile embeddings, scoring without similarity", "error", err)
+		vectors = nil
+	}
+
+	return func(a, b *models.MatchProfile) *models.ScoreBreakdown {
+		var similarity *float64
+		va, okA := vectors[a.UserID]
+		vb, okB := vectors[b.UserID]
+		if okA && okB {
+			sim := cosineSimilarity(va, vb)
+			similarity = &sim
+		}
+		return Explain(a, b, similarity, s.cfg.Weights, at)
+	}
+}
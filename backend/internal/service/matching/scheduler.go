@@ -0,0 +1,62 @@
+package matching
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/alexcolls/findme/internal/metrics"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/pkg/tracing"
+)
+
+// Scheduler runs the matching job for the current ISO week on a fixed
+// interval. Each week is processed once; later ticks are no-ops until the
+// week rolls over.
+type Scheduler struct {
+	service  MatchingService
+	interval time.Duration
+}
+
+func NewScheduler(service MatchingService, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		service:  service,
+		interval: interval,
+	}
+}
+
+// Start runs the job immediately and then on every tick until ctx is done.
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			s.runOnce(ctx)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *Scheduler) runOnce(ctx context.Context) {
+	ctx, span := tracing.StartJob(ctx, "matching")
+	start := time.Now()
+	week, year := CurrentWeek(start)
+	_, err := s.service.RunWeek(ctx, week, year, false)
+	if errors.Is(err, postgres.ErrMatchingRunNotStarted) {
+		tracing.End(span, nil)
+		metrics.SkipJob("matching")
+		return
+	}
+	tracing.End(span, err)
+	metrics.ObserveJob("matching", start, err)
+	if err != nil {
+		slog.ErrorContext(ctx, "Matching job failed", "week", week, "year", year, "error", err)
+	}
+}
@@ -0,0 +1,168 @@
+package matching
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+)
+
+const (
+	// defaultDistanceScaleKm is used to score distance when neither user has
+	// set a maximum distance.
+	defaultDistanceScaleKm = 100.0
+
+	// fullInterestOverlap is the number of shared interests that earns the
+	// full interest score.
+	fullInterestOverlap = 3
+)
+
+// ScoreWeights sets how much each component contributes to a match score.
+// Weights are relative: they are normalised over the components that could
+// be computed for a pair.
+type ScoreWeights struct {
+	SharedInterests     float64
+	Distance            float64
+	AgeFit              float64
+	EmbeddingSimilarity float64
+	Recency             float64
+}
+
+// DefaultScoreWeights keeps age, distance and recency in the 4:3:3 ratio
+// used before interests and embeddings were scored.
+func DefaultScoreWeights() ScoreWeights {
+	return ScoreWeights{
+		SharedInterests:     0.2,
+		Distance:            0.15,
+		AgeFit:              0.2,
+		EmbeddingSimilarity: 0.3,
+		Recency:             0.15,
+	}
+}
+
+func (w ScoreWeights) isZero() bool {
+	return w == ScoreWeights{}
+}
+
+// Explain scores two users that already satisfy each other's preferences and
+// returns the breakdown, with the weighted total between 0 and 1. similarity
+// is the cosine similarity of their profile embeddings, or nil if either has
+// not been embedded.
+func Explain(a, b *models.MatchProfile, similarity *float64, weights ScoreWeights, at time.Time) *models.ScoreBreakdown {
+	if weights.isZero() {
+		weights = DefaultScoreWeights()
+	}
+
+	breakdown := &models.ScoreBreakdown{
+		Distance: distanceScore(a, b),
+		AgeFit:   (ageFit(a, b, at) + ageFit(b, a, at)) / 2,
+		Recency:  (recencyScore(a, at) + recencyScore(b, at)) / 2,
+	}
+	if d, ok := distanceKm(a, b); ok {
+		breakdown.DistanceKm = &d
+	}
+
+	sum := weights.Distance*breakdown.Distance + weights.AgeFit*breakdown.AgeFit + weights.Recency*breakdown.Recency
+	total := weights.Distance + weights.AgeFit + weights.Recency
+
+	if len(a.Interests) > 0 && len(b.Interests) > 0 {
+		breakdown.Interests = sharedInterests(a.Interests, b.Interests)
+		score := math.Min(1, float64(len(breakdown.Interests))/fullInterestOverlap)
+		breakdown.SharedInterests = &score
+		sum += weights.SharedInterests * score
+		total += weights.SharedInterests
+	}
+	if similarity != nil {
+		score := clamp(*similarity)
+		breakdown.EmbeddingSimilarity = &score
+		sum += weights.EmbeddingSimilarity * score
+		total += weights.EmbeddingSimilarity
+	}
+
+	if total > 0 {
+		breakdown.Total = clamp(sum / total)
+	}
+	return breakdown
+}
+
+func clamp(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// sharedInterests returns the interests in both lists, sorted.
+func sharedInterests(a, b []string) []string {
+	inA := make(map[string]bool, len(a))
+	for _, interest := range a {
+		inA[interest] = true
+	}
+	shared := []string{}
+	for _, interest := range b {
+		if inA[interest] {
+			shared = append(shared, interest)
+			delete(inA, interest)
+		}
+	}
+	sort.Strings(shared)
+	return shared
+}
+
+// cosineSimilarity compares two embeddings. It is 0 for vectors of
+// different dimensions.
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+// ageFit is 1 when b's age sits in the middle of a's preferred range and
+// falls to 0.5 at the edges.
+func ageFit(a, b *models.MatchProfile, at time.Time) float64 {
+	span := float64(a.MaxAge - a.MinAge)
+	if span <= 0 {
+		return 1
+	}
+	mid := float64(a.MinAge+a.MaxAge) / 2
+	offset := math.Abs(float64(models.AgeAt(b.DateOfBirth, at))-mid) / (span / 2)
+	return math.Max(0, 1-offset/2)
+}
+
+// distanceScore decays linearly with distance over the tighter of the two
+// users' limits. Unknown locations score neutrally.
+func distanceScore(a, b *models.MatchProfile) float64 {
+	d, ok := distanceKm(a, b)
+	if !ok {
+		return 0.5
+	}
+
+	scale := defaultDistanceScaleKm
+	for _, limit := range []int{a.MaxDistanceKm, b.MaxDistanceKm} {
+		if limit > 0 && float64(limit) < scale {
+			scale = float64(limit)
+		}
+	}
+	return math.Max(0, 1-d/scale)
+}
+
+// recencyScore favours users who have logged in recently: 1 within a week,
+// decaying to 0 after 60 days.
+func recencyScore(p *models.MatchProfile, at time.Time) float64 {
+	if p.LastLoginAt == nil {
+		return 0
+	}
+	days := at.Sub(*p.LastLoginAt).Hours() / 24
+	if days <= 7 {
+		return 1
+	}
+	return math.Max(0, 1-(days-7)/53)
+}
@@ -0,0 +1,55 @@
+package matching
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	a := withLocation(newProfile("male", 30, "female"), 41.39, 2.17, 50)
+	b := withLocation(newProfile("female", 30, "male"), 41.40, 2.16, 0)
+	a.MinAge, a.MaxAge = 25, 35
+	b.MinAge, b.MaxAge = 25, 35
+
+	// Without interests or embeddings only age, distance and recency count,
+	// in the same 4:3:3 ratio as before they existed
+	base := Explain(a, b, nil, DefaultScoreWeights(), testNow)
+	if base.SharedInterests != nil || base.EmbeddingSimilarity != nil {
+		t.Fatalf("optional components set without data: %+v", base)
+	}
+	want := 0.4*base.AgeFit + 0.3*base.Distance + 0.3*base.Recency
+	if math.Abs(base.Total-want) > 1e-9 {
+		t.Errorf("Total = %f, want %f", base.Total, want)
+	}
+
+	a.Interests = []string{"hiking", "jazz", "cooking"}
+	b.Interests = []string{"cooking", "chess", "hiking"}
+	similarity := 0.9
+	full := Explain(a, b, &similarity, DefaultScoreWeights(), testNow)
+	if !reflect.DeepEqual(full.Interests, []string{"cooking", "hiking"}) {
+		t.Errorf("Interests = %v, want cooking and hiking", full.Interests)
+	}
+	if full.SharedInterests == nil || math.Abs(*full.SharedInterests-2.0/3) > 1e-9 {
+		t.Errorf("SharedInterests = %v, want 2/3", full.SharedInterests)
+	}
+	if full.DistanceKm == nil || *full.DistanceKm > 2 {
+		t.Errorf("DistanceKm = %v, want about 1.4", full.DistanceKm)
+	}
+
+	// Weighting similarity alone makes the total equal to it
+	onlySimilarity := Explain(a, b, &similarity, ScoreWeights{EmbeddingSimilarity: 1}, testNow)
+	if math.Abs(onlySimilarity.Total-similarity) > 1e-9 {
+		t.Errorf("similarity-only Total = %f, want %f", onlySimilarity.Total, similarity)
+	}
+
+	reasons := full.Reasons()
+	wantReasons := []string{
+		"You both love cooking and hiking",
+		"You're only 1 km apart",
+		"Your profiles have a lot in common",
+	}
+	if !reflect.DeepEqual(reasons, wantReasons) {
+		t.Errorf("Reasons() = %q, want %q", reasons, wantReasons)
+	}
+}
@@ -0,0 +1,178 @@
+package matching
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/google/uuid"
+)
+
+// AllocationStats summarises how evenly a week's matches are spread.
+type AllocationStats struct {
+	Users   int `json:"users"`
+	Matches int `json:"matches"`
+	// Coverage is the share of users with at least one match
+	Coverage float64 `json:"coverage"`
+	// Saturated is the share of users who reached the weekly cap
+	Saturated float64 `json:"saturated"`
+	// Gini of matches per user: 0 when everyone has the same number
+	Gini       float64 `json:"gini"`
+	MaxMatches int     `json:"max_matches"`
+	// OverCap counts users with more matches than the weekly cap
+	OverCap   int     `json:"over_cap"`
+	MeanScore float64 `json:"mean_score"`
+}
+
+// computeAllocationStats reports on the users in profiles, using counts for
+// their total matches this week and pairs for the scores of new ones.
+func computeAllocationStats(profiles []*models.MatchProfile, pairs []scoredPair, counts map[uuid.UUID]int, k int) AllocationStats {
+	stats := AllocationStats{Users: len(profiles), Matches: len(pairs)}
+	if len(profiles) == 0 {
+		return stats
+	}
+
+	perUser := make([]int, len(profiles))
+	covered, saturated := 0, 0
+	for i, p := range profiles {
+		n := counts[p.UserID]
+		perUser[i] = n
+		if n > 0 {
+			covered++
+		}
+		if n >= k {
+			saturated++
+		}
+		if n > k {
+			stats.OverCap++
+		}
+		if n > stats.MaxMatches {
+			stats.MaxMatches = n
+		}
+	}
+	stats.Coverage = float64(covered) / float64(len(profiles))
+	stats.Saturated = float64(saturated) / float64(len(profiles))
+	stats.Gini = gini(perUser)
+
+	if len(pairs) > 0 {
+		sum := 0.0
+		for _, p := range pairs {
+			sum += p.score
+		}
+		stats.MeanScore = sum / float64(len(pairs))
+	}
+	return stats
+}
+
+func gini(values []int) float64 {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+
+	var sum, weighted float64
+	for i, v := range sorted {
+		sum += float64(v)
+		weighted += float64(i+1) * float64(v)
+	}
+	if sum == 0 {
+		return 0
+	}
+	n := float64(len(sorted))
+	return 2*weighted/(n*sum) - (n+1)/n
+}
+
+// SimulationConfig describes a synthetic population to run the matching
+// algorithms over.
+type SimulationConfig struct {
+	Users          int
+	WeeklyCount    int
+	MinScore       float64
+	FairnessWeight float64
+	Weights        ScoreWeights
+	Seed           int64
+}
+
+// SimulationReport compares the per-user top-k baseline with the global
+// allocation on the same population.
+type SimulationReport struct {
+	TopK       AllocationStats `json:"top_k"`
+	Allocation AllocationStats `json:"allocation"`
+}
+
+// Simulate generates synthetic users and matches them with both algorithms.
+func Simulate(cfg SimulationConfig) *SimulationReport {
+	at := time.Date(2025, time.June, 2, 0, 0, 0, 0, time.UTC)
+	profiles := syntheticProfiles(rand.New(rand.NewSource(cfg.Seed)), cfg.Users, at)
+	score := func(a, b *models.MatchProfile) *models.ScoreBreakdown {
+		return Explain(a, b, nil, cfg.Weights, at)
+	}
+
+	topKCounts := map[uuid.UUID]int{}
+	topK := selectTopK(profiles, map[pairKey]bool{}, topKCounts, cfg.WeeklyCount, cfg.MinScore, at, score)
+
+	allocCounts := map[uuid.UUID]int{}
+	alloc := allocate(profiles, map[pairKey]bool{}, allocCounts, cfg.WeeklyCount, cfg.MinScore, cfg.FairnessWeight, at, score)
+
+	return &SimulationReport{
+		TopK:       computeAllocationStats(profiles, topK, topKCounts, cfg.WeeklyCount),
+		Allocation: computeAllocationStats(profiles, alloc, allocCounts, cfg.WeeklyCount),
+	}
+}
+
+var (
+	simulationCities = [][2]float64{
+		{41.39, 2.17},  // Barcelona
+		{40.42, -3.70}, // Madrid
+		{39.47, -0.38}, // Valencia
+	}
+	simulationInterests = []string{
+		"hiking", "cooking", "travel", "music", "yoga", "reading", "gaming",
+		"movies", "running", "photography", "art", "dancing", "coffee", "wine",
+	}
+)
+
+// syntheticProfiles builds a population with skewed attributes: most users
+// live in one city, activity varies widely and a minority has a narrow age
+// preference, so some profiles are far more in demand than others.
+func syntheticProfiles(rng *rand.Rand, n int, at time.Time) []*models.MatchProfile {
+	profiles := make([]*models.MatchProfile, n)
+	for i := range profiles {
+		p := &models.MatchProfile{UserID: uuid.New(), MaxDistanceKm: 50}
+
+		switch r := rng.Float64(); {
+		case r < 0.48:
+			p.Gender, p.InterestedIn = "male", []string{"female"}
+		case r < 0.96:
+			p.Gender, p.InterestedIn = "female", []string{"male"}
+		default:
+			p.Gender, p.InterestedIn = "other", []string{"male", "female", "other"}
+		}
+
+		age := 18 + int(math.Min(42, math.Abs(rng.NormFloat64())*10))
+		p.DateOfBirth = at.AddDate(-age, 0, -rng.Intn(365)-1)
+		spread := 3 + rng.Intn(8)
+		if rng.Float64() < 0.2 {
+			spread = 1 + rng.Intn(2)
+		}
+		p.MinAge = max(18, age-spread)
+		p.MaxAge = age + spread
+
+		city := simulationCities[0]
+		if r := rng.Float64(); r > 0.6 {
+			city = simulationCities[1+rng.Intn(len(simulationCities)-1)]
+		}
+		lat := city[0] + rng.NormFloat64()*0.1
+		lon := city[1] + rng.NormFloat64()*0.1
+		p.Latitude, p.Longitude = &lat, &lon
+
+		lastLogin := at.Add(-time.Duration(rng.ExpFloat64()*7*24) * time.Hour)
+		p.LastLoginAt = &lastLogin
+
+		for _, j := range rng.Perm(len(simulationInterests))[:2+rng.Intn(4)] {
+			p.Interests = append(p.Interests, simulationInterests[j])
+		}
+		profiles[i] = p
+	}
+	return profiles
+}
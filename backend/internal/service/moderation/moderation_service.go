@@ -0,0 +1,188 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/events"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidReasonCode = errors.New("invalid reason code")
+
+type ModerationService interface {
+	Enqueue(ctx context.Context, req *models.EnqueueModerationRequest) (*models.ModerationItem, error)
+	GetItem(ctx context.Context, id uuid.UUID) (*models.ModerationItem, error)
+	ListQueue(ctx context.Context, filter models.ModerationQueueFilter) ([]*models.ModerationItem, error)
+	Stats(ctx context.Context) (*models.ModerationQueueStats, error)
+	ClaimNext(ctx context.Context, moderatorID uuid.UUID, itemType string) (*models.ModerationItem, error)
+	Claim(ctx context.Context, id, moderatorID uuid.UUID) (*models.ModerationItem, error)
+	Release(ctx context.Context, id, moderatorID uuid.UUID) error
+	Approve(ctx context.Context, id, moderatorID uuid.UUID, req *models.ModerationDecisionRequest) (*models.ModerationItem, error)
+	Reject(ctx context.Context, id, moderatorID uuid.UUID, req *models.ModerationDecisionRequest) (*models.ModerationItem, error)
+	AuditTrail(ctx context.Context, id uuid.UUID) ([]*models.ModerationAuditEntry, error)
+}
+
+type moderationService struct {
+	repo     postgres.ModerationRepository
+	events   events.Publisher
+	claimTTL time.Duration
+}
+
+func NewModerationService(repo postgres.ModerationRepository, publisher events.Publisher, claimTTL time.Duration) ModerationService {
+	return &moderationService{
+		repo:     repo,
+		events:   publisher,
+		claimTTL: claimTTL,
+	}
+}
+
+// SLAForPriority returns how long a moderator has to decide on an item of the
+// given priority (0-100).
+func SLAForPriority(priority int) time.Duration {
+	switch {
+	case priority >= 90:
+		return 1 * time.Hour
+	case priority >= 70:
+		return 4 * time.Hour
+	case priority >= 40:
+		return 24 * time.Hour
+	default:
+		return 72 * time.Hour
+	}
+}
+
+func (s *moderationService) Enqueue(ctx context.Context, req *models.EnqueueModerationRequest) (*models.ModerationItem, error) {
+	source := req.Source
+	if source == "" {
+		source = "auto"
+	}
+
+	item := &models.ModerationItem{
+		ItemType:  req.ItemType,
+		ItemID:    req.ItemID,
+		UserID:    req.UserID,
+		Priority:  req.Priority,
+		Source:    source,
+		AutoScore: req.AutoScore,
+		Metadata:  req.Metadata,
+		SLADueAt:  time.Now().Add(SLAForPriority(req.Priority)),
+	}
+	if req.Reason != "" {
+		item.Reason = &req.Reason
+	}
+
+	if err := s.repo.Enqueue(ctx, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *moderationService) GetItem(ctx context.Context, id uuid.UUID) (*models.ModerationItem, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *moderationService) ListQueue(ctx context.Context, filter models.ModerationQueueFilter) ([]*models.ModerationItem, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return s.repo.List(ctx, filter)
+}
+
+func (s *moderationService) Stats(ctx context.Context) (*models.ModerationQueueStats, error) {
+	return s.repo.Stats(ctx)
+}
+
+func (s *moderationService) ClaimNext(ctx context.Context, moderatorID uuid.UUID, itemType string) (*models.ModerationItem, error) {
+	return s.repo.ClaimNext(ctx, moderatorID, itemType, s.claimTTL)
+}
+
+func (s *moderationService) Claim(ctx context.Context, id, moderatorID uuid.UUID) (*models.ModerationItem, error) {
+	return s.repo.Claim(ctx, id, moderatorID, s.claimTTL)
+}
+
+func (s *moderationService) Release(ctx context.Context, id, moderatorID uuid.UUID) error {
+	return s.repo.Release(ctx, id, moderatorID)
+}
+
+func (s *moderationService) Approve(ctx context.Context, id, moderatorID uuid.UUID, req *models.ModerationDecisionRequest) (*models.ModerationItem, error) {
+	reasonCode := req.ReasonCode
+	if reasonCode == "" {
+		reasonCode = "approved"
+	}
+	if reasonCode != "approved" && reasonCode != "not_actionable" {
+		return nil, fmt.Errorf("%w for approval: %s", ErrInvalidReasonCode, reasonCode)
+	}
+	item, err := s.repo.Decide(ctx, id, moderatorID, models.ModerationStatusApproved, reasonCode, req.Notes)
+	if err != nil {
+		return nil, err
+	}
+	s.publishDecision(ctx, item, true)
+	return item, nil
+}
+
+func (s *moderationService) Reject(ctx context.Context, id, moderatorID uuid.UUID, req *models.ModerationDecisionRequest) (*models.ModerationItem, error) {
+	if req.ReasonCode == "" {
+		return nil, fmt.Errorf("%w: reason code required for rejection", ErrInvalidReasonCode)
+	}
+	if _, ok := models.ModerationReasonCodes[req.ReasonCode]; !ok || req.ReasonCode == "approved" || req.ReasonCode == "not_actionable" {
+		return nil, fmt.Errorf("%w for rejection: %s", ErrInvalidReasonCode, req.ReasonCode)
+	}
+	item, err := s.repo.Decide(ctx, id, moderatorID, models.ModerationStatusRejected, req.ReasonCode, req.Notes)
+	if err != nil {
+		return nil, err
+	}
+	s.publishDecision(ctx, item, false)
+	return item, nil
+}
+
+// publishDecision tells the rest of the app about decisions on items that
+// someone is waiting for: verification videos and held chat messages.
+func (s *moderationService) publishDecision(ctx context.Context, item *models.ModerationItem, approved bool) {
+	if item.UserID == nil {
+		return
+	}
+	switch item.ItemType {
+	case models.ModerationItemVideo:
+		s.publishVerification(ctx, item, approved)
+	case models.ModerationItemMessage:
+		s.events.Publish(ctx, events.Event{
+			Type:    events.MessageModerated,
+			UserIDs: []uuid.UUID{*item.UserID},
+			Data:    item,
+		})
+	}
+}
+
+// publishVerification tells the owner of a reviewed verification video the
+// outcome.
+func (s *moderationService) publishVerification(ctx context.Context, item *models.ModerationItem, verified bool) {
+
+	result := &models.VerificationResult{
+		UserID:   *item.UserID,
+		Verified: verified,
+		VideoID:  &item.ItemID,
+	}
+	if !verified && item.DecisionReasonCode != nil {
+		result.ReasonCode = *item.DecisionReasonCode
+	}
+	s.events.Publish(ctx, events.Event{
+		Type:    events.VerificationDecided,
+		UserIDs: []uuid.UUID{*item.UserID},
+		Data:    result,
+	})
+}
+
+func (s *moderationService) AuditTrail(ctx context.Context, id uuid.UUID) ([]*models.ModerationAuditEntry, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAudit(ctx, id)
+}
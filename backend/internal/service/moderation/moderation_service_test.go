@@ -0,0 +1,223 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/service/events"
+	"github.com/google/uuid"
+)
+
+// fakeModerationRepo keeps items in memory with the claim rules of the
+// conditional updates in the real repository.
+type fakeModerationRepo struct {
+	postgres.ModerationRepository
+	items   map[uuid.UUID]*models.ModerationItem
+	decided int
+}
+
+func (r *fakeModerationRepo) add(item *models.ModerationItem) *models.ModerationItem {
+	item.ID = uuid.New()
+	if item.Status == "" {
+		item.Status = models.ModerationStatusPending
+	}
+	r.items[item.ID] = item
+	return item
+}
+
+func (r *fakeModerationRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.ModerationItem, error) {
+	item, ok := r.items[id]
+	if !ok {
+		return nil, postgres.ErrModerationItemNotFound
+	}
+	return item, nil
+}
+
+func (r *fakeModerationRepo) Claim(ctx context.Context, id, moderatorID uuid.UUID, ttl time.Duration) (*models.ModerationItem, error) {
+	item, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	claimable := item.Status == models.ModerationStatusPending ||
+		(item.Status == models.ModerationStatusClaimed && (item.ClaimExpiresAt.Before(time.Now()) || *item.ClaimedBy == moderatorID))
+	if !claimable {
+		return nil, postgres.ErrModerationItemUnavailable
+	}
+	expires := time.Now().Add(ttl)
+	item.Status, item.ClaimedBy, item.ClaimExpiresAt = models.ModerationStatusClaimed, &moderatorID, &expires
+	return item, nil
+}
+
+func (r *fakeModerationRepo) Decide(ctx context.Context, id, moderatorID uuid.UUID, status, reasonCode, notes string) (*models.ModerationItem, error) {
+	item, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status != models.ModerationStatusClaimed || *item.ClaimedBy != moderatorID || item.ClaimExpiresAt.Before(time.Now()) {
+		return nil, postgres.ErrModerationItemNotClaimed
+	}
+	item.Status, item.DecisionReasonCode, item.DecidedBy = status, &reasonCode, &moderatorID
+	r.decided++
+	return item, nil
+}
+
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event events.Event) {
+	p.events = append(p.events, event)
+}
+
+func newTestService() (ModerationService, *fakeModerationRepo, *recordingPublisher) {
+	repo := &fakeModerationRepo{items: map[uuid.UUID]*models.ModerationItem{}}
+	publisher := &recordingPublisher{}
+	return NewModerationService(repo, publisher, 15*time.Minute), repo, publisher
+}
+
+func claimedBy(moderatorID uuid.UUID, expiresIn time.Duration) *models.ModerationItem {
+	expires := time.Now().Add(expiresIn)
+	return &models.ModerationItem{Status: models.ModerationStatusClaimed, ClaimedBy: &moderatorID, ClaimExpiresAt: &expires}
+}
+
+func TestClaim(t *testing.T) {
+	me, other := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name    string
+		item    *models.ModerationItem
+		wantErr error
+	}{
+		{"pending", &models.ModerationItem{}, nil},
+		{"claimed by me", claimedBy(me, time.Minute), nil},
+		{"claimed by someone else", claimedBy(other, time.Minute), postgres.ErrModerationItemUnavailable},
+		{"expired claim by someone else", claimedBy(other, -time.Minute), nil},
+		{"decided", &models.ModerationItem{Status: models.ModerationStatusApproved}, postgres.ErrModerationItemUnavailable},
+		{"unknown", nil, postgres.ErrModerationItemNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, _ := newTestService()
+			id := uuid.New()
+			if tt.item != nil {
+				id = repo.add(tt.item).ID
+			}
+
+			item, err := svc.Claim(context.Background(), id, me)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Claim() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && *item.ClaimedBy != me {
+				t.Errorf("claimed by %v, want %v", *item.ClaimedBy, me)
+			}
+		})
+	}
+}
+
+func TestDecideRequiresClaim(t *testing.T) {
+	me, other := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name    string
+		item    *models.ModerationItem
+		wantErr error
+	}{
+		{"claimed by me", claimedBy(me, time.Minute), nil},
+		{"claimed by someone else", claimedBy(other, time.Minute), postgres.ErrModerationItemNotClaimed},
+		{"my claim expired", claimedBy(me, -time.Minute), postgres.ErrModerationItemNotClaimed},
+		{"unclaimed", &models.ModerationItem{}, postgres.ErrModerationItemNotClaimed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, _ := newTestService()
+			item := repo.add(tt.item)
+
+			_, err := svc.Reject(context.Background(), item.ID, me, &models.ModerationDecisionRequest{ReasonCode: "spam"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Reject() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && item.Status != models.ModerationStatusRejected {
+				t.Errorf("status = %s, want rejected", item.Status)
+			}
+		})
+	}
+}
+
+func TestReasonCodes(t *testing.T) {
+	tests := []struct {
+		name       string
+		approve    bool
+		reasonCode string
+		wantErr    error
+	}{
+		{"approve by default", true, "", nil},
+		{"approve not actionable", true, "not_actionable", nil},
+		{"approve with a rejection code", true, "spam", ErrInvalidReasonCode},
+		{"reject", false, "nudity", nil},
+		{"reject without a code", false, "", ErrInvalidReasonCode},
+		{"reject with an approval code", false, "approved", ErrInvalidReasonCode},
+		{"reject as not actionable", false, "not_actionable", ErrInvalidReasonCode},
+		{"reject with an unknown code", false, "rude", ErrInvalidReasonCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, _ := newTestService()
+			me := uuid.New()
+			item := repo.add(claimedBy(me, time.Minute))
+
+			req := &models.ModerationDecisionRequest{ReasonCode: tt.reasonCode}
+			var err error
+			if tt.approve {
+				_, err = svc.Approve(context.Background(), item.ID, me, req)
+			} else {
+				_, err = svc.Reject(context.Background(), item.ID, me, req)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if wantDecided := tt.wantErr == nil; (repo.decided == 1) != wantDecided {
+				t.Errorf("decided %d times, want decided = %v", repo.decided, wantDecided)
+			}
+		})
+	}
+}
+
+func TestDecisionEvents(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name     string
+		itemType string
+		userID   *uuid.UUID
+		want     string
+	}{
+		{"video", models.ModerationItemVideo, &userID, events.VerificationDecided},
+		{"message", models.ModerationItemMessage, &userID, events.MessageModerated},
+		{"bio", models.ModerationItemBio, &userID, ""},
+		{"no owner", models.ModerationItemVideo, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, publisher := newTestService()
+			me := uuid.New()
+			item := claimedBy(me, time.Minute)
+			item.ItemType, item.UserID = tt.itemType, tt.userID
+			repo.add(item)
+
+			if _, err := svc.Approve(context.Background(), item.ID, me, &models.ModerationDecisionRequest{}); err != nil {
+				t.Fatalf("Approve() error = %v", err)
+			}
+			var got string
+			if len(publisher.events) > 0 {
+				got = publisher.events[0].Type
+			}
+			if len(publisher.events) > 1 || got != tt.want {
+				t.Errorf("events = %v, want %q", publisher.events, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/service/events"
+)
+
+// dispatchTimeout bounds storing and delivering the notifications of one
+// event.
+const dispatchTimeout = 10 * time.Second
+
+// Dispatcher turns domain events into notifications. Events are queued and
+// handled by background workers so publishers are not held up; when the
+// queue is full the publisher handles the event itself.
+type Dispatcher struct {
+	service NotificationService
+	queue   chan queuedEvent
+	workers int
+}
+
+// queuedEvent keeps the publisher's context, without its cancellation, so
+// the worker handling the event continues its trace and request ID.
+type queuedEvent struct {
+	ctx   context.Context
+	event events.Event
+}
+
+func NewDispatcher(service NotificationService, workers, queueSize int) *Dispatcher {
+	if workers <= 0 {
+		workers = 4
+	}
+	if queueSize <= 0 {
+		queueSize = 1000
+	}
+	return &Dispatcher{
+		service: service,
+		queue:   make(chan queuedEvent, queueSize),
+		workers: workers,
+	}
+}
+
+// Subscribe registers the dispatcher for every event that notifies users.
+func (d *Dispatcher) Subscribe(bus *events.Bus) {
+	for _, eventType := range []string{
+		events.MatchCreated,
+		events.MatchMutual,
+		events.MatchExpiring,
+		events.CallMissed,
+		events.VerificationDecided,
+	} {
+		bus.Subscribe(eventType, d.enqueue)
+	}
+}
+
+func (d *Dispatcher) enqueue(ctx context.Context, event events.Event) {
+	select {
+	case d.queue <- queuedEvent{ctx: context.WithoutCancel(ctx), event: event}:
+	default:
+		d.handle(ctx, event)
+	}
+}
+
+// Start runs the workers until ctx is cancelled. Events still queued then
+// are dropped.
+func (d *Dispatcher) Start(ctx context.Context) {
+	for i := 0; i < d.workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case queued := <-d.queue:
+					d.handle(queued.ctx, queued.event)
+				}
+			}
+		}()
+	}
+}
+
+func (d *Dispatcher) handle(ctx context.Context, event events.Event) {
+	notifications, err := notificationsFor(event)
+	if err != nil {
+		slog.WarnContext(ctx, "Skipping notifications", "event", event.Type, "error", err)
+		return
+	}
+
+	// Requests that published the event may finish before it is handled
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), dispatchTimeout)
+	defer cancel()
+	for _, n := range notifications {
+		if err := d.service.Notify(ctx, n); err != nil {
+			slog.ErrorContext(ctx, "Failed to notify user", "user_id", n.UserID, "event", event.Type, "error", err)
+		}
+	}
+}
+
+// notificationsFor builds the notification each recipient of an event gets.
+func notificationsFor(event events.Event) ([]*models.Notification, error) {
+	var template models.Notification
+
+	switch event.Type {
+	case events.MatchCreated, events.MatchMutual, events.MatchExpiring:
+		match, ok := event.Data.(*models.Match)
+		if !ok {
+			return nil, fmt.Errorf("unexpected data %T", event.Data)
+		}
+		template.Data = map[string]interface{}{"match_id": match.ID}
+		switch event.Type {
+		case events.MatchCreated:
+			template.Type = models.NotificationNewMatch
+			template.Title = "You have a new match"
+			template.Body = "Someone new is waiting for you. Say hello before the match expires."
+			template.Data["expires_at"] = match.ExpiresAt
+		case events.MatchMutual:
+			template.Type = models.NotificationMutualMatch
+			template.Title = "It's a match!"
+			template.Body = "You both said yes. Start a video call to meet."
+		case events.MatchExpiring:
+			template.Type = models.NotificationMatchExpiring
+			template.Title = "Your match expires soon"
+			template.Body = "Make your move before the match is gone."
+			template.Data["expires_at"] = match.ExpiresAt
+		}
+
+	case events.CallMissed:
+		call, ok := event.Data.(*models.VideoCall)
+		if !ok {
+			return nil, fmt.Errorf("unexpected data %T", event.Data)
+		}
+		template.Type = models.NotificationCallMissed
+		template.Title = "Missed video call"
+		template.Body = "You missed a call from one of your matches."
+		template.Data = map[string]interface{}{
+			"call_id":   call.ID,
+			"match_id":  call.MatchID,
+			"caller_id": call.CallerID,
+		}
+
+	case events.VerificationDecided:
+		result, ok := event.Data.(*models.VerificationResult)
+		if !ok {
+			return nil, fmt.Errorf("unexpected data %T", event.Data)
+		}
+		template.Type = models.NotificationVerificationResult
+		template.Data = map[string]interface{}{"verified": result.Verified}
+		if result.VideoID != nil {
+			template.Data["video_id"] = *result.VideoID
+		}
+		if result.Verified {
+			template.Title = "You're verified"
+			template.Body = "Your profile is verified and can now be matched."
+		} else {
+			template.Title = "Verification unsuccessful"
+			template.Body = "We couldn't verify your video. You can record a new one."
+			if result.ReasonCode != "" {
+				template.Data["reason_code"] = result.ReasonCode
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("no notification for event")
+	}
+
+	notifications := make([]*models.Notification, 0, len(event.UserIDs))
+	for _, userID := range event.UserIDs {
+		n := template
+		n.UserID = userID
+		notifications = append(notifications, &n)
+	}
+	return notifications, nil
+}
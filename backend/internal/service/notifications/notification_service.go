@@ -0,0 +1,179 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/repository/redis"
+	"github.com/google/uuid"
+)
+
+// Notifier delivers realtime messages to a user's connected devices and
+// returns how many received them.
+type Notifier interface {
+	SendToUser(userID uuid.UUID, v interface{}) (int, error)
+}
+
+// Pusher sends mobile pushes to a user's devices, subject to their
+// preferences.
+type Pusher interface {
+	Send(ctx context.Context, userID uuid.UUID, category string, msg *models.PushMessage) (int, error)
+}
+
+// NotificationService keeps each user's notification center: it stores
+// notifications, tracks which were read and pushes them to connected
+// devices.
+type NotificationService interface {
+	Notify(ctx context.Context, n *models.Notification) error
+	List(ctx context.Context, userID uuid.UUID, filter models.NotificationFilter) (*models.NotificationPage, error)
+	MarkRead(ctx context.Context, userID, notificationID uuid.UUID) (*models.Notification, error)
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	UnreadCount(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+type notificationService struct {
+	repo     postgres.NotificationRepository
+	counts   redis.NotificationCountRepository
+	notifier Notifier
+	pusher   Pusher
+}
+
+func NewNotificationService(repo postgres.NotificationRepository, counts redis.NotificationCountRepository, notifier Notifier, pusher Pusher) NotificationService {
+	return &notificationService{
+		repo:     repo,
+		counts:   counts,
+		notifier: notifier,
+		pusher:   pusher,
+	}
+}
+
+// Notify stores the notification and, if the user is online, sends it to
+// their connected devices along with the new unread count. Users with no
+// connection get a mobile push instead.
+func (s *notificationService) Notify(ctx context.Context, n *models.Notification) error {
+	if err := s.repo.Create(ctx, n); err != nil {
+		return err
+	}
+
+	count, err := s.refreshUnreadCount(ctx, n.UserID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to count unread notifications", "user_id", n.UserID, "error", err)
+	}
+	delivered, err := s.notifier.SendToUser(n.UserID, models.ServerMessage{
+		Type: models.RealtimeNotification,
+		Data: &models.NotificationDelivery{Notification: n, UnreadCount: count},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to deliver notification", "notification_id", n.ID, "error", err)
+	}
+	if delivered == 0 {
+		if _, err := s.pusher.Send(ctx, n.UserID, n.Type, pushMessage(n)); err != nil {
+			slog.ErrorContext(ctx, "Failed to push notification", "notification_id", n.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// pushMessage turns a notification into a push. Push data only carries
+// strings.
+func pushMessage(n *models.Notification) *models.PushMessage {
+	data := map[string]string{
+		"type":            n.Type,
+		"notification_id": n.ID.String(),
+	}
+	for k, v := range n.Data {
+		switch v := v.(type) {
+		case string:
+			data[k] = v
+		case time.Time:
+			data[k] = v.Format(time.RFC3339)
+		default:
+			data[k] = fmt.Sprint(v)
+		}
+	}
+
+	msg := &models.PushMessage{
+		Title:    n.Title,
+		Body:     n.Body,
+		Data:     data,
+		Priority: models.PushPriorityNormal,
+	}
+	if n.Type == models.NotificationCallMissed {
+		// Replaces the incoming call push if the device never showed it
+		msg.CollapseKey = "call:" + data["call_id"]
+	}
+	return msg
+}
+
+func (s *notificationService) List(ctx context.Context, userID uuid.UUID, filter models.NotificationFilter) (*models.NotificationPage, error) {
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	notifications, next, err := s.repo.List(ctx, userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.counts.GetUnreadCount(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.NotificationPage{
+		Notifications: notifications,
+		NextCursor:    next,
+		UnreadCount:   count,
+	}, nil
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, userID, notificationID uuid.UUID) (*models.Notification, error) {
+	n, err := s.repo.MarkRead(ctx, userID, notificationID)
+	if err != nil {
+		return nil, err
+	}
+	s.syncUnreadCount(ctx, userID)
+	return n, nil
+}
+
+func (s *notificationService) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	marked, err := s.repo.MarkAllRead(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if marked > 0 {
+		s.syncUnreadCount(ctx, userID)
+	}
+	return marked, nil
+}
+
+func (s *notificationService) UnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.counts.GetUnreadCount(ctx, userID)
+}
+
+// refreshUnreadCount drops the cached count after a change and reloads it.
+func (s *notificationService) refreshUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	if err := s.counts.InvalidateUnreadCount(ctx, userID); err != nil {
+		return 0, err
+	}
+	return s.counts.GetUnreadCount(ctx, userID)
+}
+
+// syncUnreadCount tells the user's other devices about notifications read
+// on this one.
+func (s *notificationService) syncUnreadCount(ctx context.Context, userID uuid.UUID) {
+	count, err := s.refreshUnreadCount(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to count unread notifications", "user_id", userID, "error", err)
+		return
+	}
+	_, err = s.notifier.SendToUser(userID, models.ServerMessage{
+		Type: models.RealtimeUnreadCount,
+		Data: &models.UnreadCount{UnreadCount: count},
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to send unread count", "user_id", userID, "error", err)
+	}
+}
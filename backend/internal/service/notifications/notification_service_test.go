@@ -0,0 +1,175 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/repository/redis"
+	"github.com/alexcolls/findme/internal/service/events"
+	"github.com/google/uuid"
+)
+
+type fakeNotificationRepo struct {
+	postgres.NotificationRepository
+	created []*models.Notification
+}
+
+func (r *fakeNotificationRepo) Create(ctx context.Context, n *models.Notification) error {
+	n.ID = uuid.New()
+	n.CreatedAt = time.Now()
+	r.created = append(r.created, n)
+	return nil
+}
+
+func (r *fakeNotificationRepo) CountUnread(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	for _, n := range r.created {
+		if n.UserID == userID && n.ReadAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// fakeCounts reads straight through to the repository.
+type fakeCounts struct {
+	redis.NotificationCountRepository
+	repo *fakeNotificationRepo
+}
+
+func (c *fakeCounts) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return c.repo.CountUnread(ctx, userID)
+}
+
+func (c *fakeCounts) InvalidateUnreadCount(ctx context.Context, userID uuid.UUID) error {
+	return nil
+}
+
+type fakeNotifier struct {
+	sent    map[uuid.UUID][]models.ServerMessage
+	offline bool
+}
+
+func (n *fakeNotifier) SendToUser(userID uuid.UUID, v interface{}) (int, error) {
+	if n.offline {
+		return 0, nil
+	}
+	n.sent[userID] = append(n.sent[userID], v.(models.ServerMessage))
+	return 1, nil
+}
+
+type fakePusher struct {
+	pushed []*models.PushMessage
+}
+
+func (p *fakePusher) Send(ctx context.Context, userID uuid.UUID, category string, msg *models.PushMessage) (int, error) {
+	p.pushed = append(p.pushed, msg)
+	return 1, nil
+}
+
+func TestDispatchEvents(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	match := &models.Match{ID: uuid.New(), UserID: alice, MatchedUserID: bob, ExpiresAt: time.Now().Add(24 * time.Hour)}
+	call := &models.VideoCall{ID: uuid.New(), MatchID: match.ID, CallerID: alice, CalleeID: bob}
+	videoID := uuid.New()
+
+	tests := []struct {
+		name     string
+		event    events.Event
+		wantType string
+		wantData map[string]interface{}
+	}{
+		{"new match", events.Event{Type: events.MatchCreated, UserIDs: []uuid.UUID{alice, bob}, Data: match},
+			models.NotificationNewMatch, map[string]interface{}{"match_id": match.ID}},
+		{"mutual match", events.Event{Type: events.MatchMutual, UserIDs: []uuid.UUID{alice, bob}, Data: match},
+			models.NotificationMutualMatch, map[string]interface{}{"match_id": match.ID}},
+		{"match expiring", events.Event{Type: events.MatchExpiring, UserIDs: []uuid.UUID{bob}, Data: match},
+			models.NotificationMatchExpiring, map[string]interface{}{"match_id": match.ID}},
+		{"call missed", events.Event{Type: events.CallMissed, UserIDs: []uuid.UUID{bob}, Data: call},
+			models.NotificationCallMissed, map[string]interface{}{"call_id": call.ID, "caller_id": alice}},
+		{"verification rejected", events.Event{Type: events.VerificationDecided, UserIDs: []uuid.UUID{bob},
+			Data: &models.VerificationResult{UserID: bob, VideoID: &videoID, ReasonCode: "low_quality"}},
+			models.NotificationVerificationResult, map[string]interface{}{"verified": false, "reason_code": "low_quality"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeNotificationRepo{}
+			notifier := &fakeNotifier{sent: map[uuid.UUID][]models.ServerMessage{}}
+			service := NewNotificationService(repo, &fakeCounts{repo: repo}, notifier, &fakePusher{})
+			NewDispatcher(service, 1, 1).handle(context.Background(), tt.event)
+
+			if len(repo.created) != len(tt.event.UserIDs) {
+				t.Fatalf("created %d notifications, want one per recipient", len(repo.created))
+			}
+			for i, n := range repo.created {
+				if n.UserID != tt.event.UserIDs[i] || n.Type != tt.wantType || n.Title == "" {
+					t.Errorf("notification = %+v, want a %s for %s", n, tt.wantType, tt.event.UserIDs[i])
+				}
+				for key, want := range tt.wantData {
+					if n.Data[key] != want {
+						t.Errorf("data[%s] = %v, want %v", key, n.Data[key], want)
+					}
+				}
+
+				sent := notifier.sent[n.UserID]
+				delivery, ok := sent[len(sent)-1].Data.(*models.NotificationDelivery)
+				if !ok || delivery.Notification != n || delivery.UnreadCount != 1 {
+					t.Errorf("pushed %+v, want the notification with one unread", sent[len(sent)-1])
+				}
+			}
+		})
+	}
+}
+
+func TestDispatchIgnoresUnexpectedData(t *testing.T) {
+	repo := &fakeNotificationRepo{}
+	service := NewNotificationService(repo, &fakeCounts{repo: repo}, &fakeNotifier{sent: map[uuid.UUID][]models.ServerMessage{}}, &fakePusher{})
+	NewDispatcher(service, 1, 1).handle(context.Background(), events.Event{
+		Type:    events.MatchMutual,
+		UserIDs: []uuid.UUID{uuid.New()},
+		Data:    "not a match",
+	})
+	if len(repo.created) != 0 {
+		t.Errorf("created %d notifications from malformed event", len(repo.created))
+	}
+}
+
+func TestNotifyPushesOfflineUsers(t *testing.T) {
+	repo := &fakeNotificationRepo{}
+	notifier := &fakeNotifier{sent: map[uuid.UUID][]models.ServerMessage{}}
+	pusher := &fakePusher{}
+	service := NewNotificationService(repo, &fakeCounts{repo: repo}, notifier, pusher)
+
+	if err := service.Notify(context.Background(), &models.Notification{UserID: uuid.New(), Type: models.NotificationMutualMatch}); err != nil {
+		t.Fatal(err)
+	}
+	if len(pusher.pushed) != 0 {
+		t.Errorf("pushed %d times to an online user", len(pusher.pushed))
+	}
+
+	notifier.offline = true
+	callID := uuid.New()
+	expiresAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	err := service.Notify(context.Background(), &models.Notification{
+		UserID: uuid.New(),
+		Type:   models.NotificationCallMissed,
+		Data:   map[string]interface{}{"call_id": callID, "expires_at": expiresAt},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pusher.pushed) != 1 {
+		t.Fatalf("pushed %d times to an offline user, want 1", len(pusher.pushed))
+	}
+	msg := pusher.pushed[0]
+	if msg.Data["call_id"] != callID.String() || msg.Data["expires_at"] != "2024-05-01T12:00:00Z" {
+		t.Errorf("push data = %v, want string values", msg.Data)
+	}
+	if msg.CollapseKey != "call:"+callID.String() {
+		t.Errorf("collapse key = %q, want the call's", msg.CollapseKey)
+	}
+}
@@ -0,0 +1,183 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	apnsProductionURL = "https://api.push.apple.com"
+	apnsSandboxURL    = "https://api.sandbox.push.apple.com"
+	// Apple rejects provider tokens older than an hour and throttles
+	// refreshing them more often than every 20 minutes
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+type APNsConfig struct {
+	// KeyPath is the .p8 signing key downloaded from the developer account
+	KeyPath string
+	KeyID   string
+	TeamID  string
+	// Topic is the app's bundle ID
+	Topic      string
+	Production bool
+}
+
+// APNsProvider sends pushes to iOS devices over HTTP/2, authenticating with
+// a JWT signed by the team's key rather than a certificate.
+type APNsProvider struct {
+	key        *ecdsa.PrivateKey
+	keyID      string
+	teamID     string
+	topic      string
+	baseURL    string
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu          sync.Mutex
+	token       string
+	tokenIssued time.Time
+}
+
+func NewAPNsProvider(cfg APNsConfig) (*APNsProvider, error) {
+	if cfg.KeyID == "" || cfg.TeamID == "" || cfg.Topic == "" {
+		return nil, fmt.Errorf("APNS_KEY_ID, APNS_TEAM_ID and APNS_BUNDLE_ID are required")
+	}
+	pem, err := os.ReadFile(cfg.KeyPath)
+	if err != nil {
+		return nil, err
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(pem)
+	if err != nil {
+		return nil, fmt.Errorf("invalid APNs key: %w", err)
+	}
+
+	baseURL := apnsSandboxURL
+	if cfg.Production {
+		baseURL = apnsProductionURL
+	}
+	return &APNsProvider{
+		key:     key,
+		keyID:   cfg.KeyID,
+		teamID:  cfg.TeamID,
+		topic:   cfg.Topic,
+		baseURL: baseURL,
+		httpClient: &http.Client{
+			Transport: &http.Transport{ForceAttemptHTTP2: true},
+			Timeout:   10 * time.Second,
+		},
+		now: time.Now,
+	}, nil
+}
+
+// authToken returns the provider token, signing a new one when the current
+// one is about to expire.
+func (p *APNsProvider) authToken() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	if p.token != "" && now.Sub(p.tokenIssued) < apnsTokenLifetime {
+		return p.token, nil
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": p.teamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = p.keyID
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		return "", err
+	}
+	p.token, p.tokenIssued = signed, now
+	return signed, nil
+}
+
+func (p *APNsProvider) resetToken() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.token = ""
+}
+
+type apnsAlert struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type apnsError struct {
+	Reason string `json:"reason"`
+}
+
+func (p *APNsProvider) Send(ctx context.Context, msg *models.PushMessage) error {
+	aps := map[string]interface{}{
+		"alert": apnsAlert{Title: msg.Title, Body: msg.Body},
+		"sound": "default",
+	}
+	if msg.Priority == models.PushPriorityHigh {
+		// Breaks through Focus modes the user allows time-sensitive alerts in
+		aps["interruption-level"] = "time-sensitive"
+	}
+	payload := map[string]interface{}{"aps": aps}
+	for k, v := range msg.Data {
+		payload[k] = v
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	token, err := p.authToken()
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/3/device/"+msg.Token, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("authorization", "bearer "+token)
+	req.Header.Set("apns-topic", p.topic)
+	req.Header.Set("apns-push-type", "alert")
+	if msg.Priority == models.PushPriorityHigh {
+		req.Header.Set("apns-priority", "10")
+	} else {
+		req.Header.Set("apns-priority", "5")
+	}
+	if msg.CollapseKey != "" {
+		req.Header.Set("apns-collapse-id", msg.CollapseKey)
+	}
+	if msg.TTL > 0 {
+		req.Header.Set("apns-expiration", strconv.FormatInt(p.now().Add(msg.TTL).Unix(), 10))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("APNs request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var apnsErr apnsError
+	json.NewDecoder(resp.Body).Decode(&apnsErr)
+	switch apnsErr.Reason {
+	case "BadDeviceToken", "Unregistered", "DeviceTokenNotForTopic":
+		return fmt.Errorf("%w: %s", ErrInvalidToken, apnsErr.Reason)
+	case "ExpiredProviderToken", "InvalidProviderToken":
+		p.resetToken()
+	}
+	return fmt.Errorf("APNs rejected push with status %d: %s", resp.StatusCode, apnsErr.Reason)
+}
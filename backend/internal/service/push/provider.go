@@ -0,0 +1,103 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+)
+
+// ErrInvalidToken means the provider will never deliver to the token again,
+// usually because the app was uninstalled, so it should be forgotten.
+var ErrInvalidToken = errors.New("device token is no longer valid")
+
+// Provider sends pushes to the devices of one platform.
+type Provider interface {
+	Send(ctx context.Context, msg *models.PushMessage) error
+}
+
+type Config struct {
+	Enabled bool
+
+	APNs APNsConfig
+	// FCMCredentialsFile is the path of a Firebase service account key
+	FCMCredentialsFile string
+}
+
+// NewProviders returns the provider for each platform. Platforms without
+// credentials get a fake that only logs, so development works without
+// them. Nothing is returned when push is disabled.
+func NewProviders(cfg Config) (map[string]Provider, error) {
+	providers := map[string]Provider{}
+	if !cfg.Enabled {
+		return providers, nil
+	}
+
+	if cfg.APNs.KeyPath != "" {
+		apns, err := NewAPNsProvider(cfg.APNs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create APNs provider: %w", err)
+		}
+		providers[models.PlatformIOS] = apns
+	} else {
+		slog.Warn("APNS_KEY_PATH not set, iOS pushes will only be logged")
+		providers[models.PlatformIOS] = NewFakeProvider(true)
+	}
+
+	if cfg.FCMCredentialsFile != "" {
+		fcm, err := NewFCMProvider(cfg.FCMCredentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create FCM provider: %w", err)
+		}
+		providers[models.PlatformAndroid] = fcm
+	} else {
+		slog.Warn("FCM_CREDENTIALS_FILE not set, Android pushes will only be logged")
+		providers[models.PlatformAndroid] = NewFakeProvider(true)
+	}
+	return providers, nil
+}
+
+// FakeProvider records pushes instead of sending them.
+type FakeProvider struct {
+	mu      sync.Mutex
+	sent    []*models.PushMessage
+	invalid map[string]bool
+	logSent bool
+}
+
+// NewFakeProvider returns a fake that also logs each push when logSent is
+// set.
+func NewFakeProvider(logSent bool) *FakeProvider {
+	return &FakeProvider{invalid: map[string]bool{}, logSent: logSent}
+}
+
+// Invalidate makes pushes to token fail with ErrInvalidToken.
+func (p *FakeProvider) Invalidate(token string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.invalid[token] = true
+}
+
+// Sent returns the pushes sent so far.
+func (p *FakeProvider) Sent() []*models.PushMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*models.PushMessage(nil), p.sent...)
+}
+
+func (p *FakeProvider) Send(ctx context.Context, msg *models.PushMessage) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.invalid[msg.Token] {
+		return ErrInvalidToken
+	}
+	p.sent = append(p.sent, msg)
+	if p.logSent {
+		slog.InfoContext(ctx, "Push to device", "platform", msg.Platform, "priority", msg.Priority, "title", msg.Title, "body", msg.Body)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+)
+
+func writeKey(t *testing.T, name string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAPNsSend(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	keyPath := writeKey(t, "AuthKey.p8", der)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("authorization"), "bearer ") || r.Header.Get("apns-topic") != "com.findme.app" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"reason":"InvalidProviderToken"}`))
+			return
+		}
+		if r.URL.Path == "/3/device/gone" {
+			w.WriteHeader(http.StatusGone)
+			w.Write([]byte(`{"reason":"Unregistered"}`))
+			return
+		}
+		if r.Header.Get("apns-priority") != "10" || r.Header.Get("apns-collapse-id") != "call:1" {
+			t.Errorf("headers = %v, want high priority with the collapse id", r.Header)
+		}
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		if payload["call_id"] != "1" || payload["aps"] == nil {
+			t.Errorf("payload = %v", payload)
+		}
+	}))
+	defer server.Close()
+
+	p, err := NewAPNsProvider(APNsConfig{KeyPath: keyPath, KeyID: "KEY", TeamID: "TEAM", Topic: "com.findme.app"})
+	if err != nil {
+		t.Fatalf("NewAPNsProvider() error = %v", err)
+	}
+	p.baseURL = server.URL
+	p.httpClient = server.Client()
+
+	msg := &models.PushMessage{
+		Token: "device", Title: "Incoming video call", Data: map[string]string{"call_id": "1"},
+		Priority: models.PushPriorityHigh, CollapseKey: "call:1", TTL: 30 * time.Second,
+	}
+	if err := p.Send(context.Background(), msg); err != nil {
+		t.Errorf("Send() error = %v", err)
+	}
+	msg.Token = "gone"
+	if err := p.Send(context.Background(), msg); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Send() to unregistered token error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestFCMSend(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	der, _ := x509.MarshalPKCS8PrivateKey(key)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	tokenRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+			if r.FormValue("assertion") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"access_token":"access","expires_in":3600}`))
+		case "/projects/findme/messages:send":
+			if r.Header.Get("Authorization") != "Bearer access" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var body struct {
+				Message fcmMessage `json:"message"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.Message.Token == "gone" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":{"code":404,"status":"NOT_FOUND","message":"Requested entity was not found.",
+					"details":[{"errorCode":"UNREGISTERED"}]}}`))
+				return
+			}
+			if body.Message.Android.Priority != "HIGH" || body.Message.Android.TTL != "30s" {
+				t.Errorf("android config = %+v", body.Message.Android)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	credentials, _ := json.Marshal(serviceAccount{
+		ProjectID: "findme", ClientEmail: "push@findme.iam.gserviceaccount.com",
+		PrivateKey: string(keyPEM), TokenURI: server.URL + "/token",
+	})
+	path := filepath.Join(t.TempDir(), "service-account.json")
+	os.WriteFile(path, credentials, 0o600)
+
+	p, err := NewFCMProvider(path)
+	if err != nil {
+		t.Fatalf("NewFCMProvider() error = %v", err)
+	}
+	p.baseURL = server.URL
+
+	msg := &models.PushMessage{Token: "device", Title: "Call", Priority: models.PushPriorityHigh, TTL: 30 * time.Second}
+	if err := p.Send(context.Background(), msg); err != nil {
+		t.Errorf("Send() error = %v", err)
+	}
+	msg.Token = "gone"
+	if err := p.Send(context.Background(), msg); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Send() to unregistered token error = %v, want ErrInvalidToken", err)
+	}
+	if tokenRequests != 1 {
+		t.Errorf("fetched %d access tokens, want the first one reused", tokenRequests)
+	}
+}
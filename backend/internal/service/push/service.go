@@ -0,0 +1,149 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidQuietHours = errors.New("quiet hours must both be HH:MM or both be empty")
+	ErrInvalidTimezone   = errors.New("unknown timezone")
+)
+
+// PushService registers the devices of each user and sends them pushes
+// according to their preferences.
+type PushService interface {
+	RegisterDevice(ctx context.Context, userID uuid.UUID, req *models.RegisterDeviceRequest) (*models.DeviceToken, error)
+	UnregisterDevice(ctx context.Context, userID uuid.UUID, token string) error
+	GetPreferences(ctx context.Context, userID uuid.UUID) (*models.NotificationPreferences, error)
+	UpdatePreferences(ctx context.Context, userID uuid.UUID, req *models.UpdateNotificationPreferencesRequest) (*models.NotificationPreferences, error)
+	// Send pushes msg to every device of the user unless they turned the
+	// category off or are in quiet hours, and returns how many devices
+	// accepted it. Token and Platform are filled in per device.
+	Send(ctx context.Context, userID uuid.UUID, category string, msg *models.PushMessage) (int, error)
+}
+
+type pushService struct {
+	repo      postgres.PushRepository
+	providers map[string]Provider
+	now       func() time.Time
+}
+
+func NewPushService(repo postgres.PushRepository, providers map[string]Provider) PushService {
+	return &pushService{
+		repo:      repo,
+		providers: providers,
+		now:       time.Now,
+	}
+}
+
+func (s *pushService) RegisterDevice(ctx context.Context, userID uuid.UUID, req *models.RegisterDeviceRequest) (*models.DeviceToken, error) {
+	return s.repo.RegisterDevice(ctx, userID, req.Platform, req.Token)
+}
+
+func (s *pushService) UnregisterDevice(ctx context.Context, userID uuid.UUID, token string) error {
+	return s.repo.UnregisterDevice(ctx, userID, token)
+}
+
+func (s *pushService) GetPreferences(ctx context.Context, userID uuid.UUID) (*models.NotificationPreferences, error) {
+	return s.repo.GetPreferences(ctx, userID)
+}
+
+func (s *pushService) UpdatePreferences(ctx context.Context, userID uuid.UUID, req *models.UpdateNotificationPreferencesRequest) (*models.NotificationPreferences, error) {
+	prefs, err := s.repo.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	setBool(&prefs.NewMatch, req.NewMatch)
+	setBool(&prefs.MutualMatch, req.MutualMatch)
+	setBool(&prefs.MatchExpiring, req.MatchExpiring)
+	setBool(&prefs.IncomingCall, req.IncomingCall)
+	setBool(&prefs.CallMissed, req.CallMissed)
+	setBool(&prefs.VerificationResult, req.VerificationResult)
+
+	if req.QuietHoursStart != nil || req.QuietHoursEnd != nil {
+		if req.QuietHoursStart == nil || req.QuietHoursEnd == nil {
+			return nil, ErrInvalidQuietHours
+		}
+		start, end := *req.QuietHoursStart, *req.QuietHoursEnd
+		switch {
+		case start == "" && end == "":
+			prefs.QuietHoursStart, prefs.QuietHoursEnd = nil, nil
+		case validClock(start) && validClock(end):
+			prefs.QuietHoursStart, prefs.QuietHoursEnd = &start, &end
+		default:
+			return nil, ErrInvalidQuietHours
+		}
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" {
+			return nil, ErrInvalidTimezone
+		}
+		prefs.Timezone = *req.Timezone
+	}
+
+	return s.repo.SavePreferences(ctx, userID, prefs)
+}
+
+func setBool(dst *bool, v *bool) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func validClock(s string) bool {
+	_, err := models.ParseClock(s)
+	return err == nil
+}
+
+func (s *pushService) Send(ctx context.Context, userID uuid.UUID, category string, msg *models.PushMessage) (int, error) {
+	if len(s.providers) == 0 {
+		return 0, nil
+	}
+
+	prefs, err := s.repo.GetPreferences(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if !prefs.Allows(category) || prefs.InQuietHours(s.now()) {
+		return 0, nil
+	}
+
+	devices, err := s.repo.ListDevices(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var invalid []string
+	for _, device := range devices {
+		provider, ok := s.providers[device.Platform]
+		if !ok {
+			continue
+		}
+		m := *msg
+		m.Token, m.Platform = device.Token, device.Platform
+
+		err := provider.Send(ctx, &m)
+		switch {
+		case err == nil:
+			sent++
+		case errors.Is(err, ErrInvalidToken):
+			invalid = append(invalid, device.Token)
+		default:
+			slog.ErrorContext(ctx, "Failed to push to device", "platform", device.Platform, "device_id", device.ID, "error", err)
+		}
+	}
+
+	if err := s.repo.DeleteTokens(ctx, invalid); err != nil {
+		slog.ErrorContext(ctx, "Failed to prune invalid device tokens", "user_id", userID, "error", err)
+	}
+	return sent, nil
+}
@@ -0,0 +1,144 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+type fakePushRepo struct {
+	postgres.PushRepository
+	devices []*models.DeviceToken
+	prefs   *models.NotificationPreferences
+	deleted []string
+}
+
+func (r *fakePushRepo) ListDevices(ctx context.Context, userID uuid.UUID) ([]*models.DeviceToken, error) {
+	return r.devices, nil
+}
+
+func (r *fakePushRepo) DeleteTokens(ctx context.Context, tokens []string) error {
+	r.deleted = append(r.deleted, tokens...)
+	return nil
+}
+
+func (r *fakePushRepo) GetPreferences(ctx context.Context, userID uuid.UUID) (*models.NotificationPreferences, error) {
+	if r.prefs == nil {
+		return models.DefaultNotificationPreferences(), nil
+	}
+	copied := *r.prefs
+	return &copied, nil
+}
+
+func (r *fakePushRepo) SavePreferences(ctx context.Context, userID uuid.UUID, p *models.NotificationPreferences) (*models.NotificationPreferences, error) {
+	r.prefs = p
+	return p, nil
+}
+
+func strPtr(s string) *string { return &s }
+
+func TestSendRespectsPreferences(t *testing.T) {
+	// 23:30 in Madrid
+	now := time.Date(2024, 7, 1, 21, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		prefs    *models.NotificationPreferences
+		category string
+		wantSent int
+	}{
+		{"defaults", nil, models.NotificationNewMatch, 1},
+		{"category off", &models.NotificationPreferences{Timezone: "UTC"}, models.NotificationNewMatch, 0},
+		{"quiet hours", &models.NotificationPreferences{
+			NewMatch: true, QuietHoursStart: strPtr("22:00"), QuietHoursEnd: strPtr("07:00"), Timezone: "Europe/Madrid",
+		}, models.NotificationNewMatch, 0},
+		{"outside quiet hours", &models.NotificationPreferences{
+			NewMatch: true, QuietHoursStart: strPtr("08:00"), QuietHoursEnd: strPtr("12:00"), Timezone: "Europe/Madrid",
+		}, models.NotificationNewMatch, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakePushRepo{
+				prefs:   tt.prefs,
+				devices: []*models.DeviceToken{{Platform: models.PlatformIOS, Token: "ios-token"}},
+			}
+			provider := NewFakeProvider(false)
+			s := NewPushService(repo, map[string]Provider{models.PlatformIOS: provider}).(*pushService)
+			s.now = func() time.Time { return now }
+
+			sent, err := s.Send(context.Background(), uuid.New(), tt.category, &models.PushMessage{Title: "Hi"})
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if sent != tt.wantSent || len(provider.Sent()) != tt.wantSent {
+				t.Errorf("sent %d pushes, want %d", sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestSendPrunesInvalidTokens(t *testing.T) {
+	repo := &fakePushRepo{devices: []*models.DeviceToken{
+		{Platform: models.PlatformIOS, Token: "stale"},
+		{Platform: models.PlatformIOS, Token: "fresh"},
+		{Platform: models.PlatformAndroid, Token: "android"},
+	}}
+	ios, android := NewFakeProvider(false), NewFakeProvider(false)
+	ios.Invalidate("stale")
+	s := NewPushService(repo, map[string]Provider{models.PlatformIOS: ios, models.PlatformAndroid: android})
+
+	sent, err := s.Send(context.Background(), uuid.New(), models.PushIncomingCall, &models.PushMessage{Title: "Call"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if sent != 2 {
+		t.Errorf("sent = %d, want 2", sent)
+	}
+	if len(repo.deleted) != 1 || repo.deleted[0] != "stale" {
+		t.Errorf("deleted tokens = %v, want [stale]", repo.deleted)
+	}
+	if got := android.Sent(); len(got) != 1 || got[0].Token != "android" || got[0].Platform != models.PlatformAndroid {
+		t.Errorf("android pushes = %+v", got)
+	}
+}
+
+func TestUpdatePreferences(t *testing.T) {
+	off := false
+	tests := []struct {
+		name    string
+		req     models.UpdateNotificationPreferencesRequest
+		wantErr error
+	}{
+		{"toggle category", models.UpdateNotificationPreferencesRequest{NewMatch: &off}, nil},
+		{"set quiet hours", models.UpdateNotificationPreferencesRequest{QuietHoursStart: strPtr("22:00"), QuietHoursEnd: strPtr("07:00")}, nil},
+		{"clear quiet hours", models.UpdateNotificationPreferencesRequest{QuietHoursStart: strPtr(""), QuietHoursEnd: strPtr("")}, nil},
+		{"half quiet hours", models.UpdateNotificationPreferencesRequest{QuietHoursStart: strPtr("22:00")}, ErrInvalidQuietHours},
+		{"bad clock", models.UpdateNotificationPreferencesRequest{QuietHoursStart: strPtr("25:00"), QuietHoursEnd: strPtr("07:00")}, ErrInvalidQuietHours},
+		{"bad timezone", models.UpdateNotificationPreferencesRequest{Timezone: strPtr("Mars/Olympus")}, ErrInvalidTimezone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewPushService(&fakePushRepo{}, nil)
+			prefs, err := s.UpdatePreferences(context.Background(), uuid.New(), &tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdatePreferences() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if tt.req.NewMatch != nil && prefs.NewMatch != *tt.req.NewMatch {
+				t.Errorf("new_match = %v, want %v", prefs.NewMatch, *tt.req.NewMatch)
+			}
+			if tt.req.QuietHoursStart != nil && *tt.req.QuietHoursStart == "" && prefs.QuietHoursStart != nil {
+				t.Errorf("quiet hours = %v, want them cleared", *prefs.QuietHoursStart)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// Config sets the limits applied to each WebSocket connection.
+type Config struct {
+	// MaxMessageSize is the largest message a client may send, in bytes
+	MaxMessageSize int64
+	// SendBuffer is how many outgoing messages may queue before the client
+	// is considered too slow and disconnected
+	SendBuffer   int
+	PingInterval time.Duration
+	WriteTimeout time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.MaxMessageSize <= 0 {
+		c.MaxMessageSize = 64 * 1024
+	}
+	if c.SendBuffer <= 0 {
+		c.SendBuffer = 64
+	}
+	if c.PingInterval <= 0 {
+		c.PingInterval = 25 * time.Second
+	}
+	if c.WriteTimeout <= 0 {
+		c.WriteTimeout = 10 * time.Second
+	}
+	return c
+}
+
+// Close codes the server sends when it ends a session. On CloseTokenExpired
+// the app should refresh its token and reconnect; on CloseSessionRevoked the
+// user has been logged out or suspended.
+const (
+	CloseSessionRevoked = 4001
+	CloseTokenExpired   = 4002
+)
+
+// pongWait is how long a connection may stay silent before it is dropped.
+// It allows one missed pong.
+func (c Config) pongWait() time.Duration {
+	return 2 * c.PingInterval
+}
+
+// MessageHandler processes a message read from a client.
+type MessageHandler func(ctx context.Context, c *Client, data []byte)
+
+// Client is one WebSocket connection of a user. A user may have several,
+// one per device.
+type Client struct {
+	UserID uuid.UUID
+
+	hub       *Hub
+	conn      *websocket.Conn
+	cfg       Config
+	expiresAt time.Time
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewClient wraps a connection authenticated with an access token that
+// expires at expiresAt; the connection is closed then. A zero expiresAt
+// never expires.
+func NewClient(hub *Hub, conn *websocket.Conn, userID uuid.UUID, expiresAt time.Time, cfg Config) *Client {
+	cfg = cfg.withDefaults()
+	return &Client{
+		UserID:    userID,
+		hub:       hub,
+		conn:      conn,
+		cfg:       cfg,
+		expiresAt: expiresAt,
+		send:      make(chan []byte, cfg.SendBuffer),
+		done:      make(chan struct{}),
+	}
+}
+
+// Run registers the client with the hub and serves it until the connection
+// closes. Messages are handled one at a time, in order.
+func (c *Client) Run(ctx context.Context, handle MessageHandler) {
+	c.hub.register(c)
+	defer c.hub.unregister(c)
+	defer c.Close(websocket.CloseNormalClosure, "")
+
+	if !c.expiresAt.IsZero() {
+		expiry := time.AfterFunc(time.Until(c.expiresAt), func() {
+			c.Close(CloseTokenExpired, "access token expired")
+		})
+		defer expiry.Stop()
+	}
+
+	go c.writePump()
+
+	// The read limit makes gorilla reply with 1009 and fail the read when a
+	// client sends an oversized message.
+	c.conn.SetReadLimit(c.cfg.MaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(c.cfg.pongWait()))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(c.cfg.pongWait()))
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				slog.WarnContext(ctx, "WebSocket read failed", "user_id", c.UserID, "error", err)
+			}
+			return
+		}
+		handle(ctx, c, data)
+	}
+}
+
+// Send queues a message without blocking. A client whose queue is full is
+// disconnected rather than allowed to hold up senders; it can reconnect.
+func (c *Client) Send(msg []byte) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+
+	select {
+	case c.send <- msg:
+		return true
+	default:
+		slog.Warn("WebSocket client is too slow, disconnecting", "user_id", c.UserID)
+		c.Close(websocket.CloseTryAgainLater, "client too slow")
+		return false
+	}
+}
+
+// SendJSON marshals v and queues it.
+func (c *Client) SendJSON(v interface{}) bool {
+	msg, err := json.Marshal(v)
+	if err != nil {
+		slog.Error("Failed to encode WebSocket message", "error", err)
+		return false
+	}
+	return c.Send(msg)
+}
+
+// Close sends a close frame and closes the connection. It is safe to call
+// from any goroutine, more than once.
+func (c *Client) Close(code int, reason string) {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		deadline := time.Now().Add(c.cfg.WriteTimeout)
+		c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), deadline)
+		c.conn.Close()
+	})
+}
+
+// writePump is the only writer of data frames. It also pings the client so
+// dead connections are noticed by the read deadline.
+func (c *Client) writePump() {
+	ticker := time.NewTicker(c.cfg.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(c.cfg.WriteTimeout))
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				c.Close(websocket.CloseGoingAway, "")
+				return
+			}
+		case <-ticker.C:
+			deadline := time.Now().Add(c.cfg.WriteTimeout)
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, deadline); err != nil {
+				c.Close(websocket.CloseGoingAway, "")
+				return
+			}
+		case <-c.done:
+			return
+		}
+	}
+}
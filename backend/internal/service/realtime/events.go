@@ -0,0 +1,18 @@
+package realtime
+
+import (
+	"context"
+
+	"github.com/alexcolls/findme/internal/service/events"
+)
+
+// Subscribe closes the connections of users whose sessions are revoked, so a
+// suspended or logged out user stops receiving messages and relaying signals
+// straight away.
+func Subscribe(bus *events.Bus, hub *Hub) {
+	bus.Subscribe(events.SessionsRevoked, func(ctx context.Context, event events.Event) {
+		for _, userID := range event.UserIDs {
+			hub.DisconnectUser(userID)
+		}
+	})
+}
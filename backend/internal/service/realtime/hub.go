@@ -0,0 +1,339 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisTimeout = 2 * time.Second
+	// statsInterval is how often each instance reports its connection
+	// counts; reports older than statsTTL belong to instances that are gone
+	statsInterval = 10 * time.Second
+	statsTTL      = 3 * statsInterval
+)
+
+// Hub tracks the WebSocket connections open on this instance, by user.
+//
+// A hub created with NewRedisHub also routes messages between instances:
+// each instance subscribes to a Redis channel for every user connected to it
+// and SendToUser publishes there, so a message reaches the user's devices
+// wherever they are connected. DisconnectUser goes through a channel every
+// instance subscribes to.
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[uuid.UUID]map[*Client]struct{}
+
+	redis      *redis.Client
+	pubsub     *redis.PubSub
+	prefix     string
+	instanceID string
+	closing    atomic.Bool
+}
+
+// NewHub returns a hub that only reaches connections on this instance.
+func NewHub() *Hub {
+	return &Hub{clients: map[uuid.UUID]map[*Client]struct{}{}}
+}
+
+// NewRedisHub returns a hub that shares messages with the other instances
+// through Redis pub/sub. Keys and channels are namespaced under prefix.
+// Start must be called to receive messages.
+func NewRedisHub(client *redis.Client, prefix string) *Hub {
+	h := NewHub()
+	h.redis = client
+	h.prefix = prefix + "realtime:"
+	h.pubsub = client.Subscribe(context.Background(), h.disconnectChannel())
+	h.instanceID = uuid.NewString()
+	return h
+}
+
+func (h *Hub) userChannel(userID uuid.UUID) string {
+	return h.prefix + "user:" + userID.String()
+}
+
+func (h *Hub) disconnectChannel() string {
+	return h.prefix + "disconnect"
+}
+
+func (h *Hub) statsKey() string {
+	return h.prefix + "instances"
+}
+
+// Start delivers messages published for users connected to this instance
+// and reports its connection counts until ctx is cancelled. It does nothing
+// for a local hub.
+//
+// The subscription reconnects on its own after Redis goes away and
+// resubscribes to every channel; messages published in between are lost.
+func (h *Hub) Start(ctx context.Context) {
+	if h.redis == nil {
+		return
+	}
+	go h.receive(ctx)
+	go h.reportStats(ctx)
+}
+
+func (h *Hub) receive(ctx context.Context) {
+	messages := h.pubsub.Channel(redis.WithChannelSize(1000))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			if msg.Channel == h.disconnectChannel() {
+				if userID, err := uuid.Parse(msg.Payload); err == nil {
+					h.disconnectLocal(userID)
+				}
+				continue
+			}
+			userID, err := uuid.Parse(strings.TrimPrefix(msg.Channel, h.prefix+"user:"))
+			if err != nil {
+				slog.WarnContext(ctx, "Ignoring realtime message on unexpected channel", "channel", msg.Channel)
+				continue
+			}
+			h.deliverLocal(userID, []byte(msg.Payload))
+		}
+	}
+}
+
+func (h *Hub) register(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.clients[c.UserID] == nil {
+		h.clients[c.UserID] = map[*Client]struct{}{}
+		// Subscribing under the lock keeps subscribe and unsubscribe for the
+		// same user in order. A failed subscribe is retried when the
+		// subscription reconnects.
+		if h.pubsub != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+			if err := h.pubsub.Subscribe(ctx, h.userChannel(c.UserID)); err != nil {
+				slog.ErrorContext(ctx, "Failed to subscribe to realtime messages", "user_id", c.UserID, "error", err)
+			}
+			cancel()
+		}
+	}
+	h.clients[c.UserID][c] = struct{}{}
+}
+
+func (h *Hub) unregister(c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.clients[c.UserID], c)
+	if len(h.clients[c.UserID]) == 0 {
+		delete(h.clients, c.UserID)
+		if h.pubsub != nil && !h.closing.Load() {
+			ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+			if err := h.pubsub.Unsubscribe(ctx, h.userChannel(c.UserID)); err != nil {
+				slog.ErrorContext(ctx, "Failed to unsubscribe from realtime messages", "user_id", c.UserID, "error", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// SendToUser delivers v to every connection of the user. A local hub returns
+// how many connections accepted it. A Redis hub returns how many instances
+// the user is connected to; if Redis cannot be reached it falls back to this
+// instance's connections.
+func (h *Hub) SendToUser(userID uuid.UUID, v interface{}) (int, error) {
+	msg, err := json.Marshal(v)
+	if err != nil {
+		return 0, err
+	}
+	if h.redis == nil {
+		return h.deliverLocal(userID, msg), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	n, err := h.redis.Publish(ctx, h.userChannel(userID), msg).Result()
+	if err != nil {
+		slog.Warn("Failed to publish realtime message, delivering locally", "user_id", userID, "error", err)
+		return h.deliverLocal(userID, msg), nil
+	}
+	return int(n), nil
+}
+
+func (h *Hub) deliverLocal(userID uuid.UUID, msg []byte) int {
+	delivered := 0
+	for _, c := range h.userClients(userID) {
+		if c.Send(msg) {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+func (h *Hub) userClients(userID uuid.UUID) []*Client {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	clients := make([]*Client, 0, len(h.clients[userID]))
+	for c := range h.clients[userID] {
+		clients = append(clients, c)
+	}
+	return clients
+}
+
+// DisconnectUser closes every connection of the user with
+// CloseSessionRevoked, on all instances. It is used when the user's tokens
+// are revoked, since connections are only authenticated when they open. If
+// Redis cannot be reached only this instance's connections are closed.
+func (h *Hub) DisconnectUser(userID uuid.UUID) {
+	h.disconnectLocal(userID)
+	if h.redis == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := h.redis.Publish(ctx, h.disconnectChannel(), userID.String()).Err(); err != nil {
+		slog.Error("Failed to disconnect user on other instances", "user_id", userID, "error", err)
+	}
+}
+
+func (h *Hub) disconnectLocal(userID uuid.UUID) {
+	for _, c := range h.userClients(userID) {
+		c.Close(CloseSessionRevoked, "session revoked")
+	}
+}
+
+// Connections returns how many connections the user has open on this
+// instance.
+func (h *Hub) Connections(userID uuid.UUID) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients[userID])
+}
+
+// HubStats counts connected users and their connections.
+type HubStats struct {
+	Users       int `json:"users"`
+	Connections int `json:"connections"`
+}
+
+// Stats returns the counts for this instance.
+func (h *Hub) Stats() HubStats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	stats := HubStats{Users: len(h.clients)}
+	for _, set := range h.clients {
+		stats.Connections += len(set)
+	}
+	return stats
+}
+
+// ClusterStats adds up the counts of every instance for monitoring.
+type ClusterStats struct {
+	Instances int `json:"instances"`
+	// Users counts a user connected to two instances twice
+	Users       int      `json:"users"`
+	Connections int      `json:"connections"`
+	Local       HubStats `json:"local"`
+}
+
+type instanceStats struct {
+	HubStats
+	ReportedAt time.Time `json:"reported_at"`
+}
+
+// ClusterStats returns the counts reported by all live instances. A local
+// hub only knows its own.
+func (h *Hub) ClusterStats(ctx context.Context) (*ClusterStats, error) {
+	local := h.Stats()
+	if h.redis == nil {
+		return &ClusterStats{Instances: 1, Users: local.Users, Connections: local.Connections, Local: local}, nil
+	}
+
+	reports, err := h.redis.HGetAll(ctx, h.statsKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &ClusterStats{Local: local}
+	var stale []string
+	for instanceID, data := range reports {
+		var report instanceStats
+		if err := json.Unmarshal([]byte(data), &report); err != nil || time.Since(report.ReportedAt) > statsTTL {
+			stale = append(stale, instanceID)
+			continue
+		}
+		stats.Instances++
+		stats.Users += report.Users
+		stats.Connections += report.Connections
+	}
+	if len(stale) > 0 {
+		h.redis.HDel(ctx, h.statsKey(), stale...)
+	}
+	return stats, nil
+}
+
+func (h *Hub) reportStats(ctx context.Context) {
+	ticker := time.NewTicker(statsInterval)
+	defer ticker.Stop()
+
+	for {
+		h.publishStats(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Hub) publishStats(ctx context.Context) {
+	data, err := json.Marshal(instanceStats{HubStats: h.Stats(), ReportedAt: time.Now()})
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+	if err := h.redis.HSet(ctx, h.statsKey(), h.instanceID, data).Err(); err != nil {
+		slog.ErrorContext(ctx, "Failed to report realtime connection counts", "error", err)
+	}
+}
+
+// Close disconnects every client, telling them the server is going away, and
+// stops receiving messages from other instances. The HTTP server's shutdown
+// does not close hijacked connections.
+func (h *Hub) Close() {
+	if !h.closing.CompareAndSwap(false, true) {
+		return
+	}
+
+	h.mu.RLock()
+	var clients []*Client
+	for _, set := range h.clients {
+		for c := range set {
+			clients = append(clients, c)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, c := range clients {
+		c.Close(websocket.CloseGoingAway, "server shutting down")
+	}
+
+	if h.redis != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+		defer cancel()
+		h.redis.HDel(ctx, h.statsKey(), h.instanceID)
+		h.pubsub.Close()
+	}
+}
@@ -0,0 +1,121 @@
+package realtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+)
+
+// newRedisHubs returns two hubs sharing one Redis, as two API instances would.
+func newRedisHubs(t *testing.T) (*miniredis.Miniredis, *Hub, *Hub) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	var hubs []*Hub
+	for i := 0; i < 2; i++ {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { client.Close() })
+		hub := NewRedisHub(client, "test:")
+		hub.Start(ctx)
+		hubs = append(hubs, hub)
+	}
+	return mr, hubs[0], hubs[1]
+}
+
+// waitSubscribers waits until n instances have subscribed for the user.
+func waitSubscribers(t *testing.T, mr *miniredis.Miniredis, hub *Hub, userID uuid.UUID, n int) {
+	t.Helper()
+	channel := hub.userChannel(userID)
+	for i := 0; mr.PubSubNumSub(channel)[channel] != n && i < 200; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := mr.PubSubNumSub(channel)[channel]; got != n {
+		t.Fatalf("%d instances subscribed, want %d", got, n)
+	}
+}
+
+func TestRedisHubRoutesAcrossInstances(t *testing.T) {
+	mr, hubA, hubB := newRedisHubs(t)
+	srvA := testServer(t, hubA, nil, Config{})
+	srvB := testServer(t, hubB, nil, Config{})
+
+	user := uuid.New()
+	phone := dial(t, srvB, hubB, user)
+	laptop := dial(t, srvB, hubB, user)
+	waitSubscribers(t, mr, hubA, user, 1)
+
+	n, err := hubA.SendToUser(user, map[string]interface{}{"type": "ping", "data": map[string]int{"n": 1}})
+	if err != nil || n != 1 {
+		t.Fatalf("SendToUser() = %d, %v, want one instance", n, err)
+	}
+	for _, conn := range []*websocket.Conn{phone, laptop} {
+		var data map[string]int
+		if typ := readMessage(t, conn, &data); typ != "ping" || data["n"] != 1 {
+			t.Errorf("got %s %v, want the ping", typ, data)
+		}
+	}
+
+	// A third device on the other instance subscribes that one too
+	dial(t, srvA, hubA, user)
+	waitSubscribers(t, mr, hubA, user, 2)
+	if n, _ := hubA.SendToUser(user, map[string]string{"type": "ping"}); n != 2 {
+		t.Errorf("SendToUser() reached %d instances, want 2", n)
+	}
+
+	if n, _ := hubA.SendToUser(uuid.New(), map[string]string{"type": "ping"}); n != 0 {
+		t.Errorf("SendToUser() to an offline user reached %d instances", n)
+	}
+}
+
+func TestRedisHubClusterStats(t *testing.T) {
+	_, hubA, hubB := newRedisHubs(t)
+	srvB := testServer(t, hubB, nil, Config{})
+	user := uuid.New()
+	dial(t, srvB, hubB, user)
+	dial(t, srvB, hubB, user)
+	hubB.publishStats(context.Background())
+	hubA.publishStats(context.Background())
+
+	stats, err := hubA.ClusterStats(context.Background())
+	if err != nil {
+		t.Fatalf("ClusterStats() error = %v", err)
+	}
+	if stats.Instances != 2 || stats.Users != 1 || stats.Connections != 2 || stats.Local.Connections != 0 {
+		t.Errorf("stats = %+v, want 2 instances and one user on two connections", stats)
+	}
+}
+
+func TestRedisHubDisconnectUser(t *testing.T) {
+	mr, hubA, hubB := newRedisHubs(t)
+	srvA := testServer(t, hubA, nil, Config{})
+	srvB := testServer(t, hubB, nil, Config{})
+
+	user, other := uuid.New(), uuid.New()
+	phone := dial(t, srvA, hubA, user)
+	laptop := dial(t, srvB, hubB, user)
+	bystander := dial(t, srvB, hubB, other)
+	waitSubscribers(t, mr, hubA, user, 2)
+
+	hubA.DisconnectUser(user)
+
+	for _, conn := range []*websocket.Conn{phone, laptop} {
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, CloseSessionRevoked) {
+			t.Errorf("read error = %v, want close %d", err, CloseSessionRevoked)
+		}
+	}
+	if n, _ := hubA.SendToUser(other, map[string]interface{}{"type": "ping", "data": map[string]int{"n": 1}}); n != 1 {
+		t.Fatalf("SendToUser() to the other user reached %d instances, want 1", n)
+	}
+	var data map[string]int
+	if typ := readMessage(t, bystander, &data); typ != "ping" {
+		t.Errorf("other user got %s, want the ping", typ)
+	}
+}
@@ -0,0 +1,187 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/google/uuid"
+)
+
+var errNotAllowed = errors.New("you can only signal the other participant of a mutual match")
+
+// grantTTL is how long a permission to signal a peer is cached, so ICE
+// candidate bursts don't each hit the database. A block or ended match takes
+// effect for signaling within this time.
+const grantTTL = 30 * time.Second
+
+type grantKey struct {
+	userID  uuid.UUID
+	matchID uuid.UUID
+}
+
+type grant struct {
+	peerID  uuid.UUID
+	expires time.Time
+}
+
+// Signaling relays WebRTC offers, answers and ICE candidates, and chat typing
+// indicators, between the two participants of a mutual match.
+type Signaling struct {
+	hub        *Hub
+	matchRepo  postgres.MatchRepository
+	safetyRepo postgres.SafetyRepository
+
+	mu     sync.Mutex
+	grants map[grantKey]grant
+}
+
+func NewSignaling(hub *Hub, matchRepo postgres.MatchRepository, safetyRepo postgres.SafetyRepository) *Signaling {
+	return &Signaling{
+		hub:        hub,
+		matchRepo:  matchRepo,
+		safetyRepo: safetyRepo,
+		grants:     map[grantKey]grant{},
+	}
+}
+
+// HandleMessage is the MessageHandler for client connections.
+func (s *Signaling) HandleMessage(ctx context.Context, c *Client, data []byte) {
+	var msg models.ClientMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		sendError(c, models.RealtimeErrInvalidMessage, "message is not valid JSON")
+		return
+	}
+
+	switch msg.Type {
+	case models.RealtimeWebRTCSignal:
+		s.relaySignal(ctx, c, &msg)
+	case models.RealtimeTyping:
+		s.relayTyping(ctx, c, &msg)
+	default:
+		sendError(c, models.RealtimeErrUnsupported, "unsupported message type")
+	}
+}
+
+func (s *Signaling) relaySignal(ctx context.Context, c *Client, msg *models.ClientMessage) {
+	if msg.MatchID == nil || msg.Signal == nil {
+		sendError(c, models.RealtimeErrInvalidMessage, "match_id and signal are required")
+		return
+	}
+	switch msg.Signal.Type {
+	case models.SignalOffer, models.SignalAnswer, models.SignalICECandidate:
+	default:
+		sendError(c, models.RealtimeErrInvalidMessage, "signal type must be offer, answer or ice-candidate")
+		return
+	}
+
+	peerID, err := s.peer(ctx, c.UserID, *msg.MatchID)
+	if errors.Is(err, errNotAllowed) {
+		sendError(c, models.RealtimeErrForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to authorize signal", "user_id", c.UserID, "match_id", *msg.MatchID, "error", err)
+		sendError(c, models.RealtimeErrInternal, "failed to relay signal")
+		return
+	}
+
+	delivered, err := s.hub.SendToUser(peerID, models.ServerMessage{
+		Type: models.RealtimeWebRTCSignal,
+		Data: &models.SignalRelay{MatchID: *msg.MatchID, From: c.UserID, Signal: msg.Signal},
+	})
+	if err != nil {
+		sendError(c, models.RealtimeErrInvalidMessage, "signal data is not valid JSON")
+		return
+	}
+	if delivered == 0 {
+		sendError(c, models.RealtimeErrPeerOffline, "the other participant is not connected")
+	}
+}
+
+// relayTyping forwards typing indicators on a best-effort basis: an offline
+// peer is not reported, since the indicator would be stale by the time they
+// connect.
+func (s *Signaling) relayTyping(ctx context.Context, c *Client, msg *models.ClientMessage) {
+	if msg.MatchID == nil {
+		sendError(c, models.RealtimeErrInvalidMessage, "match_id is required")
+		return
+	}
+
+	peerID, err := s.peer(ctx, c.UserID, *msg.MatchID)
+	if errors.Is(err, errNotAllowed) {
+		sendError(c, models.RealtimeErrForbidden, err.Error())
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to authorize typing indicator", "user_id", c.UserID, "match_id", *msg.MatchID, "error", err)
+		return
+	}
+
+	s.hub.SendToUser(peerID, models.ServerMessage{
+		Type: models.RealtimeTyping,
+		Data: &models.TypingIndicator{MatchID: *msg.MatchID, From: c.UserID, Typing: msg.Typing},
+	})
+}
+
+// peer returns who userID may signal about the match, checking that the
+// match is mutual and still accepted and that neither user blocked the other.
+func (s *Signaling) peer(ctx context.Context, userID, matchID uuid.UUID) (uuid.UUID, error) {
+	key := grantKey{userID: userID, matchID: matchID}
+	now := time.Now()
+
+	s.mu.Lock()
+	g, ok := s.grants[key]
+	s.mu.Unlock()
+	if ok && now.Before(g.expires) {
+		return g.peerID, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	match, err := s.matchRepo.GetMatch(ctx, matchID)
+	if errors.Is(err, postgres.ErrMatchNotFound) {
+		return uuid.Nil, errNotAllowed
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if match.UserID != userID && match.MatchedUserID != userID {
+		return uuid.Nil, errNotAllowed
+	}
+	if !match.MutualMatch || match.Status != models.MatchStatusAccepted {
+		return uuid.Nil, errNotAllowed
+	}
+
+	peerID := match.OtherUserID(userID)
+	blocked, err := s.safetyRepo.IsBlocked(ctx, userID, peerID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if blocked {
+		return uuid.Nil, errNotAllowed
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, g := range s.grants {
+		if now.After(g.expires) {
+			delete(s.grants, k)
+		}
+	}
+	s.grants[key] = grant{peerID: peerID, expires: now.Add(grantTTL)}
+	return peerID, nil
+}
+
+func sendError(c *Client, code, message string) {
+	c.SendJSON(models.ServerMessage{
+		Type: models.RealtimeError,
+		Data: &models.RealtimeErrorData{Code: code, Message: message},
+	})
+}
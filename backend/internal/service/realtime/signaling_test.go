@@ -0,0 +1,271 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+type fakeMatchRepo struct {
+	postgres.MatchRepository
+	matches map[uuid.UUID]*models.Match
+}
+
+func (r *fakeMatchRepo) GetMatch(ctx context.Context, matchID uuid.UUID) (*models.Match, error) {
+	m, ok := r.matches[matchID]
+	if !ok {
+		return nil, postgres.ErrMatchNotFound
+	}
+	return m, nil
+}
+
+type fakeSafetyRepo struct {
+	postgres.SafetyRepository
+	blocked bool
+}
+
+func (r *fakeSafetyRepo) IsBlocked(ctx context.Context, a, b uuid.UUID) (bool, error) {
+	return r.blocked, nil
+}
+
+// testServer serves WebSockets for the user named in the user query
+// parameter, skipping token authentication.
+func testServer(t *testing.T, hub *Hub, handle MessageHandler, cfg Config) *httptest.Server {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := uuid.MustParse(r.URL.Query().Get("user"))
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		NewClient(hub, conn, userID, time.Time{}, cfg).Run(r.Context(), handle)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func dial(t *testing.T, srv *httptest.Server, hub *Hub, userID uuid.UUID) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "?user=" + userID.String()
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	// Wait for the server side to register the connection
+	for i := 0; hub.Connections(userID) == 0 && i < 100; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	return conn
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn, data interface{}) string {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("ReadJSON() error = %v", err)
+	}
+	if err := json.Unmarshal(msg.Data, data); err != nil {
+		t.Fatalf("decoding %s data: %v", msg.Type, err)
+	}
+	return msg.Type
+}
+
+func signal(matchID uuid.UUID, signalType string) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     models.RealtimeWebRTCSignal,
+		"match_id": matchID,
+		"signal":   map[string]interface{}{"type": signalType, "data": map[string]string{"sdp": "v=0"}},
+	}
+}
+
+func TestSignalingRelay(t *testing.T) {
+	alice, bob, eve := uuid.New(), uuid.New(), uuid.New()
+	mutual := &models.Match{ID: uuid.New(), UserID: alice, MatchedUserID: bob, Status: models.MatchStatusAccepted, MutualMatch: true}
+	pending := &models.Match{ID: uuid.New(), UserID: alice, MatchedUserID: eve, Status: models.MatchStatusPending}
+
+	safety := &fakeSafetyRepo{}
+	hub := NewHub()
+	signaling := NewSignaling(hub, &fakeMatchRepo{matches: map[uuid.UUID]*models.Match{
+		mutual.ID: mutual, pending.ID: pending,
+	}}, safety)
+	srv := testServer(t, hub, signaling.HandleMessage, Config{})
+
+	aliceConn := dial(t, srv, hub, alice)
+	bobPhone := dial(t, srv, hub, bob)
+	bobLaptop := dial(t, srv, hub, bob)
+	eveConn := dial(t, srv, hub, eve)
+
+	if err := aliceConn.WriteJSON(signal(mutual.ID, models.SignalOffer)); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	for _, conn := range []*websocket.Conn{bobPhone, bobLaptop} {
+		var relay models.SignalRelay
+		if typ := readMessage(t, conn, &relay); typ != models.RealtimeWebRTCSignal {
+			t.Fatalf("message type = %s, want webrtc_signal", typ)
+		}
+		if relay.From != alice || relay.MatchID != mutual.ID || relay.Signal.Type != models.SignalOffer {
+			t.Errorf("relay = %+v, want alice's offer for the match", relay)
+		}
+		if string(relay.Signal.Data) != `{"sdp":"v=0"}` {
+			t.Errorf("signal data = %s, want it relayed untouched", relay.Signal.Data)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		conn     *websocket.Conn
+		msg      interface{}
+		wantCode string
+	}{
+		{"not a participant", eveConn, signal(mutual.ID, models.SignalOffer), models.RealtimeErrForbidden},
+		{"match not mutual", eveConn, signal(pending.ID, models.SignalOffer), models.RealtimeErrForbidden},
+		{"unknown match", aliceConn, signal(uuid.New(), models.SignalOffer), models.RealtimeErrForbidden},
+		{"bad signal type", aliceConn, signal(mutual.ID, "bye"), models.RealtimeErrInvalidMessage},
+		{"unknown message type", aliceConn, map[string]string{"type": "dance"}, models.RealtimeErrUnsupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.conn.WriteJSON(tt.msg); err != nil {
+				t.Fatalf("WriteJSON() error = %v", err)
+			}
+			var data models.RealtimeErrorData
+			if typ := readMessage(t, tt.conn, &data); typ != models.RealtimeError || data.Code != tt.wantCode {
+				t.Errorf("got %s %q, want error %q", typ, data.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestTypingRelay(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	mutual := &models.Match{ID: uuid.New(), UserID: alice, MatchedUserID: bob, Status: models.MatchStatusAccepted, MutualMatch: true}
+
+	hub := NewHub()
+	signaling := NewSignaling(hub, &fakeMatchRepo{matches: map[uuid.UUID]*models.Match{mutual.ID: mutual}}, &fakeSafetyRepo{})
+	srv := testServer(t, hub, signaling.HandleMessage, Config{})
+
+	aliceConn := dial(t, srv, hub, alice)
+	bobConn := dial(t, srv, hub, bob)
+
+	err := aliceConn.WriteJSON(map[string]interface{}{"type": models.RealtimeTyping, "match_id": mutual.ID, "typing": true})
+	if err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	var typing models.TypingIndicator
+	if typ := readMessage(t, bobConn, &typing); typ != models.RealtimeTyping {
+		t.Fatalf("message type = %s, want typing", typ)
+	}
+	if typing.From != alice || typing.MatchID != mutual.ID || !typing.Typing {
+		t.Errorf("typing = %+v, want alice typing in the match", typing)
+	}
+}
+
+func TestSignalingBlockedPair(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	mutual := &models.Match{ID: uuid.New(), UserID: alice, MatchedUserID: bob, Status: models.MatchStatusAccepted, MutualMatch: true}
+
+	hub := NewHub()
+	signaling := NewSignaling(hub, &fakeMatchRepo{matches: map[uuid.UUID]*models.Match{mutual.ID: mutual}}, &fakeSafetyRepo{blocked: true})
+	srv := testServer(t, hub, signaling.HandleMessage, Config{})
+	aliceConn := dial(t, srv, hub, alice)
+	dial(t, srv, hub, bob)
+
+	aliceConn.WriteJSON(signal(mutual.ID, models.SignalOffer))
+	var data models.RealtimeErrorData
+	if typ := readMessage(t, aliceConn, &data); typ != models.RealtimeError || data.Code != models.RealtimeErrForbidden {
+		t.Errorf("got %s %q, want forbidden", typ, data.Code)
+	}
+}
+
+func TestClientMessageSizeLimit(t *testing.T) {
+	hub := NewHub()
+	srv := testServer(t, hub, func(context.Context, *Client, []byte) {}, Config{MaxMessageSize: 128})
+	user := uuid.New()
+	conn := dial(t, srv, hub, user)
+
+	conn.WriteMessage(websocket.TextMessage, []byte(strings.Repeat("x", 256)))
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+		t.Fatalf("read error = %v, want close 1009", err)
+	}
+
+	for i := 0; hub.Connections(user) > 0 && i < 100; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := hub.Connections(user); n != 0 {
+		t.Errorf("hub still has %d connections", n)
+	}
+}
+
+func TestClientSlowConsumerDisconnected(t *testing.T) {
+	hub := NewHub()
+	serverSide := make(chan *Client, 1)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		// No pumps run, so nothing drains the send queue
+		serverSide <- NewClient(hub, conn, uuid.New(), time.Time{}, Config{SendBuffer: 2})
+	}))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	client := <-serverSide
+
+	for i := 0; i < 2; i++ {
+		if !client.Send([]byte("{}")) {
+			t.Fatalf("Send() %d rejected within the buffer", i)
+		}
+	}
+	if client.Send([]byte("{}")) {
+		t.Fatal("Send() accepted a message beyond the buffer")
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseTryAgainLater) {
+		t.Errorf("read error = %v, want close 1013", err)
+	}
+}
+
+func TestClientClosedWhenTokenExpires(t *testing.T) {
+	hub := NewHub()
+	user := uuid.New()
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		NewClient(hub, conn, user, time.Now().Add(100*time.Millisecond), Config{}).Run(r.Context(), nil)
+	}))
+	defer srv.Close()
+
+	conn := dial(t, srv, hub, user)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, CloseTokenExpired) {
+		t.Errorf("read error = %v, want close %d", err, CloseTokenExpired)
+	}
+}
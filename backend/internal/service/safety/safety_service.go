@@ -0,0 +1,194 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/repository/redis"
+	"github.com/alexcolls/findme/internal/service/events"
+	"github.com/alexcolls/findme/internal/service/moderation"
+	"github.com/google/uuid"
+)
+
+var ErrSelfTarget = errors.New("you cannot block or report yourself")
+
+// Config sets when reports suspend an account automatically. Thresholds count
+// distinct reporters within the window; zero disables a threshold.
+type Config struct {
+	AutoSuspendThreshold       int
+	AutoSuspendSevereThreshold int
+	AutoSuspendWindow          time.Duration
+}
+
+// SafetyService lets users block and report each other.
+type SafetyService interface {
+	Block(ctx context.Context, userID, targetID uuid.UUID) error
+	Unblock(ctx context.Context, userID, targetID uuid.UUID) error
+	ListBlocks(ctx context.Context, userID uuid.UUID) ([]*models.BlockedUser, error)
+	Report(ctx context.Context, userID, targetID uuid.UUID, req *models.ReportRequest) (*models.ReportResponse, error)
+}
+
+type safetyService struct {
+	safetyRepo postgres.SafetyRepository
+	userRepo   postgres.UserRepository
+	adminRepo  postgres.AdminRepository
+	sessions   redis.SessionRepository
+	moderation moderation.ModerationService
+	events     events.Publisher
+	cfg        Config
+}
+
+func NewSafetyService(
+	safetyRepo postgres.SafetyRepository,
+	userRepo postgres.UserRepository,
+	adminRepo postgres.AdminRepository,
+	sessions redis.SessionRepository,
+	moderationService moderation.ModerationService,
+	publisher events.Publisher,
+	cfg Config,
+) SafetyService {
+	return &safetyService{
+		safetyRepo: safetyRepo,
+		userRepo:   userRepo,
+		adminRepo:  adminRepo,
+		sessions:   sessions,
+		moderation: moderationService,
+		events:     publisher,
+		cfg:        cfg,
+	}
+}
+
+func (s *safetyService) Block(ctx context.Context, userID, targetID uuid.UUID) error {
+	if userID == targetID {
+		return ErrSelfTarget
+	}
+	if err := s.safetyRepo.Block(ctx, userID, targetID); err != nil {
+		return err
+	}
+
+	s.events.Publish(ctx, events.Event{
+		Type:    events.UserBlocked,
+		UserIDs: []uuid.UUID{userID, targetID},
+		Data:    &models.Block{BlockerID: userID, BlockedID: targetID, CreatedAt: time.Now()},
+	})
+	return nil
+}
+
+func (s *safetyService) Unblock(ctx context.Context, userID, targetID uuid.UUID) error {
+	return s.safetyRepo.Unblock(ctx, userID, targetID)
+}
+
+func (s *safetyService) ListBlocks(ctx context.Context, userID uuid.UUID) ([]*models.BlockedUser, error) {
+	return s.safetyRepo.ListBlocks(ctx, userID)
+}
+
+// Report files the report, queues it for moderation and suspends the reported
+// user if enough people have reported them recently.
+func (s *safetyService) Report(ctx context.Context, userID, targetID uuid.UUID, req *models.ReportRequest) (*models.ReportResponse, error) {
+	if userID == targetID {
+		return nil, ErrSelfTarget
+	}
+
+	report := &models.Report{
+		ReporterID: userID,
+		ReportedID: targetID,
+		Category:   req.Category,
+		Evidence:   req.Evidence,
+		MatchID:    req.MatchID,
+		CallID:     req.CallID,
+	}
+	if req.Description != "" {
+		report.Description = &req.Description
+	}
+	if err := s.safetyRepo.CreateReport(ctx, report); err != nil {
+		return nil, err
+	}
+
+	_, err := s.moderation.Enqueue(ctx, &models.EnqueueModerationRequest{
+		ItemType: models.ModerationItemReport,
+		ItemID:   report.ID,
+		UserID:   &targetID,
+		Priority: models.ReportPriorities[req.Category],
+		Source:   "report",
+		Reason:   req.Category,
+		Metadata: map[string]interface{}{
+			"reporter_id": userID.String(),
+			"category":    req.Category,
+			"evidence":    len(report.Evidence),
+		},
+	})
+	if err != nil {
+		// Without a queue item no moderator would see the report, and the
+		// open report would make every retry fail as a duplicate
+		if delErr := s.safetyRepo.DeleteReport(context.WithoutCancel(ctx), report.ID); delErr != nil {
+			slog.ErrorContext(ctx, "Failed to remove unqueued report", "report_id", report.ID, "error", delErr)
+		}
+		return nil, fmt.Errorf("failed to queue report for moderation: %w", err)
+	}
+
+	resp := &models.ReportResponse{Report: report}
+	if req.Block {
+		if err := s.Block(ctx, userID, targetID); err != nil {
+			return nil, err
+		}
+		resp.Blocked = true
+	}
+
+	if err := s.enforceThresholds(ctx, targetID); err != nil {
+		// The report is filed and queued; moderators can still act on it
+		slog.ErrorContext(ctx, "Failed to check report thresholds", "user_id", targetID, "error", err)
+	}
+	return resp, nil
+}
+
+// enforceThresholds suspends the user when the reports against them within
+// the window reach either threshold.
+func (s *safetyService) enforceThresholds(ctx context.Context, userID uuid.UUID) error {
+	since := time.Now().Add(-s.cfg.AutoSuspendWindow)
+
+	reason := ""
+	if s.cfg.AutoSuspendSevereThreshold > 0 {
+		n, err := s.safetyRepo.CountReporters(ctx, userID, since, models.SevereReportCategories)
+		if err != nil {
+			return err
+		}
+		if n >= s.cfg.AutoSuspendSevereThreshold {
+			reason = fmt.Sprintf("automatic suspension: %d severe reports", n)
+		}
+	}
+	if reason == "" && s.cfg.AutoSuspendThreshold > 0 {
+		n, err := s.safetyRepo.CountReporters(ctx, userID, since, nil)
+		if err != nil {
+			return err
+		}
+		if n >= s.cfg.AutoSuspendThreshold {
+			reason = fmt.Sprintf("automatic suspension: %d reports", n)
+		}
+	}
+	if reason == "" {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.Active {
+		return nil
+	}
+
+	if err := s.adminRepo.SetActive(ctx, nil, userID, false, reason); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "Suspended user", "user_id", userID, "reason", reason)
+	if err := s.sessions.InvalidateTokenVersion(ctx, userID); err != nil {
+		return err
+	}
+	s.events.Publish(ctx, events.Event{Type: events.SessionsRevoked, UserIDs: []uuid.UUID{userID}})
+	return nil
+}
@@ -0,0 +1,247 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexcolls/findme/internal/domain/models"
+	"github.com/alexcolls/findme/internal/repository/postgres"
+	"github.com/alexcolls/findme/internal/repository/redis"
+	"github.com/alexcolls/findme/internal/service/events"
+	"github.com/alexcolls/findme/internal/service/moderation"
+	"github.com/google/uuid"
+)
+
+// fakeSafetyRepo keeps reports in memory and counts distinct reporters the
+// way CountReporters does.
+type fakeSafetyRepo struct {
+	postgres.SafetyRepository
+	reports []*models.Report
+	blocks  []models.Block
+	// oneOpen rejects a second open report per pair, like idx_reports_one_open
+	oneOpen bool
+}
+
+func (r *fakeSafetyRepo) Block(ctx context.Context, blockerID, blockedID uuid.UUID) error {
+	r.blocks = append(r.blocks, models.Block{BlockerID: blockerID, BlockedID: blockedID})
+	return nil
+}
+
+func (r *fakeSafetyRepo) CreateReport(ctx context.Context, report *models.Report) error {
+	for _, existing := range r.reports {
+		if r.oneOpen && existing.ReporterID == report.ReporterID && existing.ReportedID == report.ReportedID &&
+			existing.Status == models.ReportStatusOpen {
+			return postgres.ErrAlreadyReported
+		}
+	}
+	report.ID = uuid.New()
+	report.Status = models.ReportStatusOpen
+	report.CreatedAt = time.Now()
+	r.reports = append(r.reports, report)
+	return nil
+}
+
+func (r *fakeSafetyRepo) DeleteReport(ctx context.Context, id uuid.UUID) error {
+	for i, report := range r.reports {
+		if report.ID == id {
+			r.reports = append(r.reports[:i], r.reports[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (r *fakeSafetyRepo) CountReporters(ctx context.Context, reportedID uuid.UUID, since time.Time, categories []string) (int, error) {
+	wanted := map[string]bool{}
+	for _, c := range categories {
+		wanted[c] = true
+	}
+	reporters := map[uuid.UUID]bool{}
+	for _, report := range r.reports {
+		if report.ReportedID != reportedID || report.CreatedAt.Before(since) {
+			continue
+		}
+		if len(wanted) == 0 || wanted[report.Category] {
+			reporters[report.ReporterID] = true
+		}
+	}
+	return len(reporters), nil
+}
+
+type fakeUserRepo struct {
+	postgres.UserRepository
+	active map[uuid.UUID]bool
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	return &models.User{ID: id, Active: r.active[id]}, nil
+}
+
+type fakeAdminRepo struct {
+	postgres.AdminRepository
+	users     *fakeUserRepo
+	suspended []uuid.UUID
+}
+
+func (r *fakeAdminRepo) SetActive(ctx context.Context, actorID *uuid.UUID, userID uuid.UUID, active bool, reason string) error {
+	r.users.active[userID] = active
+	if !active {
+		r.suspended = append(r.suspended, userID)
+	}
+	return nil
+}
+
+type fakeSessions struct {
+	redis.SessionRepository
+	invalidated []uuid.UUID
+}
+
+func (s *fakeSessions) InvalidateTokenVersion(ctx context.Context, userID uuid.UUID) error {
+	s.invalidated = append(s.invalidated, userID)
+	return nil
+}
+
+type fakeModeration struct {
+	moderation.ModerationService
+	queued []*models.EnqueueModerationRequest
+	err    error
+}
+
+func (m *fakeModeration) Enqueue(ctx context.Context, req *models.EnqueueModerationRequest) (*models.ModerationItem, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.queued = append(m.queued, req)
+	return &models.ModerationItem{ID: uuid.New()}, nil
+}
+
+type recordingPublisher struct {
+	events []events.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event events.Event) {
+	p.events = append(p.events, event)
+}
+
+type testService struct {
+	SafetyService
+	repo       *fakeSafetyRepo
+	admin      *fakeAdminRepo
+	sessions   *fakeSessions
+	moderation *fakeModeration
+	publisher  *recordingPublisher
+}
+
+func newTestService(target uuid.UUID) *testService {
+	users := &fakeUserRepo{active: map[uuid.UUID]bool{target: true}}
+	ts := &testService{
+		repo:       &fakeSafetyRepo{},
+		admin:      &fakeAdminRepo{users: users},
+		sessions:   &fakeSessions{},
+		moderation: &fakeModeration{},
+		publisher:  &recordingPublisher{},
+	}
+	ts.SafetyService = NewSafetyService(ts.repo, users, ts.admin, ts.sessions, ts.moderation, ts.publisher, Config{
+		AutoSuspendThreshold:       3,
+		AutoSuspendSevereThreshold: 2,
+		AutoSuspendWindow:          72 * time.Hour,
+	})
+	return ts
+}
+
+func TestReportAutoSuspend(t *testing.T) {
+	tests := []struct {
+		name       string
+		categories []string
+		suspended  bool
+	}{
+		{"below threshold", []string{"spam", "harassment"}, false},
+		{"threshold reached", []string{"spam", "harassment", "other"}, true},
+		{"severe threshold reached", []string{"underage", "safety_concern"}, true},
+		{"one severe report", []string{"underage", "spam"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := uuid.New()
+			ts := newTestService(target)
+
+			for _, category := range tt.categories {
+				_, err := ts.Report(context.Background(), uuid.New(), target, &models.ReportRequest{Category: category})
+				if err != nil {
+					t.Fatalf("Report() error = %v", err)
+				}
+			}
+
+			if got := len(ts.admin.suspended) > 0; got != tt.suspended {
+				t.Errorf("suspended = %v, want %v", got, tt.suspended)
+			}
+			if tt.suspended && (len(ts.admin.suspended) != 1 || len(ts.sessions.invalidated) != 1) {
+				t.Errorf("suspended %d times and revoked sessions %d times, want once each",
+					len(ts.admin.suspended), len(ts.sessions.invalidated))
+			}
+			if len(ts.moderation.queued) != len(tt.categories) {
+				t.Errorf("queued %d reports for moderation, want %d", len(ts.moderation.queued), len(tt.categories))
+			}
+		})
+	}
+}
+
+func TestReportSameReporterCountsOnce(t *testing.T) {
+	target, reporter := uuid.New(), uuid.New()
+	ts := newTestService(target)
+
+	for _, category := range []string{"spam", "harassment", "scam"} {
+		if _, err := ts.Report(context.Background(), reporter, target, &models.ReportRequest{Category: category}); err != nil {
+			t.Fatalf("Report() error = %v", err)
+		}
+	}
+	if len(ts.admin.suspended) != 0 {
+		t.Error("one reporter suspended the account on their own")
+	}
+}
+
+func TestReportAndBlock(t *testing.T) {
+	target, reporter := uuid.New(), uuid.New()
+	ts := newTestService(target)
+
+	resp, err := ts.Report(context.Background(), reporter, target, &models.ReportRequest{Category: "harassment", Block: true})
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if !resp.Blocked || len(ts.repo.blocks) != 1 {
+		t.Errorf("blocked = %v with %d blocks, want the reported user blocked", resp.Blocked, len(ts.repo.blocks))
+	}
+	if len(ts.publisher.events) != 1 || ts.publisher.events[0].Type != events.UserBlocked {
+		t.Errorf("events = %v, want one user.blocked", ts.publisher.events)
+	}
+
+	if _, err := ts.Report(context.Background(), reporter, reporter, &models.ReportRequest{Category: "spam"}); err != ErrSelfTarget {
+		t.Errorf("self report error = %v, want ErrSelfTarget", err)
+	}
+}
+
+func TestReportRetryAfterEnqueueFailure(t *testing.T) {
+	target, reporter := uuid.New(), uuid.New()
+	ts := newTestService(target)
+	ts.repo.oneOpen = true
+	req := &models.ReportRequest{Category: "harassment"}
+
+	ts.moderation.err = errors.New("connection refused")
+	if _, err := ts.Report(context.Background(), reporter, target, req); err == nil {
+		t.Fatal("Report() succeeded while the moderation queue was down")
+	}
+	if len(ts.repo.reports) != 0 {
+		t.Errorf("%d reports left without a queue item, want 0", len(ts.repo.reports))
+	}
+
+	ts.moderation.err = nil
+	if _, err := ts.Report(context.Background(), reporter, target, req); err != nil {
+		t.Fatalf("retry error = %v, want the report filed", err)
+	}
+	if len(ts.repo.reports) != 1 || len(ts.moderation.queued) != 1 {
+		t.Errorf("%d reports and %d queue items after retry, want 1 each", len(ts.repo.reports), len(ts.moderation.queued))
+	}
+}
@@ -0,0 +1,26 @@
+// Package migrations embeds the FindMe schema so the API and cmd/migrate
+// apply the same changes without the files on disk.
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"io/fs"
+
+	qdrantrepo "github.com/alexcolls/findme/internal/repository/qdrant"
+	"github.com/alexcolls/findme/migrations/qdrant"
+	"github.com/alexcolls/findme/pkg/migrate"
+)
+
+//go:embed postgres/*.sql
+var postgresFiles embed.FS
+
+// New returns a migrator for the Postgres schema followed by the Qdrant
+// collections.
+func New(db *sql.DB, profiles qdrantrepo.ProfileRepository, vectorSize int) (*migrate.Migrator, error) {
+	files, err := fs.Sub(postgresFiles, "postgres")
+	if err != nil {
+		return nil, err
+	}
+	return migrate.New(db, files, qdrant.Steps(profiles, vectorSize)...)
+}
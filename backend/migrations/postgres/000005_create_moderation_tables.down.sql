@@ -0,0 +1,17 @@
+-- Drop audit trail
+DROP INDEX IF EXISTS idx_moderation_audit_log_actor;
+DROP INDEX IF EXISTS idx_moderation_audit_log_item;
+DROP TABLE IF EXISTS moderation_audit_log;
+
+-- Drop trigger
+DROP TRIGGER IF EXISTS update_moderation_items_updated_at ON moderation_items;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_moderation_items_one_open;
+DROP INDEX IF EXISTS idx_moderation_items_created_at;
+DROP INDEX IF EXISTS idx_moderation_items_user_id;
+DROP INDEX IF EXISTS idx_moderation_items_claimed_by;
+DROP INDEX IF EXISTS idx_moderation_items_queue;
+
+-- Drop table
+DROP TABLE IF EXISTS moderation_items;
@@ -0,0 +1,60 @@
+-- Create moderation queue table
+CREATE TABLE moderation_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    item_type VARCHAR(50) NOT NULL CHECK (item_type IN ('video', 'photo', 'bio', 'report')),
+    item_id UUID NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'claimed', 'approved', 'rejected')),
+    priority INTEGER NOT NULL DEFAULT 50 CHECK (priority >= 0 AND priority <= 100),
+    source VARCHAR(50) NOT NULL DEFAULT 'auto' CHECK (source IN ('auto', 'report', 'manual')),
+    auto_score FLOAT CHECK (auto_score >= 0 AND auto_score <= 1),
+    reason TEXT,
+    metadata JSONB DEFAULT '{}',
+    claimed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    claimed_at TIMESTAMP WITH TIME ZONE,
+    claim_expires_at TIMESTAMP WITH TIME ZONE,
+    decision_reason_code VARCHAR(50),
+    decision_notes TEXT,
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decided_at TIMESTAMP WITH TIME ZONE,
+    sla_due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT claim_consistency CHECK (status != 'claimed' OR (claimed_by IS NOT NULL AND claim_expires_at IS NOT NULL))
+);
+
+-- Create indexes
+CREATE INDEX idx_moderation_items_queue ON moderation_items(priority DESC, sla_due_at ASC) WHERE status IN ('pending', 'claimed');
+CREATE INDEX idx_moderation_items_claimed_by ON moderation_items(claimed_by) WHERE status = 'claimed';
+CREATE INDEX idx_moderation_items_user_id ON moderation_items(user_id);
+CREATE INDEX idx_moderation_items_created_at ON moderation_items(created_at DESC);
+
+-- Only one open review per item
+CREATE UNIQUE INDEX idx_moderation_items_one_open ON moderation_items(item_type, item_id)
+    WHERE status IN ('pending', 'claimed');
+
+-- Create trigger for auto-update
+CREATE TRIGGER update_moderation_items_updated_at
+    BEFORE UPDATE ON moderation_items
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Create moderation audit trail
+CREATE TABLE moderation_audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    moderation_item_id UUID NOT NULL REFERENCES moderation_items(id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(50) NOT NULL CHECK (action IN ('enqueued', 'claimed', 'released', 'approved', 'rejected')),
+    reason_code VARCHAR(50),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_moderation_audit_log_item ON moderation_audit_log(moderation_item_id, created_at);
+CREATE INDEX idx_moderation_audit_log_actor ON moderation_audit_log(actor_id, created_at DESC);
+
+COMMENT ON TABLE moderation_items IS 'Manual review queue for content between auto-approve and auto-reject';
+COMMENT ON COLUMN moderation_items.item_id IS 'ID of the reviewed entity (video, user for bio/photo, report)';
+COMMENT ON COLUMN moderation_items.sla_due_at IS 'Deadline for a moderator decision, derived from priority';
+COMMENT ON COLUMN moderation_items.claim_expires_at IS 'Claims past this time can be taken by another moderator';
+COMMENT ON TABLE moderation_audit_log IS 'Append-only trail of moderation actions';
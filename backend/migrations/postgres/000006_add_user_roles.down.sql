@@ -0,0 +1,12 @@
+-- Drop admin audit trail
+DROP INDEX IF EXISTS idx_admin_audit_log_actor;
+DROP INDEX IF EXISTS idx_admin_audit_log_target;
+DROP TABLE IF EXISTS admin_audit_log;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_users_full_name_trgm;
+DROP INDEX IF EXISTS idx_users_role;
+
+-- Drop columns
+ALTER TABLE users DROP COLUMN IF EXISTS token_version;
+ALTER TABLE users DROP COLUMN IF EXISTS role;
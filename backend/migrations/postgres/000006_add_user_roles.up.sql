@@ -0,0 +1,27 @@
+-- Add role and token version to users
+ALTER TABLE users ADD COLUMN role VARCHAR(50) NOT NULL DEFAULT 'user'
+    CHECK (role IN ('user', 'moderator', 'admin', 'support'));
+ALTER TABLE users ADD COLUMN token_version INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_users_role ON users(role) WHERE role != 'user' AND deleted_at IS NULL;
+CREATE INDEX idx_users_full_name_trgm ON users USING gin(full_name gin_trgm_ops) WHERE deleted_at IS NULL;
+
+-- Create admin audit trail
+CREATE TABLE admin_audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    target_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action VARCHAR(50) NOT NULL CHECK (action IN ('role_changed', 'suspended', 'unsuspended', 'forced_logout', 'verification_changed')),
+    old_value TEXT,
+    new_value TEXT,
+    reason TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_admin_audit_log_target ON admin_audit_log(target_user_id, created_at DESC);
+CREATE INDEX idx_admin_audit_log_actor ON admin_audit_log(actor_id, created_at DESC);
+
+COMMENT ON COLUMN users.role IS 'Access role: user, moderator, admin or support';
+COMMENT ON COLUMN users.token_version IS 'Incremented to invalidate all issued tokens (forced logout)';
+COMMENT ON TABLE admin_audit_log IS 'Append-only trail of administrative actions on user accounts';
+COMMENT ON COLUMN admin_audit_log.actor_id IS 'NULL for system actions such as admin bootstrap';
@@ -0,0 +1,10 @@
+-- Drop matching run log
+DROP TABLE IF EXISTS matching_runs;
+
+-- Drop preferences
+DROP TRIGGER IF EXISTS update_user_preferences_updated_at ON user_preferences;
+DROP TABLE IF EXISTS user_preferences;
+
+-- Drop location columns
+ALTER TABLE users DROP COLUMN IF EXISTS longitude;
+ALTER TABLE users DROP COLUMN IF EXISTS latitude;
@@ -0,0 +1,40 @@
+-- Add location to users for distance filtering
+ALTER TABLE users ADD COLUMN latitude DOUBLE PRECISION CHECK (latitude >= -90 AND latitude <= 90);
+ALTER TABLE users ADD COLUMN longitude DOUBLE PRECISION CHECK (longitude >= -180 AND longitude <= 180);
+
+-- Create matching preferences table
+CREATE TABLE user_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    interested_in VARCHAR(50)[] NOT NULL DEFAULT ARRAY['male', 'female', 'other'],
+    min_age INTEGER NOT NULL DEFAULT 18 CHECK (min_age >= 18),
+    max_age INTEGER NOT NULL DEFAULT 99 CHECK (max_age <= 120),
+    max_distance_km INTEGER NOT NULL DEFAULT 0 CHECK (max_distance_km >= 0),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT valid_age_range CHECK (min_age <= max_age)
+);
+
+CREATE TRIGGER update_user_preferences_updated_at
+    BEFORE UPDATE ON user_preferences
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Create weekly matching run log
+CREATE TABLE matching_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    week_number INTEGER NOT NULL CHECK (week_number >= 1 AND week_number <= 53),
+    year INTEGER NOT NULL CHECK (year >= 2025),
+    status VARCHAR(50) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'failed')),
+    eligible_users INTEGER NOT NULL DEFAULT 0,
+    matches_created INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    finished_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT unique_matching_run_per_week UNIQUE(week_number, year)
+);
+
+COMMENT ON COLUMN users.latitude IS 'Last known latitude used for distance filtering';
+COMMENT ON COLUMN users.longitude IS 'Last known longitude used for distance filtering';
+COMMENT ON TABLE user_preferences IS 'Matching preferences; users without a row use column defaults';
+COMMENT ON COLUMN user_preferences.max_distance_km IS 'Maximum match distance in km, 0 for unlimited';
+COMMENT ON TABLE matching_runs IS 'One row per ISO week the matching job has processed';
@@ -0,0 +1,6 @@
+-- Drop outbox trigger
+DROP TRIGGER IF EXISTS enqueue_users_profile_embedding ON users;
+DROP FUNCTION IF EXISTS enqueue_profile_embedding();
+
+-- Drop outbox
+DROP TABLE IF EXISTS embedding_outbox;
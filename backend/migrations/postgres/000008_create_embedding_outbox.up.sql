@@ -0,0 +1,43 @@
+-- Create outbox of pending profile embedding changes
+CREATE TABLE embedding_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    user_id UUID NOT NULL,
+    operation VARCHAR(50) NOT NULL CHECK (operation IN ('upsert', 'delete')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    processed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Create indexes
+CREATE INDEX idx_embedding_outbox_pending ON embedding_outbox(next_attempt_at, id) WHERE processed_at IS NULL;
+CREATE INDEX idx_embedding_outbox_processed_at ON embedding_outbox(processed_at) WHERE processed_at IS NOT NULL;
+
+-- Record an outbox entry whenever an embedded profile field changes, in the
+-- same transaction as the change itself
+CREATE OR REPLACE FUNCTION enqueue_profile_embedding()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND
+       (OLD.full_name, OLD.bio, OLD.gender, OLD.date_of_birth, OLD.verified, OLD.active,
+        OLD.latitude, OLD.longitude, OLD.video_id, OLD.deleted_at)
+       IS NOT DISTINCT FROM
+       (NEW.full_name, NEW.bio, NEW.gender, NEW.date_of_birth, NEW.verified, NEW.active,
+        NEW.latitude, NEW.longitude, NEW.video_id, NEW.deleted_at) THEN
+        RETURN NEW;
+    END IF;
+
+    INSERT INTO embedding_outbox (user_id, operation)
+    VALUES (NEW.id, CASE WHEN NEW.deleted_at IS NULL THEN 'upsert' ELSE 'delete' END);
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER enqueue_users_profile_embedding
+    AFTER INSERT OR UPDATE ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION enqueue_profile_embedding();
+
+COMMENT ON TABLE embedding_outbox IS 'Profile changes waiting to be synced to the Qdrant profile_embeddings collection';
+COMMENT ON COLUMN embedding_outbox.next_attempt_at IS 'Entries are retried with backoff until processed';
@@ -0,0 +1,17 @@
+-- Restore original mutual match trigger function
+CREATE OR REPLACE FUNCTION update_mutual_match()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- If both users accepted, set mutual_match to true
+    IF NEW.user_action = 'accepted' AND NEW.matched_user_action = 'accepted' THEN
+        NEW.mutual_match := TRUE;
+        NEW.matched_at := NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+-- Drop columns
+ALTER TABLE matches DROP COLUMN IF EXISTS end_feedback;
+ALTER TABLE matches DROP COLUMN IF EXISTS end_reason;
+ALTER TABLE matches DROP COLUMN IF EXISTS ended_by;
@@ -0,0 +1,22 @@
+-- Record who ended a match and why
+ALTER TABLE matches ADD COLUMN ended_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE matches ADD COLUMN end_reason VARCHAR(50) CHECK (end_reason IN ('not_compatible', 'no_chemistry', 'met_someone', 'safety_concern', 'other'));
+ALTER TABLE matches ADD COLUMN end_feedback TEXT;
+
+-- Only stamp matched_at when the match first becomes mutual, not on every
+-- later update of a mutual match
+CREATE OR REPLACE FUNCTION update_mutual_match()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- If both users accepted, set mutual_match to true
+    IF NEW.user_action = 'accepted' AND NEW.matched_user_action = 'accepted'
+       AND NOT COALESCE(OLD.mutual_match, FALSE) THEN
+        NEW.mutual_match := TRUE;
+        NEW.matched_at := NOW();
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+COMMENT ON COLUMN matches.ended_by IS 'User who ended a mutual match';
+COMMENT ON COLUMN matches.end_reason IS 'Reason given when a mutual match was ended';
@@ -0,0 +1,6 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_matches_reminder_due;
+DROP INDEX IF EXISTS idx_matches_accepted_expires_at;
+
+-- Drop columns
+ALTER TABLE matches DROP COLUMN IF EXISTS reminder_sent_at;
@@ -0,0 +1,9 @@
+-- Track "last chance" reminders so each match is reminded at most once
+ALTER TABLE matches ADD COLUMN reminder_sent_at TIMESTAMP WITH TIME ZONE;
+
+-- Support the expiry sweeper, which also completes active mutual matches
+CREATE INDEX idx_matches_accepted_expires_at ON matches(expires_at) WHERE status = 'accepted';
+CREATE INDEX idx_matches_reminder_due ON matches(expires_at)
+    WHERE status IN ('pending', 'accepted') AND reminder_sent_at IS NULL;
+
+COMMENT ON COLUMN matches.reminder_sent_at IS 'When the last chance reminder before expiry was sent';
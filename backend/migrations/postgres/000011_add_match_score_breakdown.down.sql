@@ -0,0 +1,3 @@
+-- Drop columns
+ALTER TABLE matches DROP COLUMN IF EXISTS score_breakdown;
+ALTER TABLE users DROP COLUMN IF EXISTS interests;
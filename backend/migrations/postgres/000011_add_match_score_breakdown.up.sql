@@ -0,0 +1,9 @@
+-- Interests are free-form tags used to explain matches ("You both love hiking")
+ALTER TABLE users ADD COLUMN interests VARCHAR(50)[] NOT NULL DEFAULT '{}'
+    CHECK (cardinality(interests) <= 20);
+
+-- Keep the per-component scores behind match_score
+ALTER TABLE matches ADD COLUMN score_breakdown JSONB;
+
+COMMENT ON COLUMN users.interests IS 'Lowercase interest tags, at most 20';
+COMMENT ON COLUMN matches.score_breakdown IS 'Component scores and shared interests behind match_score';
@@ -0,0 +1,10 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS check_blocked_call ON video_calls;
+DROP TRIGGER IF EXISTS update_reports_updated_at ON reports;
+
+-- Drop functions
+DROP FUNCTION IF EXISTS refuse_blocked_call();
+
+-- Drop tables
+DROP TABLE IF EXISTS reports;
+DROP TABLE IF EXISTS blocks;
@@ -0,0 +1,61 @@
+-- Create blocks table
+CREATE TABLE blocks (
+    blocker_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (blocker_id, blocked_id),
+    CONSTRAINT no_self_block CHECK (blocker_id != blocked_id)
+);
+
+CREATE INDEX idx_blocks_blocked ON blocks(blocked_id);
+
+-- Create reports table
+CREATE TABLE reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    reporter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reported_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category VARCHAR(50) NOT NULL CHECK (category IN ('harassment', 'inappropriate_content', 'fake_profile', 'spam', 'scam', 'underage', 'safety_concern', 'other')),
+    description TEXT CHECK (char_length(description) <= 2000),
+    evidence JSONB NOT NULL DEFAULT '[]',
+    match_id UUID REFERENCES matches(id) ON DELETE SET NULL,
+    call_id UUID REFERENCES video_calls(id) ON DELETE SET NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'actioned', 'dismissed')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT no_self_report CHECK (reporter_id != reported_id)
+);
+
+CREATE INDEX idx_reports_reported ON reports(reported_id, created_at DESC);
+CREATE INDEX idx_reports_reporter ON reports(reporter_id, created_at DESC);
+
+-- One open report per reporter and reported user
+CREATE UNIQUE INDEX idx_reports_one_open ON reports(reporter_id, reported_id) WHERE status = 'open';
+
+CREATE TRIGGER update_reports_updated_at
+    BEFORE UPDATE ON reports
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Refuse video calls between users where either has blocked the other
+CREATE OR REPLACE FUNCTION refuse_blocked_call()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM blocks
+        WHERE (blocker_id = NEW.caller_id AND blocked_id = NEW.callee_id)
+           OR (blocker_id = NEW.callee_id AND blocked_id = NEW.caller_id)
+    ) THEN
+        RAISE EXCEPTION 'call between blocked users' USING ERRCODE = 'check_violation', CONSTRAINT = 'no_blocked_call';
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER check_blocked_call
+    BEFORE INSERT ON video_calls
+    FOR EACH ROW
+    EXECUTE FUNCTION refuse_blocked_call();
+
+COMMENT ON TABLE blocks IS 'Users a user never wants to see, match or call again';
+COMMENT ON TABLE reports IS 'User reports, each reviewed through a moderation item';
+COMMENT ON COLUMN reports.evidence IS 'List of {type, reference} items supporting the report';
@@ -0,0 +1,12 @@
+-- Drop indexes
+DROP INDEX IF EXISTS idx_video_calls_unanswered;
+DROP INDEX IF EXISTS idx_video_calls_live_callee;
+DROP INDEX IF EXISTS idx_video_calls_live_caller;
+
+-- Drop columns
+ALTER TABLE video_calls DROP COLUMN IF EXISTS callee_feedback;
+ALTER TABLE video_calls DROP COLUMN IF EXISTS callee_quality_rating;
+ALTER TABLE video_calls DROP COLUMN IF EXISTS end_reason;
+ALTER TABLE video_calls DROP COLUMN IF EXISTS ended_by;
+
+COMMENT ON COLUMN video_calls.quality_rating IS 'User-provided call quality rating';
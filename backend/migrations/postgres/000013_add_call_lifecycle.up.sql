@@ -0,0 +1,20 @@
+-- Who ended a call and why
+ALTER TABLE video_calls ADD COLUMN ended_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE video_calls ADD COLUMN end_reason VARCHAR(50);
+
+-- quality_rating and feedback hold the caller's review; the callee's goes here
+ALTER TABLE video_calls ADD COLUMN callee_quality_rating INTEGER
+    CHECK (callee_quality_rating >= 1 AND callee_quality_rating <= 5);
+ALTER TABLE video_calls ADD COLUMN callee_feedback TEXT;
+
+-- Support the busy check on initiate and the ring timeout sweep
+CREATE INDEX idx_video_calls_live_caller ON video_calls(caller_id)
+    WHERE status IN ('initiated', 'ringing', 'active');
+CREATE INDEX idx_video_calls_live_callee ON video_calls(callee_id)
+    WHERE status IN ('initiated', 'ringing', 'active');
+CREATE INDEX idx_video_calls_unanswered ON video_calls(created_at)
+    WHERE status IN ('initiated', 'ringing');
+
+COMMENT ON COLUMN video_calls.end_reason IS 'hangup, cancelled, timeout or failed';
+COMMENT ON COLUMN video_calls.quality_rating IS 'Caller-provided call quality rating';
+COMMENT ON COLUMN video_calls.callee_quality_rating IS 'Callee-provided call quality rating';
@@ -0,0 +1,37 @@
+-- Create device_tokens table
+CREATE TABLE device_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform VARCHAR(20) NOT NULL CHECK (platform IN ('ios', 'android')),
+    token VARCHAR(512) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_device_tokens_user ON device_tokens(user_id);
+
+-- Create notification_preferences table
+CREATE TABLE notification_preferences (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    new_match BOOLEAN NOT NULL DEFAULT TRUE,
+    mutual_match BOOLEAN NOT NULL DEFAULT TRUE,
+    match_expiring BOOLEAN NOT NULL DEFAULT TRUE,
+    incoming_call BOOLEAN NOT NULL DEFAULT TRUE,
+    call_missed BOOLEAN NOT NULL DEFAULT TRUE,
+    verification_result BOOLEAN NOT NULL DEFAULT TRUE,
+    quiet_hours_start TIME,
+    quiet_hours_end TIME,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    CONSTRAINT quiet_hours_complete CHECK ((quiet_hours_start IS NULL) = (quiet_hours_end IS NULL))
+);
+
+CREATE TRIGGER update_notification_preferences_updated_at
+    BEFORE UPDATE ON notification_preferences
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE device_tokens IS 'APNs and FCM tokens of the devices each user is signed in on';
+COMMENT ON COLUMN device_tokens.token IS 'Belongs to whoever registered it last, since devices change hands';
+COMMENT ON TABLE notification_preferences IS 'Which push notifications a user wants; no row means all of them';
+COMMENT ON COLUMN notification_preferences.quiet_hours_start IS 'Local time in timezone when pushes stop; the window may wrap past midnight';
@@ -0,0 +1,6 @@
+-- Drop triggers
+DROP TRIGGER IF EXISTS update_conversations_updated_at ON conversations;
+
+-- Drop tables
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS conversations;
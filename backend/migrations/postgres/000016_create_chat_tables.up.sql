@@ -0,0 +1,45 @@
+-- Create conversations table, one per mutual match
+CREATE TABLE conversations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    match_id UUID NOT NULL UNIQUE REFERENCES matches(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    matched_user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    closed_at TIMESTAMP WITH TIME ZONE,
+    close_reason VARCHAR(50) CHECK (close_reason IN ('match_ended', 'blocked')),
+    last_message_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT close_reason_when_closed CHECK ((closed_at IS NULL) = (close_reason IS NULL))
+);
+
+CREATE INDEX idx_conversations_user ON conversations(user_id, last_message_at DESC);
+CREATE INDEX idx_conversations_matched_user ON conversations(matched_user_id, last_message_at DESC);
+
+CREATE TRIGGER update_conversations_updated_at BEFORE UPDATE ON conversations
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Create messages table
+CREATE TABLE messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    read_at TIMESTAMP WITH TIME ZONE,
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Newest first, with id breaking ties for cursor pagination
+CREATE INDEX idx_messages_conversation_created ON messages(conversation_id, created_at DESC, id DESC);
+CREATE INDEX idx_messages_unread ON messages(conversation_id, sender_id)
+    WHERE read_at IS NULL AND deleted_at IS NULL;
+
+-- Open conversations for mutual matches made before chat existed
+INSERT INTO conversations (match_id, user_id, matched_user_id)
+SELECT id, user_id, matched_user_id FROM matches
+WHERE mutual_match = TRUE AND status = 'accepted' AND deleted_at IS NULL;
+
+COMMENT ON TABLE conversations IS 'Chat between the two users of a mutual match';
+COMMENT ON COLUMN conversations.close_reason IS 'match_ended or blocked; closed conversations stay readable';
+COMMENT ON COLUMN messages.deleted_at IS 'Soft deletion by the sender; the body is hidden from then on';
@@ -0,0 +1,11 @@
+-- Drop constraints
+DELETE FROM moderation_items WHERE item_type = 'message';
+ALTER TABLE moderation_items DROP CONSTRAINT IF EXISTS moderation_items_item_type_check;
+ALTER TABLE moderation_items ADD CONSTRAINT moderation_items_item_type_check
+    CHECK (item_type IN ('video', 'photo', 'bio', 'report'));
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_messages_held;
+
+-- Drop columns
+ALTER TABLE messages DROP COLUMN IF EXISTS held_at;
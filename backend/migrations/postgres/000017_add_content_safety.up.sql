@@ -0,0 +1,12 @@
+-- Messages the content filter holds stay hidden from the recipient until a
+-- moderator approves them
+ALTER TABLE messages ADD COLUMN held_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_messages_held ON messages(conversation_id) WHERE held_at IS NOT NULL;
+
+-- Held messages are reviewed in the moderation queue
+ALTER TABLE moderation_items DROP CONSTRAINT IF EXISTS moderation_items_item_type_check;
+ALTER TABLE moderation_items ADD CONSTRAINT moderation_items_item_type_check
+    CHECK (item_type IN ('video', 'photo', 'bio', 'report', 'message'));
+
+COMMENT ON COLUMN messages.held_at IS 'Set while the message waits for moderation; only the sender sees it';
@@ -0,0 +1,3 @@
+-- Drop video trigger
+DROP TRIGGER IF EXISTS enqueue_videos_profile_embedding ON videos;
+DROP FUNCTION IF EXISTS enqueue_video_embedding();
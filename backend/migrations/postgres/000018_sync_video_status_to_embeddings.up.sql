@@ -0,0 +1,29 @@
+-- Candidate search only returns users with a verified video, so the vector
+-- index needs to hear about video status changes as well as profile changes
+CREATE OR REPLACE FUNCTION enqueue_video_embedding()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO embedding_outbox (user_id, operation) VALUES (OLD.user_id, 'upsert');
+        RETURN OLD;
+    END IF;
+
+    IF TG_OP = 'UPDATE' AND
+       (OLD.user_id, OLD.status, OLD.deleted_at) IS NOT DISTINCT FROM (NEW.user_id, NEW.status, NEW.deleted_at) THEN
+        RETURN NEW;
+    END IF;
+
+    INSERT INTO embedding_outbox (user_id, operation) VALUES (NEW.user_id, 'upsert');
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER enqueue_videos_profile_embedding
+    AFTER INSERT OR UPDATE OR DELETE ON videos
+    FOR EACH ROW
+    EXECUTE FUNCTION enqueue_video_embedding();
+
+-- Points indexed before this change carry neither video_verified nor
+-- date_of_birth, so resync every profile
+INSERT INTO embedding_outbox (user_id, operation)
+SELECT id, 'upsert' FROM users WHERE deleted_at IS NULL;
@@ -0,0 +1,5 @@
+-- Put held bios back on the profile
+UPDATE users SET bio = pending_bio WHERE pending_bio IS NOT NULL;
+
+-- Drop columns
+ALTER TABLE users DROP COLUMN IF EXISTS pending_bio;
@@ -0,0 +1,12 @@
+-- Bios the content filter holds wait here until a moderator approves them,
+-- so the reviewed bio stays on the profile meanwhile
+ALTER TABLE users ADD COLUMN pending_bio TEXT;
+
+-- Move bios already held for review out of the live profile
+UPDATE users SET pending_bio = bio, bio = NULL
+WHERE id IN (
+    SELECT item_id FROM moderation_items
+    WHERE item_type = 'bio' AND status IN ('pending', 'claimed')
+);
+
+COMMENT ON COLUMN users.pending_bio IS 'Held bio awaiting moderation; replaces bio when approved';
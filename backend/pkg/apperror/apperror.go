@@ -0,0 +1,153 @@
+// Package apperror defines the errors the API reports to clients. Each has a
+// stable code that maps to an HTTP status; the message is safe to show and
+// the cause, if any, is only logged.
+package apperror
+
+import (
+	"errors"
+	"net/http"
+)
+
+type Code string
+
+const (
+	CodeUnauthorized Code = "UNAUTHORIZED"
+	CodeForbidden    Code = "FORBIDDEN"
+	CodeNotFound     Code = "NOT_FOUND"
+	CodeValidation   Code = "VALIDATION_ERROR"
+	CodeConflict     Code = "CONFLICT"
+	CodeRateLimited  Code = "RATE_LIMIT_EXCEEDED"
+	CodeInternal     Code = "INTERNAL_ERROR"
+)
+
+// Status is the HTTP status responses with the code get.
+func (c Code) Status() int {
+	switch c {
+	case CodeUnauthorized:
+		return http.StatusUnauthorized
+	case CodeForbidden:
+		return http.StatusForbidden
+	case CodeNotFound:
+		return http.StatusNotFound
+	case CodeValidation:
+		return http.StatusUnprocessableEntity
+	case CodeConflict:
+		return http.StatusConflict
+	case CodeRateLimited:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Sentinels for errors.Is, which matches any Error with the same code.
+var (
+	ErrUnauthorized = &Error{Code: CodeUnauthorized}
+	ErrForbidden    = &Error{Code: CodeForbidden}
+	ErrNotFound     = &Error{Code: CodeNotFound}
+	ErrValidation   = &Error{Code: CodeValidation}
+	ErrConflict     = &Error{Code: CodeConflict}
+	ErrRateLimited  = &Error{Code: CodeRateLimited}
+	ErrInternal     = &Error{Code: CodeInternal}
+)
+
+type Error struct {
+	Code    Code
+	Message string
+	// Details is rendered as is, e.g. the invalid fields of a request
+	Details any
+	// Err is the underlying cause. It is logged, never rendered.
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches sentinels by code.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Message == "" && t.Code == e.Code
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{Code: CodeUnauthorized, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Code: CodeForbidden, Message: message}
+}
+
+func NotFound(message string) *Error {
+	return &Error{Code: CodeNotFound, Message: message}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Code: CodeConflict, Message: message}
+}
+
+// Validation reports invalid input. fields maps each invalid field to what
+// is wrong with it and may be nil.
+func Validation(message string, fields map[string]string) *Error {
+	e := &Error{Code: CodeValidation, Message: message}
+	if len(fields) > 0 {
+		e.Details = map[string]any{"fields": fields}
+	}
+	return e
+}
+
+func RateLimited(message string, retryAfterSeconds int) *Error {
+	return &Error{
+		Code:    CodeRateLimited,
+		Message: message,
+		Details: map[string]any{"retry_after": retryAfterSeconds},
+	}
+}
+
+// Internal hides err behind a generic message.
+func Internal(message string, err error) *Error {
+	return &Error{Code: CodeInternal, Message: message, Err: err}
+}
+
+// Wrap attaches err as the cause of an error with the given code.
+func Wrap(code Code, message string, err error) *Error {
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+// From returns the Error in err's chain, or an internal error with err as
+// its cause.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return Internal("internal server error", err)
+}
+
+// Body is the JSON shape of an error response.
+type Body struct {
+	Success bool      `json:"success"`
+	Error   BodyError `json:"error"`
+}
+
+type BodyError struct {
+	Code    Code   `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+// Response renders e for clients.
+func (e *Error) Response() Body {
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.Code.Status())
+	}
+	return Body{Error: BodyError{Code: e.Code, Message: message, Details: e.Details}}
+}
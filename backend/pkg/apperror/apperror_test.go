@@ -0,0 +1,47 @@
+package apperror
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestFrom(t *testing.T) {
+	notFound := NotFound("match not found")
+
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    Code
+		wantStatus  int
+		wantMessage string
+		wantIs      error
+	}{
+		{"typed", notFound, CodeNotFound, http.StatusNotFound, "match not found", ErrNotFound},
+		{"wrapped", fmt.Errorf("accept: %w", notFound), CodeNotFound, http.StatusNotFound, "match not found", notFound},
+		{"validation", Validation("invalid request", map[string]string{"email": "must be an email"}), CodeValidation, http.StatusUnprocessableEntity, "invalid request", ErrValidation},
+		{"with cause", Wrap(CodeConflict, "email already registered", sql.ErrNoRows), CodeConflict, http.StatusConflict, "email already registered", sql.ErrNoRows},
+		{"untyped", errors.New("pq: connection refused"), CodeInternal, http.StatusInternalServerError, "internal server error", ErrInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := From(tt.err)
+			body := e.Response()
+			if body.Success || body.Error.Code != tt.wantCode || body.Error.Message != tt.wantMessage {
+				t.Errorf("Response() = %+v, want %s %q", body, tt.wantCode, tt.wantMessage)
+			}
+			if got := e.Code.Status(); got != tt.wantStatus {
+				t.Errorf("Status() = %d, want %d", got, tt.wantStatus)
+			}
+			if !errors.Is(e, tt.wantIs) {
+				t.Errorf("errors.Is(%v, %v) = false", e, tt.wantIs)
+			}
+		})
+	}
+
+	if errors.Is(NotFound("a"), ErrConflict) {
+		t.Error("a not found error matches ErrConflict")
+	}
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrLockNotAcquired = errors.New("lock is held by another process")
+
+// Only the holder's token may release or extend a lock, so a process whose
+// lock expired cannot clobber the next holder.
+var (
+	releaseScript = redis.NewScript(`
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			return redis.call("DEL", KEYS[1])
+		end
+		return 0
+	`)
+	extendScript = redis.NewScript(`
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+		end
+		return 0
+	`)
+)
+
+// Lock is a distributed lock held in Redis.
+type Lock struct {
+	client *redis.Client
+	key    string
+	token  string
+}
+
+// AcquireLock takes the lock with SET NX and a TTL, returning
+// ErrLockNotAcquired if someone else holds it.
+func (r *RedisCache) AcquireLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	lock := &Lock{
+		client: r.client,
+		key:    r.prefix + key,
+		token:  hex.EncodeToString(buf),
+	}
+
+	ok, err := r.client.SetNX(ctx, lock.key, lock.token, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrLockNotAcquired
+	}
+	return lock, nil
+}
+
+// Extend resets the lock's TTL. It returns ErrLockNotAcquired if the lock
+// has expired and been taken by someone else in the meantime.
+func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
+	n, err := extendScript.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLockNotAcquired
+	}
+	return nil
+}
+
+func (l *Lock) Release(ctx context.Context) error {
+	return releaseScript.Run(ctx, l.client, []string{l.key}, l.token).Err()
+}
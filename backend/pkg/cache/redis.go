@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -28,6 +29,11 @@ func NewRedisCache(addr, password string, db int, prefix string) (*RedisCache, e
 		return nil, err
 	}
 
+	// Trace every command as a child span of the caller's
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		return nil, err
+	}
+
 	return &RedisCache{
 		client: client,
 		prefix: prefix,
@@ -59,6 +65,22 @@ func (r *RedisCache) Delete(ctx context.Context, key string) error {
 	return r.client.Del(ctx, fullKey).Err()
 }
 
+// Incr increments the integer at key and resets its TTL, returning the new
+// value.
+func (r *RedisCache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	fullKey := r.prefix + key
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, fullKey)
+		pipe.Expire(ctx, fullKey, ttl)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
 func (r *RedisCache) Exists(ctx context.Context, key string) (bool, error) {
 	fullKey := r.prefix + key
 	count, err := r.client.Exists(ctx, fullKey).Result()
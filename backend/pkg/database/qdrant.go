@@ -0,0 +1,34 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/qdrant/go-client/qdrant"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+type QdrantConfig struct {
+	Host   string
+	Port   int
+	APIKey string
+	UseTLS bool
+}
+
+// NewQdrantClient creates a gRPC client for Qdrant. The connection is
+// established lazily, so an unavailable server surfaces as request errors
+// rather than failing startup. Every call is traced as a client span.
+func NewQdrantClient(cfg QdrantConfig) (*qdrant.Client, error) {
+	client, err := qdrant.NewClient(&qdrant.Config{
+		Host:                   cfg.Host,
+		Port:                   cfg.Port,
+		APIKey:                 cfg.APIKey,
+		UseTLS:                 cfg.UseTLS,
+		SkipCompatibilityCheck: true,
+		GrpcOptions:            []grpc.DialOption{grpc.WithStatsHandler(otelgrpc.NewClientHandler())},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Qdrant client: %w", err)
+	}
+	return client, nil
+}
@@ -0,0 +1,52 @@
+package health
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+// Dial checks that a TCP connection to addr can be opened.
+func Dial(addr string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// SMTP checks that the server at addr greets and answers EHLO. It does not
+// authenticate or send mail.
+func SMTP(addr string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return err
+		}
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		} else {
+			conn.SetDeadline(time.Now().Add(defaultTimeout))
+		}
+
+		client, err := smtp.NewClient(conn, host)
+		if err != nil {
+			conn.Close()
+			return err
+		}
+		defer client.Close()
+		if err := client.Hello("localhost"); err != nil {
+			return err
+		}
+		return client.Quit()
+	}
+}
@@ -0,0 +1,175 @@
+// Package health runs checks against the dependencies of the service. A
+// Registry answers liveness cheaply, readiness from a short-lived cache of
+// its critical checks, and reports every check in detail on demand.
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultTimeout = 2 * time.Second
+
+// ErrShuttingDown fails readiness once shutdown has begun.
+var ErrShuttingDown = errors.New("shutting down")
+
+// Status of a check or of the service as a whole
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Checker checks one dependency.
+type Checker struct {
+	Name  string
+	Check func(ctx context.Context) error
+	// Timeout bounds a single check; zero means two seconds
+	Timeout time.Duration
+	// Critical checks decide readiness; the others are only reported
+	Critical bool
+}
+
+// Result is the outcome of one check.
+type Result struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Critical  bool      `json:"critical"`
+	LatencyMS float64   `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Report aggregates results. Status is down if any critical check is.
+type Report struct {
+	Status     string    `json:"status"`
+	Components []Result  `json:"components"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Up reports whether the service should receive traffic.
+func (r *Report) Up() bool {
+	return r.Status == StatusUp
+}
+
+type Registry struct {
+	mu       sync.RWMutex
+	checkers []Checker
+
+	// readyMu serializes refreshes so a burst of probes runs the checks once
+	readyMu  sync.Mutex
+	cacheTTL time.Duration
+	cached   *Report
+
+	shuttingDown atomic.Bool
+	now          func() time.Time
+}
+
+// NewRegistry returns a registry that reuses a readiness result for
+// cacheTTL, so frequent probes do not load the dependencies.
+func NewRegistry(cacheTTL time.Duration) *Registry {
+	return &Registry{cacheTTL: cacheTTL, now: time.Now}
+}
+
+func (r *Registry) Register(checker Checker) {
+	if checker.Timeout <= 0 {
+		checker.Timeout = defaultTimeout
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkers = append(r.checkers, checker)
+}
+
+// Shutdown makes readiness fail from now on, so load balancers stop sending
+// requests while those in flight complete.
+func (r *Registry) Shutdown() {
+	r.shuttingDown.Store(true)
+}
+
+// Ready runs the critical checks, or returns their result from the last
+// cacheTTL.
+func (r *Registry) Ready(ctx context.Context) *Report {
+	if r.shuttingDown.Load() {
+		now := r.now()
+		return &Report{
+			Status: StatusDown,
+			Components: []Result{{
+				Name: "server", Status: StatusDown, Critical: true,
+				Error: ErrShuttingDown.Error(), CheckedAt: now,
+			}},
+			CheckedAt: now,
+		}
+	}
+
+	r.readyMu.Lock()
+	defer r.readyMu.Unlock()
+	if r.cached != nil && r.now().Sub(r.cached.CheckedAt) < r.cacheTTL {
+		return r.cached
+	}
+	r.cached = r.run(ctx, true)
+	return r.cached
+}
+
+// Check runs every check now, critical or not.
+func (r *Registry) Check(ctx context.Context) *Report {
+	return r.run(ctx, false)
+}
+
+// run checks in parallel, each under its own timeout.
+func (r *Registry) run(ctx context.Context, criticalOnly bool) *Report {
+	r.mu.RLock()
+	checkers := make([]Checker, 0, len(r.checkers))
+	for _, c := range r.checkers {
+		if c.Critical || !criticalOnly {
+			checkers = append(checkers, c)
+		}
+	}
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checkers))
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.runOne(ctx, checker)
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{Status: StatusUp, Components: results, CheckedAt: r.now()}
+	for _, result := range results {
+		if result.Critical && result.Status == StatusDown {
+			report.Status = StatusDown
+		}
+	}
+	if r.shuttingDown.Load() {
+		report.Status = StatusDown
+	}
+	return report
+}
+
+func (r *Registry) runOne(ctx context.Context, checker Checker) Result {
+	ctx, cancel := context.WithTimeout(ctx, checker.Timeout)
+	defer cancel()
+
+	start := r.now()
+	err := checker.Check(ctx)
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+	result := Result{
+		Name:      checker.Name,
+		Status:    StatusUp,
+		Critical:  checker.Critical,
+		LatencyMS: float64(r.now().Sub(start).Microseconds()) / 1000,
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func check(err error) func(context.Context) error {
+	return func(context.Context) error { return err }
+}
+
+func TestReport(t *testing.T) {
+	errDown := errors.New("connection refused")
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name       string
+		checkers   []Checker
+		wantStatus string
+		wantReady  string
+	}{
+		{
+			name: "all up",
+			checkers: []Checker{
+				{Name: "postgres", Check: check(nil), Critical: true},
+				{Name: "qdrant", Check: check(nil)},
+			},
+			wantStatus: StatusUp,
+			wantReady:  StatusUp,
+		},
+		{
+			name: "critical down",
+			checkers: []Checker{
+				{Name: "postgres", Check: check(errDown), Critical: true},
+				{Name: "qdrant", Check: check(nil)},
+			},
+			wantStatus: StatusDown,
+			wantReady:  StatusDown,
+		},
+		{
+			name: "optional down",
+			checkers: []Checker{
+				{Name: "postgres", Check: check(nil), Critical: true},
+				{Name: "smtp", Check: check(errDown)},
+			},
+			wantStatus: StatusUp,
+			wantReady:  StatusUp,
+		},
+		{
+			name: "critical times out",
+			checkers: []Checker{
+				{Name: "redis", Check: hang, Timeout: 10 * time.Millisecond, Critical: true},
+			},
+			wantStatus: StatusDown,
+			wantReady:  StatusDown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry(time.Minute)
+			for _, c := range tt.checkers {
+				r.Register(c)
+			}
+
+			report := r.Check(context.Background())
+			if report.Status != tt.wantStatus {
+				t.Errorf("Check().Status = %s, want %s", report.Status, tt.wantStatus)
+			}
+			if len(report.Components) != len(tt.checkers) {
+				t.Errorf("Check() reported %d components, want %d", len(report.Components), len(tt.checkers))
+			}
+			if got := r.Ready(context.Background()).Status; got != tt.wantReady {
+				t.Errorf("Ready().Status = %s, want %s", got, tt.wantReady)
+			}
+		})
+	}
+}
+
+func TestReadyCache(t *testing.T) {
+	var calls atomic.Int32
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	r := NewRegistry(5 * time.Second)
+	r.now = func() time.Time { return now }
+	r.Register(Checker{Name: "postgres", Critical: true, Check: func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}})
+	r.Register(Checker{Name: "smtp", Check: func(context.Context) error {
+		t.Error("Ready() ran a check that is not critical")
+		return nil
+	}})
+
+	r.Ready(context.Background())
+	now = now.Add(4 * time.Second)
+	r.Ready(context.Background())
+	if got := calls.Load(); got != 1 {
+		t.Errorf("checks ran %d times within the cache TTL, want 1", got)
+	}
+	now = now.Add(2 * time.Second)
+	r.Ready(context.Background())
+	if got := calls.Load(); got != 2 {
+		t.Errorf("checks ran %d times after the TTL, want 2", got)
+	}
+
+	r.Shutdown()
+	if report := r.Ready(context.Background()); report.Up() {
+		t.Error("Ready() is up after Shutdown(), want down")
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("Ready() ran checks after Shutdown()")
+	}
+}
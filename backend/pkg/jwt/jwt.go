@@ -9,16 +9,18 @@ import (
 )
 
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Email  string    `json:"email"`
-	Type   string    `json:"type"` // "access" or "refresh"
+	UserID       uuid.UUID `json:"user_id"`
+	Email        string    `json:"email"`
+	Role         string    `json:"role,omitempty"`
+	TokenVersion int       `json:"ver"`
+	Type         string    `json:"type"` // "access" or "refresh"
 	jwt.RegisteredClaims
 }
 
 type JWTManager struct {
-	secretKey             string
-	accessTokenDuration   time.Duration
-	refreshTokenDuration  time.Duration
+	secretKey            string
+	accessTokenDuration  time.Duration
+	refreshTokenDuration time.Duration
 }
 
 func NewJWTManager(secretKey string, accessMinutes, refreshDays int) *JWTManager {
@@ -29,11 +31,13 @@ func NewJWTManager(secretKey string, accessMinutes, refreshDays int) *JWTManager
 	}
 }
 
-func (m *JWTManager) GenerateAccessToken(userID uuid.UUID, email string) (string, error) {
+func (m *JWTManager) GenerateAccessToken(userID uuid.UUID, email, role string, tokenVersion int) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		Type:   "access",
+		UserID:       userID,
+		Email:        email,
+		Role:         role,
+		TokenVersion: tokenVersion,
+		Type:         "access",
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.accessTokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -45,11 +49,13 @@ func (m *JWTManager) GenerateAccessToken(userID uuid.UUID, email string) (string
 	return token.SignedString([]byte(m.secretKey))
 }
 
-func (m *JWTManager) GenerateRefreshToken(userID uuid.UUID, email string) (string, error) {
+func (m *JWTManager) GenerateRefreshToken(userID uuid.UUID, email, role string, tokenVersion int) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
-		Type:   "refresh",
+		UserID:       userID,
+		Email:        email,
+		Role:         role,
+		TokenVersion: tokenVersion,
+		Type:         "refresh",
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.refreshTokenDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
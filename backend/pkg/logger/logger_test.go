@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"empty", "", ""},
+		{"nothing sensitive", "page=2&limit=20", "limit=20&page=2"},
+		{"token", "token=abc123", "token=%5BREDACTED%5D"},
+		{"api key with dash", "api-key=abc&q=x", "api-key=%5BREDACTED%5D&q=x"},
+		{"reset token", "reset_token=abc", "reset_token=%5BREDACTED%5D"},
+		{"malformed", "token=%zz", Redacted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactQuery(tt.raw); got != tt.want {
+				t.Errorf("RedactQuery(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{
+		"Authorization":          {"Bearer secret"},
+		"Cookie":                 {"session=1"},
+		"X-Api-Key":              {"abc"},
+		"Accept":                 {"application/json", "text/plain"},
+		"Sec-Websocket-Protocol": {"bearer, eyJhbGciOi"},
+	}
+	got := RedactHeaders(header)
+	for _, name := range []string{"Authorization", "Cookie", "X-Api-Key", "Sec-Websocket-Protocol"} {
+		if got[name] != Redacted {
+			t.Errorf("%s = %q, want redacted", name, got[name])
+		}
+	}
+	if got["Accept"] != "application/json, text/plain" {
+		t.Errorf("Accept = %q", got["Accept"])
+	}
+}
+
+func TestLoggerRecord(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf, Config{Level: "info", Format: "json"})
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	log.DebugContext(ctx, "hidden")
+	log.InfoContext(ctx, "login", "email", "a@b.c", "password", "hunter2", "refresh_token", "xyz")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("want a single JSON record, got %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"msg":           "login",
+		"request_id":    "req-1",
+		"email":         "a@b.c",
+		"password":      Redacted,
+		"refresh_token": Redacted,
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("%s = %v, want %v", key, record[key], value)
+		}
+	}
+}
+
+func TestLoggerTraceContext(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf, Config{Format: "json"})
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	log.InfoContext(ctx, "traced")
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("invalid record %q: %v", buf.String(), err)
+	}
+	if record["trace_id"] != traceID.String() || record["span_id"] != spanID.String() {
+		t.Errorf("trace_id = %v, span_id = %v", record["trace_id"], record["span_id"])
+	}
+}
@@ -0,0 +1,421 @@
+// Package migrate applies versioned schema changes. SQL files are applied to
+// Postgres in a transaction each; Go steps cover other stores. Every applied
+// version is recorded in a schema table, guarded by an advisory lock so
+// replicas starting together apply each change once.
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// TargetPostgres is the target of SQL file migrations.
+const TargetPostgres = "postgres"
+
+// lockKey identifies the advisory lock held while migrating.
+const lockKey int64 = 0x66696e646d65 // "findme"
+
+var (
+	ErrChecksumMismatch = errors.New("applied migration has been modified")
+	ErrNoDown           = errors.New("migration has no down step")
+	ErrDirtyLegacy      = errors.New("legacy schema_migrations table is dirty")
+)
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Step is a migration written in Go, for stores other than Postgres. Steps
+// run outside the schema transaction, so they must be safe to repeat.
+type Step struct {
+	Target  string
+	Version int64
+	Name    string
+	Up      func(ctx context.Context) error
+	// Down may be nil when there is nothing to undo; the step then stays
+	// applied when migrating down.
+	Down func(ctx context.Context) error
+}
+
+type migration struct {
+	target   string
+	version  int64
+	name     string
+	checksum string
+	upSQL    string
+	downSQL  string
+	step     *Step
+}
+
+// Status describes one known migration.
+type Status struct {
+	Target    string
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []*migration
+}
+
+// New reads the NNNNNN_name.up.sql and .down.sql files at the root of files.
+// They are applied in version order, followed by the steps in the order
+// given.
+func New(db *sql.DB, files fs.FS, steps ...Step) (*Migrator, error) {
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		body, err := fs.ReadFile(files, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{target: TargetPostgres, version: version, name: match[2]}
+			byVersion[version] = m
+		} else if m.name != match[2] {
+			return nil, fmt.Errorf("version %d is used by both %s and %s", version, m.name, match[2])
+		}
+		if match[3] == "up" {
+			m.upSQL = string(body)
+			sum := sha256.Sum256(body)
+			m.checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.downSQL = string(body)
+		}
+	}
+
+	mg := &Migrator{db: db}
+	for _, m := range byVersion {
+		if m.upSQL == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.version, m.name)
+		}
+		mg.migrations = append(mg.migrations, m)
+	}
+	sort.Slice(mg.migrations, func(i, j int) bool {
+		return mg.migrations[i].version < mg.migrations[j].version
+	})
+
+	seen := make(map[string]bool)
+	for i := range steps {
+		step := &steps[i]
+		mig := &migration{target: step.Target, version: step.Version, name: step.Name, step: step}
+		if step.Target == TargetPostgres {
+			return nil, fmt.Errorf("step %s targets postgres; use a SQL file", mig)
+		}
+		if seen[mig.key()] {
+			return nil, fmt.Errorf("version %d is used twice for %s", step.Version, step.Target)
+		}
+		seen[mig.key()] = true
+		mg.migrations = append(mg.migrations, mig)
+	}
+
+	return mg, nil
+}
+
+// Up applies every pending migration and returns how many were applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.locked(ctx, func(conn *sql.Conn, done map[string]appliedRow) error {
+		for _, mig := range m.migrations {
+			if _, ok := done[mig.key()]; ok {
+				continue
+			}
+			started := time.Now()
+			if err := mig.up(ctx, conn); err != nil {
+				return fmt.Errorf("migration %s failed: %w", mig, err)
+			}
+			slog.InfoContext(ctx, "Applied migration", "migration", mig.String(), "duration", time.Since(started))
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last n applied migrations, most recently applied first.
+// Steps without a Down are left applied and don't count towards n.
+func (m *Migrator) Down(ctx context.Context, n int) (int, error) {
+	reverted := 0
+	err := m.locked(ctx, func(conn *sql.Conn, done map[string]appliedRow) error {
+		for _, mig := range m.revertOrder(done) {
+			if reverted == n {
+				break
+			}
+			if err := mig.down(ctx, conn); err != nil {
+				return fmt.Errorf("reverting migration %s failed: %w", mig, err)
+			}
+			slog.InfoContext(ctx, "Reverted migration", "migration", mig.String())
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// revertOrder returns the applied migrations that can be reverted, latest
+// applied_at first. Migrations applied together, as adopted legacy ones are,
+// fall back to reverse declaration order.
+func (m *Migrator) revertOrder(done map[string]appliedRow) []*migration {
+	var revertible []*migration
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if _, ok := done[mig.key()]; !ok || (mig.step != nil && mig.step.Down == nil) {
+			continue
+		}
+		revertible = append(revertible, mig)
+	}
+	sort.SliceStable(revertible, func(i, j int) bool {
+		return done[revertible[i].key()].appliedAt.After(done[revertible[j].key()].appliedAt)
+	})
+	return revertible
+}
+
+// Status lists every known migration and whether it has been applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.locked(ctx, func(_ *sql.Conn, done map[string]appliedRow) error {
+		for _, mig := range m.migrations {
+			row, ok := done[mig.key()]
+			statuses = append(statuses, Status{
+				Target:    mig.target,
+				Version:   mig.version,
+				Name:      mig.name,
+				Applied:   ok,
+				AppliedAt: row.appliedAt,
+			})
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+type appliedRow struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// locked runs fn on a connection holding the migration lock, with the
+// applied versions loaded and their checksums verified.
+func (m *Migrator) locked(ctx context.Context, fn func(*sql.Conn, map[string]appliedRow) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		// A fresh context so the lock is released even after cancellation
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.ExecContext(unlockCtx, `SELECT pg_advisory_unlock($1)`, lockKey); err != nil {
+			slog.Error("Failed to release migration lock", "error", err)
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_versions (
+			target VARCHAR(50) NOT NULL,
+			version BIGINT NOT NULL,
+			name VARCHAR(255) NOT NULL,
+			checksum VARCHAR(64) NOT NULL DEFAULT '',
+			applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (target, version)
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create schema_versions: %w", err)
+	}
+
+	if err := m.adoptLegacy(ctx, conn); err != nil {
+		return err
+	}
+
+	done, err := m.applied(ctx, conn)
+	if err != nil {
+		return err
+	}
+	return fn(conn, done)
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[string]appliedRow, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT target, version, checksum, applied_at FROM schema_versions`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	done := make(map[string]appliedRow)
+	for rows.Next() {
+		var target string
+		var version int64
+		var row appliedRow
+		if err := rows.Scan(&target, &version, &row.checksum, &row.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		done[path.Join(target, strconv.FormatInt(version, 10))] = row
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, mig := range m.migrations {
+		row, ok := done[mig.key()]
+		if ok && mig.checksum != "" && row.checksum != mig.checksum {
+			return nil, fmt.Errorf("%w: %s", ErrChecksumMismatch, mig)
+		}
+	}
+	return done, nil
+}
+
+// adoptLegacy records the Postgres migrations a database already got from
+// golang-migrate, whose schema_migrations table holds only the last version,
+// so they are not applied a second time.
+func (m *Migrator) adoptLegacy(ctx context.Context, conn *sql.Conn) error {
+	var tracked bool
+	if err := conn.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM schema_versions WHERE target = $1)`, TargetPostgres,
+	).Scan(&tracked); err != nil {
+		return fmt.Errorf("failed to check schema_versions: %w", err)
+	}
+	var legacy sql.NullString
+	if err := conn.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations')::text`).Scan(&legacy); err != nil {
+		return fmt.Errorf("failed to check for schema_migrations: %w", err)
+	}
+	if tracked || !legacy.Valid {
+		return nil
+	}
+
+	var version int64
+	var dirty bool
+	err := conn.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("%w at version %d; fix the schema by hand first", ErrDirtyLegacy, version)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, mig := range m.migrations {
+		if mig.target != TargetPostgres || mig.version > version {
+			continue
+		}
+		if err := record(ctx, tx, mig); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to adopt schema_migrations: %w", err)
+	}
+	slog.InfoContext(ctx, "Adopted golang-migrate history", "version", version)
+	return nil
+}
+
+func (mig *migration) key() string {
+	return path.Join(mig.target, strconv.FormatInt(mig.version, 10))
+}
+
+func (mig *migration) String() string {
+	return fmt.Sprintf("%s/%06d_%s", mig.target, mig.version, mig.name)
+}
+
+func (mig *migration) up(ctx context.Context, conn *sql.Conn) error {
+	if mig.step != nil {
+		if err := mig.step.Up(ctx); err != nil {
+			return err
+		}
+		return record(ctx, conn, mig)
+	}
+
+	return inTx(ctx, conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, mig.upSQL); err != nil {
+			return err
+		}
+		return record(ctx, tx, mig)
+	})
+}
+
+func (mig *migration) down(ctx context.Context, conn *sql.Conn) error {
+	if mig.step != nil {
+		if err := mig.step.Down(ctx); err != nil {
+			return err
+		}
+		return forget(ctx, conn, mig)
+	}
+
+	if mig.downSQL == "" {
+		return ErrNoDown
+	}
+	return inTx(ctx, conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, mig.downSQL); err != nil {
+			return err
+		}
+		return forget(ctx, tx, mig)
+	})
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func record(ctx context.Context, db execer, mig *migration) error {
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO schema_versions (target, version, name, checksum) VALUES ($1, $2, $3, $4)`,
+		mig.target, mig.version, mig.name, mig.checksum,
+	)
+	return err
+}
+
+func forget(ctx context.Context, db execer, mig *migration) error {
+	_, err := db.ExecContext(ctx,
+		`DELETE FROM schema_versions WHERE target = $1 AND version = $2`,
+		mig.target, mig.version,
+	)
+	return err
+}
+
+func inTx(ctx context.Context, conn *sql.Conn, fn func(*sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
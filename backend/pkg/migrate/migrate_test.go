@@ -0,0 +1,135 @@
+package migrate
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	file := func(body string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(body)} }
+	step := Step{Target: "qdrant", Version: 1, Name: "create_collection", Up: func(context.Context) error { return nil }}
+
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		steps   []Step
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "ordered by version, steps last",
+			files: fstest.MapFS{
+				"000002_add_roles.up.sql":      file("ALTER TABLE users ADD role TEXT;"),
+				"000001_create_users.up.sql":   file("CREATE TABLE users ();"),
+				"000001_create_users.down.sql": file("DROP TABLE users;"),
+				"README.md":                    file("not a migration"),
+			},
+			steps: []Step{step},
+			want:  []string{"postgres/000001_create_users", "postgres/000002_add_roles", "qdrant/000001_create_collection"},
+		},
+		{
+			name:    "down without up",
+			files:   fstest.MapFS{"000001_create_users.down.sql": file("DROP TABLE users;")},
+			wantErr: true,
+		},
+		{
+			name: "version reused",
+			files: fstest.MapFS{
+				"000001_create_users.up.sql":  file("CREATE TABLE users ();"),
+				"000001_create_videos.up.sql": file("CREATE TABLE videos ();"),
+			},
+			wantErr: true,
+		},
+		{
+			name:    "step version reused",
+			files:   fstest.MapFS{},
+			steps:   []Step{step, step},
+			wantErr: true,
+		},
+		{
+			name:    "step targets postgres",
+			files:   fstest.MapFS{},
+			steps:   []Step{{Target: TargetPostgres, Version: 1, Name: "seed"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := New(nil, tt.files, tt.steps...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			var got []string
+			for _, mig := range m.migrations {
+				got = append(got, mig.String())
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("migrations = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("migrations[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestChecksumIgnoresDownFile(t *testing.T) {
+	load := func(down string) string {
+		m, err := New(nil, fstest.MapFS{
+			"000001_create_users.up.sql":   {Data: []byte("CREATE TABLE users ();")},
+			"000001_create_users.down.sql": {Data: []byte(down)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m.migrations[0].checksum
+	}
+
+	if a, b := load("DROP TABLE users;"), load("DROP TABLE IF EXISTS users;"); a != b || a == "" {
+		t.Errorf("checksums = %q and %q, want equal and non-empty", a, b)
+	}
+}
+
+func TestRevertOrder(t *testing.T) {
+	noop := func(context.Context) error { return nil }
+	m, err := New(nil, fstest.MapFS{
+		"000001_create_users.up.sql":   {Data: []byte("CREATE TABLE users ();")},
+		"000001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"000002_add_roles.up.sql":      {Data: []byte("ALTER TABLE users ADD role TEXT;")},
+		"000003_add_bio.up.sql":        {Data: []byte("ALTER TABLE users ADD bio TEXT;")},
+	},
+		Step{Target: "qdrant", Version: 1, Name: "create_collection", Up: noop},
+		Step{Target: "search", Version: 1, Name: "create_index", Up: noop, Down: noop},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 1 and 2 were adopted together, then the qdrant step ran on a fresh
+	// volume, then 3 and the search step were deployed
+	adopted := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	done := map[string]appliedRow{
+		"postgres/1": {appliedAt: adopted},
+		"postgres/2": {appliedAt: adopted},
+		"qdrant/1":   {appliedAt: adopted.Add(time.Hour)},
+		"search/1":   {appliedAt: adopted.Add(2 * time.Hour)},
+		"postgres/3": {appliedAt: adopted.Add(3 * time.Hour)},
+	}
+
+	var got []string
+	for _, mig := range m.revertOrder(done) {
+		got = append(got, mig.String())
+	}
+	want := []string{"postgres/000003_add_bio", "search/000001_create_index", "postgres/000002_add_roles", "postgres/000001_create_users"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("revertOrder() = %v, want %v", got, want)
+	}
+}
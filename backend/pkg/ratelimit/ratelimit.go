@@ -0,0 +1,198 @@
+// Package ratelimit implements the generic cell rate algorithm (GCRA) over
+// Redis, so every API instance shares the same counters, and in memory for
+// when Redis is unavailable.
+//
+// GCRA tracks one timestamp per key, the theoretical arrival time (TAT) of
+// the next request if requests came at exactly the allowed rate. A request
+// is allowed unless the TAT is more than a full period ahead of now, which
+// lets a client spend the whole limit in a burst and then earn it back
+// evenly, like a sliding window without storing each request.
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Limit allows Requests per Period.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Requests)
+}
+
+// Result is the outcome of one request against a limit.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long a denied client must wait
+	RetryAfter time.Duration
+	// ResetAfter is how long until the full limit is available again
+	ResetAfter time.Duration
+}
+
+// Limiter counts a request for key against limit.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (*Result, error)
+}
+
+// gcra applies one request at now to a key whose TAT is tat, returning the
+// new TAT to store (zero when denied).
+func gcra(now, tat time.Time, limit Limit) (*Result, time.Time) {
+	interval, period := limit.interval(), limit.Period
+	if tat.Before(now) {
+		tat = now
+	}
+	newTAT := tat.Add(interval)
+	allowAt := newTAT.Add(-period)
+
+	if now.Before(allowAt) {
+		return &Result{
+			Limit:      limit.Requests,
+			RetryAfter: allowAt.Sub(now),
+			ResetAfter: tat.Sub(now),
+		}, time.Time{}
+	}
+	return &Result{
+		Allowed:    true,
+		Limit:      limit.Requests,
+		Remaining:  int(now.Sub(allowAt) / interval),
+		ResetAfter: newTAT.Sub(now),
+	}, newTAT
+}
+
+// gcraScript is gcra run atomically in Redis. Times are microseconds; the
+// caller supplies now so the result matches the in-memory limiter.
+var gcraScript = redis.NewScript(`
+	local now = tonumber(ARGV[1])
+	local period = tonumber(ARGV[2])
+	local interval = tonumber(ARGV[3])
+
+	local tat = tonumber(redis.call("GET", KEYS[1]) or now)
+	if tat < now then
+		tat = now
+	end
+	local new_tat = tat + interval
+	local allow_at = new_tat - period
+
+	if now < allow_at then
+		return {0, 0, allow_at - now, tat - now}
+	end
+	redis.call("SET", KEYS[1], string.format("%d", new_tat), "PX", math.ceil((new_tat - now) / 1000))
+	return {1, math.floor((now - allow_at) / interval), 0, new_tat - now}
+`)
+
+type redisLimiter struct {
+	client *redis.Client
+	prefix string
+	now    func() time.Time
+}
+
+func NewRedisLimiter(client *redis.Client, prefix string) Limiter {
+	return &redisLimiter{client: client, prefix: prefix, now: time.Now}
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	values, err := gcraScript.Run(ctx, l.client, []string{l.prefix + key},
+		l.now().UnixMicro(), limit.Period.Microseconds(), limit.interval().Microseconds(),
+	).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit.Requests,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Microsecond,
+		ResetAfter: time.Duration(values[3]) * time.Microsecond,
+	}, nil
+}
+
+// memoryLimiter keeps TATs in process. Counters are per instance, so a
+// client spread across instances gets more than the limit.
+type memoryLimiter struct {
+	mu        sync.Mutex
+	tats      map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryLimiter() Limiter {
+	return &memoryLimiter{tats: map[string]time.Time{}, now: time.Now}
+}
+
+// sweepInterval is how often keys whose TAT has passed are dropped, as they
+// mean the same as no entry.
+const sweepInterval = time.Minute
+
+func (l *memoryLimiter) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		for k, tat := range l.tats {
+			if !tat.After(now) {
+				delete(l.tats, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	result, tat := gcra(now, l.tats[key], limit)
+	if result.Allowed {
+		l.tats[key] = tat
+	}
+	return result, nil
+}
+
+// fallbackLimiter uses the primary limiter and switches to the fallback for
+// requests the primary fails on, so an outage of Redis does not turn into
+// an outage of the API.
+type fallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+	mu       sync.Mutex
+	failing  bool
+}
+
+func NewFallbackLimiter(primary, fallback Limiter) Limiter {
+	return &fallbackLimiter{primary: primary, fallback: fallback}
+}
+
+func (l *fallbackLimiter) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	result, err := l.primary.Allow(ctx, key, limit)
+	l.setFailing(err)
+	if err != nil {
+		return l.fallback.Allow(ctx, key, limit)
+	}
+	return result, nil
+}
+
+// setFailing logs when the primary starts and stops failing rather than on
+// every request.
+func (l *fallbackLimiter) setFailing(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch {
+	case err != nil && !l.failing:
+		slog.Warn("Rate limiter falling back to memory", "error", err)
+	case err == nil && l.failing:
+		slog.Info("Rate limiter recovered")
+	}
+	l.failing = err != nil
+}
+
+// Seconds rounds a duration up to whole seconds, as rate limit headers use.
+func Seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestLimiters(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	limiters := map[string]func(now func() time.Time) Limiter{
+		"redis": func(now func() time.Time) Limiter {
+			return &redisLimiter{client: client, prefix: "test:", now: now}
+		},
+		"memory": func(now func() time.Time) Limiter {
+			return &memoryLimiter{tats: map[string]time.Time{}, now: now}
+		},
+	}
+	limit := Limit{Requests: 3, Period: time.Minute}
+
+	// Each step happens at an offset from the start
+	steps := []struct {
+		at            time.Duration
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		{0, true, 2, 0},
+		{0, true, 1, 0},
+		{0, true, 0, 0},
+		{0, false, 0, 20 * time.Second},
+		{19 * time.Second, false, 0, time.Second},
+		{20 * time.Second, true, 0, 0},
+		{2 * time.Minute, true, 2, 0},
+	}
+
+	for name, newLimiter := range limiters {
+		t.Run(name, func(t *testing.T) {
+			now := start
+			limiter := newLimiter(func() time.Time { return now })
+			for i, step := range steps {
+				now = start.Add(step.at)
+				result, err := limiter.Allow(context.Background(), name+":user", limit)
+				if err != nil {
+					t.Fatalf("step %d: Allow() error = %v", i, err)
+				}
+				if result.Allowed != step.wantAllowed || result.Remaining != step.wantRemaining || result.RetryAfter != step.wantRetry {
+					t.Errorf("step %d: got allowed=%v remaining=%d retry=%v, want %v %d %v",
+						i, result.Allowed, result.Remaining, result.RetryAfter,
+						step.wantAllowed, step.wantRemaining, step.wantRetry)
+				}
+			}
+		})
+	}
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(ctx context.Context, key string, limit Limit) (*Result, error) {
+	return nil, redis.ErrClosed
+}
+
+func TestFallbackLimiter(t *testing.T) {
+	limiter := NewFallbackLimiter(failingLimiter{}, NewMemoryLimiter())
+	limit := Limit{Requests: 1, Period: time.Minute}
+
+	first, err := limiter.Allow(context.Background(), "k", limit)
+	if err != nil || !first.Allowed {
+		t.Fatalf("first Allow() = %+v, %v; want allowed", first, err)
+	}
+	second, err := limiter.Allow(context.Background(), "k", limit)
+	if err != nil || second.Allowed {
+		t.Fatalf("second Allow() = %+v, %v; want denied by the fallback", second, err)
+	}
+}
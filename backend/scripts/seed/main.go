@@ -5,10 +5,9 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"time"
 
 	"github.com/joho/godotenv"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -52,6 +51,7 @@ func seedUsers(db *sql.DB) error {
 		dateOfBirth string
 		gender      string
 		bio         string
+		interests   []string
 	}{
 		{
 			email:       "john@example.com",
@@ -60,6 +60,7 @@ func seedUsers(db *sql.DB) error {
 			dateOfBirth: "1995-06-15",
 			gender:      "male",
 			bio:         "Adventure seeker and coffee enthusiast. Love hiking and exploring new places!",
+			interests:   []string{"hiking", "coffee", "travel"},
 		},
 		{
 			email:       "jane@example.com",
@@ -68,6 +69,7 @@ func seedUsers(db *sql.DB) error {
 			dateOfBirth: "1993-03-20",
 			gender:      "female",
 			bio:         "Book lover and travel addict. Always up for spontaneous road trips!",
+			interests:   []string{"reading", "travel", "road trips"},
 		},
 		{
 			email:       "alex@example.com",
@@ -76,6 +78,7 @@ func seedUsers(db *sql.DB) error {
 			dateOfBirth: "1997-09-10",
 			gender:      "other",
 			bio:         "Foodie and music lover. Let's discover the best restaurants in town together!",
+			interests:   []string{"food", "music", "travel"},
 		},
 		{
 			email:       "sarah@example.com",
@@ -84,6 +87,7 @@ func seedUsers(db *sql.DB) error {
 			dateOfBirth: "1996-01-25",
 			gender:      "female",
 			bio:         "Yoga instructor and wellness enthusiast. Looking for someone who values health!",
+			interests:   []string{"yoga", "hiking", "cooking"},
 		},
 		{
 			email:       "mike@example.com",
@@ -92,6 +96,7 @@ func seedUsers(db *sql.DB) error {
 			dateOfBirth: "1994-11-05",
 			gender:      "male",
 			bio:         "Tech geek and gamer. Netflix and chill? More like code and compile! 😄",
+			interests:   []string{"gaming", "movies", "coding"},
 		},
 	}
 
@@ -116,9 +121,9 @@ func seedUsers(db *sql.DB) error {
 
 		// Insert user
 		_, err = db.Exec(`
-			INSERT INTO users (email, password_hash, full_name, date_of_birth, gender, bio, verified, active)
-			VALUES ($1, $2, $3, $4, $5, $6, true, true)
-		`, user.email, hashedPassword, user.fullName, user.dateOfBirth, user.gender, user.bio)
+			INSERT INTO users (email, password_hash, full_name, date_of_birth, gender, bio, interests, verified, active)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, true, true)
+		`, user.email, hashedPassword, user.fullName, user.dateOfBirth, user.gender, user.bio, pq.Array(user.interests))
 
 		if err != nil {
 			return fmt.Errorf("failed to create user %s: %v", user.email, err)
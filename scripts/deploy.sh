@@ -36,8 +36,7 @@ echo -e "${YELLOW}📦 Building Docker images...${NC}"
 docker-compose -f backend/docker-compose.yml build
 
 echo -e "${YELLOW}🔄 Running database migrations...${NC}"
-docker-compose -f backend/docker-compose.yml run --rm api \
-    sh -c "cd /app && go run cmd/migrate/main.go up" || true
+docker-compose -f backend/docker-compose.yml run --rm api ./migrate up
 
 echo -e "${YELLOW}🌱 Seeding database (if needed)...${NC}"
 if [ "$ENVIRONMENT" = "staging" ]; then